@@ -0,0 +1,10 @@
+package adpcm
+
+import "errors"
+
+var (
+	InvalidBlock         = errors.New("invalid ADPCM block")
+	InvalidBlockAlign    = errors.New("invalid ADPCM block align")
+	NotSupportedCodec    = errors.New("not supported codec")
+	NotSupportedChannels = errors.New("only mono and stereo are supported")
+)
@@ -0,0 +1,178 @@
+package adpcm
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const imaHeaderSize = 4
+
+var imaStepTable = [89]int32{
+	7, 8, 9, 10, 11, 12, 13, 14, 16, 17, 19, 21, 23, 25, 28, 31, 34, 37, 41, 45, 50, 55, 60, 66, 73, 80, 88, 97, 107,
+	118, 130, 143, 157, 173, 190, 209, 230, 253, 279, 307, 337, 371, 408, 449, 494, 544, 598, 658, 724, 796, 876, 963,
+	1060, 1166, 1282, 1411, 1552, 1707, 1878, 2066, 2272, 2499, 2749, 3024, 3327, 3660, 4026, 4428, 4871, 5358, 5894,
+	6484, 7132, 7845, 8630, 9493, 10442, 11487, 12635, 13899, 15289, 16818, 18500, 20350, 22385, 24623, 27086, 29794,
+	32767,
+}
+
+var imaIndexTable = [8]int32{-1, -1, -1, -1, 2, 4, 6, 8}
+
+// ImaState is the predictor of IMA (DVI) ADPCM, the encoder and the decoder keep identical states.
+type ImaState struct {
+	Predictor int32
+	Index     int32
+}
+
+// Decode returns the sample of the 4-bit code.
+func (s *ImaState) Decode(code byte) int16 {
+	step := imaStepTable[s.Index]
+	diff := step >> 3
+	if code&1 != 0 {
+		diff += step >> 2
+	}
+	if code&2 != 0 {
+		diff += step >> 1
+	}
+	if code&4 != 0 {
+		diff += step
+	}
+	if code&8 != 0 {
+		s.Predictor -= diff
+	} else {
+		s.Predictor += diff
+	}
+	s.Predictor = min(max(s.Predictor, -32768), 32767)
+	s.Index = min(max(s.Index+imaIndexTable[code&7], 0), 88)
+	return int16(s.Predictor)
+}
+
+// Encode returns the 4-bit code of the sample.
+func (s *ImaState) Encode(sample int16) byte {
+	diff := int32(sample) - s.Predictor
+	var code byte
+	if diff < 0 {
+		code = 8
+		diff = -diff
+	}
+	step := imaStepTable[s.Index]
+	if diff >= step {
+		code |= 4
+		diff -= step
+	}
+	step >>= 1
+	if diff >= step {
+		code |= 2
+		diff -= step
+	}
+	step >>= 1
+	if diff >= step {
+		code |= 1
+	}
+	// the decoder is run to keep the same predictor on both sides
+	s.Decode(code)
+	return code
+}
+
+// ImaEncoder encodes interleaved 16-bit samples into IMA ADPCM blocks of WAV files:
+// every channel starts with a 4-byte header (the first sample and the step index) followed by
+// groups of 8 codes per channel, the first code in the low nibble.
+type ImaEncoder struct {
+	channels        int
+	blockAlign      int
+	samplesPerBlock int
+	states          []ImaState
+}
+
+func NewImaEncoder(channels int, blockAlign int) (*ImaEncoder, error) {
+	if channels != 1 && channels != 2 {
+		return nil, fmt.Errorf("%w: %d", NotSupportedChannels, channels)
+	}
+	if blockAlign <= imaHeaderSize*channels || (blockAlign-imaHeaderSize*channels)%(4*channels) != 0 {
+		return nil, fmt.Errorf("%w: %d", InvalidBlockAlign, blockAlign)
+	}
+	return &ImaEncoder{
+		channels:        channels,
+		blockAlign:      blockAlign,
+		samplesPerBlock: (blockAlign-imaHeaderSize*channels)*2/channels + 1,
+		states:          make([]ImaState, channels),
+	}, nil
+}
+
+// SamplesPerBlock returns the number of samples per channel in a block.
+func (e *ImaEncoder) SamplesPerBlock() int {
+	return e.samplesPerBlock
+}
+
+// EncodeBlock appends a block of up to SamplesPerBlock samples per channel to dst,
+// a shorter block is padded with silence up to the next group of 8 samples.
+func (e *ImaEncoder) EncodeBlock(dst []byte, samples []int16) []byte {
+	n := min(len(samples)/e.channels, e.samplesPerBlock)
+	if n == 0 {
+		return dst
+	}
+	for ch := range e.states {
+		s := &e.states[ch]
+		s.Predictor = int32(samples[ch])
+		dst = binary.LittleEndian.AppendUint16(dst, uint16(samples[ch]))
+		dst = append(dst, byte(s.Index), 0)
+	}
+
+	for i := 1; i < n; i += 8 {
+		for ch := range e.states {
+			s := &e.states[ch]
+			for j := 0; j < 8; j += 2 {
+				lo := s.Encode(sampleAt(samples, i+j, n, e.channels, ch))
+				hi := s.Encode(sampleAt(samples, i+j+1, n, e.channels, ch))
+				dst = append(dst, lo|hi<<4)
+			}
+		}
+	}
+	return dst
+}
+
+// DecodeImaBlock appends the interleaved samples of the block to dst.
+func DecodeImaBlock(dst []int16, block []byte, channels int) ([]int16, error) {
+	if channels != 1 && channels != 2 {
+		return nil, fmt.Errorf("%w: %d", NotSupportedChannels, channels)
+	}
+	if len(block) < imaHeaderSize*channels {
+		return nil, InvalidBlock
+	}
+
+	states := make([]ImaState, channels)
+	for ch := range states {
+		header := block[ch*imaHeaderSize:]
+		states[ch].Predictor = int32(int16(binary.LittleEndian.Uint16(header)))
+		if states[ch].Index = int32(header[2]); states[ch].Index > 88 {
+			return nil, fmt.Errorf("%w: step index %d", InvalidBlock, header[2])
+		}
+		dst = append(dst, int16(states[ch].Predictor))
+	}
+
+	data := block[imaHeaderSize*channels:]
+	groupSize := 4 * channels
+	var group [2][8]int16
+	for len(data) >= groupSize {
+		for ch := range states {
+			for j, b := range data[ch*4 : ch*4+4] {
+				group[ch][2*j] = states[ch].Decode(b & 0x0F)
+				group[ch][2*j+1] = states[ch].Decode(b >> 4)
+			}
+		}
+		for j := 0; j < 8; j++ {
+			for ch := range states {
+				dst = append(dst, group[ch][j])
+			}
+		}
+		data = data[groupSize:]
+	}
+	return dst, nil
+}
+
+// sampleAt returns the sample of the channel or silence after the end of the block
+func sampleAt(samples []int16, i int, n int, channels int, ch int) int16 {
+	if i >= n {
+		return 0
+	}
+	return samples[i*channels+ch]
+}
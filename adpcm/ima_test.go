@@ -0,0 +1,106 @@
+package adpcm
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// imaSamples, imaCodes and imaDecoded are the reference vector produced by audioop.lin2adpcm and audioop.adpcm2lin
+// of Python starting from the zero state
+var (
+	imaSamples = []int16{
+		0, 7089, 6963, 7631, 13307, 14203, 8258, 5798, 6597, 1089, -7093, -8254, -7459, -12168, -14704, -9230, -4937,
+		-5681, -1983, 6623, 32767, -32768, 10974, 14726, 10377, 4508, 4477, 2518, -5772, -10426, -8545, -9924, -14263,
+		-11503, -4569, -3166, -2590, 4695, 10976, 9641, 9193, 13385, 12415, 5097, 1947, 2171, -3584, -11043, -10894,
+		-8904, -12235, -12955, -5992, -1006, -1313, 2637, 10627, 12107, 9105, 10998, 13024, 7095, 481, 140,
+	}
+	imaCodes = []byte{
+		0, 7, 7, 7, 7, 7, 7, 7, 7, 13, 14, 8, 0, 10, 9, 3, 2, 8, 3, 7, 7, 15, 2, 1, 9, 8, 8, 0, 10, 8, 0, 8, 9, 0, 2, 0,
+		0, 3, 4, 9, 0, 2, 8, 13, 9, 0, 11, 12, 8, 1, 10, 0, 4, 3, 8, 2, 7, 0, 9, 0, 1, 11, 11, 8,
+	}
+	imaDecoded = []int16{
+		0, 11, 41, 104, 240, 533, 1164, 2521, 5431, 858, -7056, -8134, -7154, -11611, -14042, -8886, -5538, -6146,
+		-2272, 5276, 21456, -13231, 7247, 18419, 8263, 5186, 2388, 4931, -6631, -8733, -6822, -8559, -13296, -11861,
+		-5335, -4149, -3071, 3792, 11815, 8580, 9560, 14017, 13207, 5104, 1869, 2849, -3391, -10685, -11665, -8991,
+		-13043, -12307, -6280, -607, -1343, 2005, 11136, 12441, 8882, 9960, 12901, 6661, 988, 252,
+	}
+)
+
+func TestImaState(t *testing.T) {
+	var encoder ImaState
+	codes := make([]byte, len(imaSamples))
+	for i, s := range imaSamples {
+		codes[i] = encoder.Encode(s)
+	}
+	if !slices.Equal(codes, imaCodes) {
+		t.Errorf("codes = %v, want %v", codes, imaCodes)
+	}
+	if encoder.Predictor != 252 || encoder.Index != 69 {
+		t.Errorf("state = %+v, want {252 69}", encoder)
+	}
+
+	var decoder ImaState
+	decoded := make([]int16, len(imaCodes))
+	for i, c := range imaCodes {
+		decoded[i] = decoder.Decode(c)
+	}
+	if !slices.Equal(decoded, imaDecoded) {
+		t.Errorf("decoded = %v, want %v", decoded, imaDecoded)
+	}
+}
+
+func TestImaBlock(t *testing.T) {
+	for _, channels := range []int{1, 2} {
+		e, err := NewImaEncoder(channels, 256*channels)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.SamplesPerBlock() != 505 {
+			t.Fatalf("%d channels: %d samples per block", channels, e.SamplesPerBlock())
+		}
+		samples := interleave(channels, 505)
+		block := e.EncodeBlock(nil, samples)
+		if len(block) != 256*channels {
+			t.Fatalf("%d channels: block of %d bytes", channels, len(block))
+		}
+
+		decoded, err := DecodeImaBlock(nil, block, channels)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the first sample of every channel is stored as is
+		if len(decoded) != len(samples) || !slices.Equal(decoded[:channels], samples[:channels]) {
+			t.Fatalf("%d channels: decoded %d samples starting with %v", channels, len(decoded), decoded[:channels])
+		}
+		if s := snr(samples, decoded); s < 20 {
+			t.Errorf("%d channels: SNR = %.1f dB", channels, s)
+		}
+	}
+
+	if _, err := NewImaEncoder(1, 35); err == nil {
+		t.Errorf("block align 35 is accepted")
+	}
+}
+
+// interleave returns n sample frames of channels with tones of different frequencies
+func interleave(channels int, n int) []int16 {
+	samples := make([]int16, 0, channels*n)
+	for i := 0; i < n; i++ {
+		for ch := 0; ch < channels; ch++ {
+			samples = append(samples, int16(8_000*math.Sin(float64(i)*0.1*float64(ch+1))))
+		}
+	}
+	return samples
+}
+
+// snr returns the signal to noise ratio in dB of the decoded samples
+func snr(samples []int16, decoded []int16) float64 {
+	var signal, noise float64
+	for i, s := range samples {
+		d := float64(decoded[i]) - float64(s)
+		signal += float64(s) * float64(s)
+		noise += d * d
+	}
+	return 10 * math.Log10(signal/noise)
+}
@@ -0,0 +1,235 @@
+package adpcm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+const (
+	msHeaderSize = 7
+	msMinDelta   = 16
+)
+
+var msAdaptationTable = [16]int32{230, 230, 230, 230, 307, 409, 512, 614, 768, 614, 512, 409, 307, 230, 230, 230}
+
+// MsState is the predictor of Microsoft ADPCM, the encoder and the decoder keep identical states.
+type MsState struct {
+	Coefficients [2]int32
+	Delta        int32
+	// Sample1 is the last sample, Sample2 is the one before it
+	Sample1 int32
+	Sample2 int32
+}
+
+// predict divides by 256 rounding toward zero as ffmpeg does, a shift would round negative values down
+func (s *MsState) predict() int32 {
+	return (s.Sample1*s.Coefficients[0] + s.Sample2*s.Coefficients[1]) / 256
+}
+
+func (s *MsState) update(code byte, sample int32) {
+	s.Sample2 = s.Sample1
+	s.Sample1 = sample
+	s.Delta = max(msAdaptationTable[code]*s.Delta>>8, msMinDelta)
+}
+
+// Decode returns the sample of the 4-bit code.
+func (s *MsState) Decode(code byte) int16 {
+	// the code is a signed 4-bit number
+	sample := s.predict() + int32(int8(code<<4)>>4)*s.Delta
+	sample = min(max(sample, -32768), 32767)
+	s.update(code, sample)
+	return int16(sample)
+}
+
+// Encode returns the 4-bit code of the sample.
+func (s *MsState) Encode(sample int16) byte {
+	predictor := s.predict()
+	diff := int32(sample) - predictor
+	// rounding to the nearest code
+	if diff >= 0 {
+		diff += s.Delta / 2
+	} else {
+		diff -= s.Delta / 2
+	}
+	code := min(max(diff/s.Delta, -8), 7)
+	c := byte(code) & 0x0F
+	s.update(c, min(max(predictor+code*s.Delta, -32768), 32767))
+	return c
+}
+
+// MsEncoder encodes interleaved 16-bit samples into Microsoft ADPCM blocks of WAV files:
+// the block header holds the predictor index, the delta and two first samples of every channel
+// grouped by field, the codes follow with the first code in the high nibble.
+type MsEncoder struct {
+	channels        int
+	blockAlign      int
+	samplesPerBlock int
+	states          []MsState
+}
+
+func NewMsEncoder(channels int, blockAlign int) (*MsEncoder, error) {
+	if channels != 1 && channels != 2 {
+		return nil, fmt.Errorf("%w: %d", NotSupportedChannels, channels)
+	}
+	if blockAlign <= msHeaderSize*channels {
+		return nil, fmt.Errorf("%w: %d", InvalidBlockAlign, blockAlign)
+	}
+	return &MsEncoder{
+		channels:        channels,
+		blockAlign:      blockAlign,
+		samplesPerBlock: (blockAlign-msHeaderSize*channels)*2/channels + 2,
+		states:          make([]MsState, channels),
+	}, nil
+}
+
+// SamplesPerBlock returns the number of samples per channel in a block.
+func (e *MsEncoder) SamplesPerBlock() int {
+	return e.samplesPerBlock
+}
+
+// EncodeBlock appends a block of up to SamplesPerBlock samples per channel to dst choosing the predictor
+// with the least error for every channel, a shorter block is padded with silence up to a whole byte.
+func (e *MsEncoder) EncodeBlock(dst []byte, samples []int16) []byte {
+	n := min(len(samples)/e.channels, e.samplesPerBlock)
+	if n == 0 {
+		return dst
+	}
+
+	predictors := make([]int, e.channels)
+	for ch := range e.states {
+		predictors[ch] = e.choosePredictor(samples, n, ch)
+		e.states[ch] = e.initialState(samples, n, ch, predictors[ch])
+	}
+
+	for ch := range e.states {
+		dst = append(dst, byte(predictors[ch]))
+	}
+	for ch := range e.states {
+		dst = binary.LittleEndian.AppendUint16(dst, uint16(e.states[ch].Delta))
+	}
+	for ch := range e.states {
+		dst = binary.LittleEndian.AppendUint16(dst, uint16(e.states[ch].Sample1))
+	}
+	for ch := range e.states {
+		dst = binary.LittleEndian.AppendUint16(dst, uint16(e.states[ch].Sample2))
+	}
+
+	// mono codes are padded to a whole byte
+	last := n
+	if n*e.channels%2 != 0 {
+		last++
+	}
+	var b byte
+	k := 0
+	for i := 2; i < last; i++ {
+		for ch := range e.states {
+			code := e.states[ch].Encode(sampleAt(samples, i, n, e.channels, ch))
+			if k%2 == 0 {
+				b = code << 4
+			} else {
+				dst = append(dst, b|code)
+			}
+			k++
+		}
+	}
+	return dst
+}
+
+// choosePredictor returns the index of the coefficients giving the least squared error on the block
+func (e *MsEncoder) choosePredictor(samples []int16, n int, ch int) int {
+	best := 0
+	var bestError int64 = -1
+	for p := range audiocodec.MsAdpcmCoefficients {
+		s := e.initialState(samples, n, ch, p)
+		var err int64
+		for i := 2; i < n; i++ {
+			sample := sampleAt(samples, i, n, e.channels, ch)
+			s.Encode(sample)
+			d := int64(sample) - int64(s.Sample1)
+			err += d * d
+		}
+		if bestError < 0 || err < bestError {
+			best, bestError = p, err
+		}
+	}
+	return best
+}
+
+// initialState sets up the two first samples and the delta by the mean prediction error of the block start
+func (e *MsEncoder) initialState(samples []int16, n int, ch int, predictor int) MsState {
+	c := audiocodec.MsAdpcmCoefficients[predictor]
+	s := MsState{
+		Coefficients: [2]int32{int32(c[0]), int32(c[1])},
+		Sample2:      int32(sampleAt(samples, 0, n, e.channels, ch)),
+		Sample1:      int32(sampleAt(samples, 1, n, e.channels, ch)),
+	}
+
+	s1, s2 := s.Sample1, s.Sample2
+	var sum, count int32
+	for i := 2; i < min(n, 6); i++ {
+		sample := int32(sampleAt(samples, i, n, e.channels, ch))
+		prediction := (s1*s.Coefficients[0] + s2*s.Coefficients[1]) / 256
+		sum += abs(sample - prediction)
+		count++
+		s2, s1 = s1, sample
+	}
+	s.Delta = msMinDelta
+	if count > 0 {
+		s.Delta = max(sum/count/4, msMinDelta)
+	}
+	return s
+}
+
+// DecodeMsBlock appends the interleaved samples of the block to dst.
+func DecodeMsBlock(dst []int16, block []byte, channels int) ([]int16, error) {
+	if channels != 1 && channels != 2 {
+		return nil, fmt.Errorf("%w: %d", NotSupportedChannels, channels)
+	}
+	if len(block) < msHeaderSize*channels {
+		return nil, InvalidBlock
+	}
+
+	states := make([]MsState, channels)
+	for ch := range states {
+		predictor := int(block[ch])
+		if predictor >= len(audiocodec.MsAdpcmCoefficients) {
+			return nil, fmt.Errorf("%w: predictor %d", InvalidBlock, predictor)
+		}
+		c := audiocodec.MsAdpcmCoefficients[predictor]
+		field := func(i int) int32 {
+			offset := channels + 2*(i*channels+ch)
+			return int32(int16(binary.LittleEndian.Uint16(block[offset:])))
+		}
+		states[ch] = MsState{
+			Coefficients: [2]int32{int32(c[0]), int32(c[1])},
+			Delta:        field(0),
+			Sample1:      field(1),
+			Sample2:      field(2),
+		}
+	}
+
+	for ch := range states {
+		dst = append(dst, int16(states[ch].Sample2))
+	}
+	for ch := range states {
+		dst = append(dst, int16(states[ch].Sample1))
+	}
+
+	ch := 0
+	for _, b := range block[msHeaderSize*channels:] {
+		dst = append(dst, states[ch].Decode(b>>4))
+		ch = (ch + 1) % channels
+		dst = append(dst, states[ch].Decode(b&0x0F))
+		ch = (ch + 1) % channels
+	}
+	return dst, nil
+}
+
+func abs(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,57 @@
+package adpcm
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDecodeMsBlock(t *testing.T) {
+	// predictor 5, delta 40, samples -1000 and -900, the samples are decoded with the formulas of ffmpeg
+	block := []byte{
+		0x05, 0x28, 0x00, 0x18, 0xfc, 0x7c, 0xfc, 0xa4, 0xc1, 0x23, 0xb1, 0x61, 0x2d, 0xd2, 0x72, 0xd1, 0x37, 0x1c, 0x17,
+	}
+	want := []int16{
+		-900, -1000, -1305, -1212, -1497, -1592, -1442, -1027, -1073, -966, -179, 691, 1795, 2114, 1848, 1897, 2831,
+		4177, 4356, 4687, 5566, 7621, 9660, 9409, 9584, 12880,
+	}
+	samples, err := DecodeMsBlock(nil, block, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(samples, want) {
+		t.Errorf("samples = %v, want %v", samples, want)
+	}
+
+	if _, err = DecodeMsBlock(nil, []byte{7, 0, 0, 0, 0, 0, 0}, 1); err == nil {
+		t.Errorf("predictor 7 is decoded")
+	}
+}
+
+func TestMsBlock(t *testing.T) {
+	for _, channels := range []int{1, 2} {
+		e, err := NewMsEncoder(channels, 256*channels)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.SamplesPerBlock() != 500 {
+			t.Fatalf("%d channels: %d samples per block", channels, e.SamplesPerBlock())
+		}
+		samples := interleave(channels, 500)
+		block := e.EncodeBlock(nil, samples)
+		if len(block) != 256*channels {
+			t.Fatalf("%d channels: block of %d bytes", channels, len(block))
+		}
+
+		decoded, err := DecodeMsBlock(nil, block, channels)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the two first samples of every channel are stored as is
+		if len(decoded) != len(samples) || !slices.Equal(decoded[:2*channels], samples[:2*channels]) {
+			t.Fatalf("%d channels: decoded %d samples starting with %v", channels, len(decoded), decoded[:2*channels])
+		}
+		if s := snr(samples, decoded); s < 40 {
+			t.Errorf("%d channels: SNR = %.1f dB", channels, s)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package adpcm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+// DecodeWav decodes a WAV of IMA or Microsoft ADPCM into 16-bit PCM.
+func DecodeWav(wav *audiocodec.Wav) (*audiocodec.Wav, error) {
+	decode, headerSize := DecodeImaBlock, imaHeaderSize
+	switch wav.Codec().Name {
+	case audiocodec.ImaAdpcm:
+	case audiocodec.MsAdpcm:
+		decode, headerSize = DecodeMsBlock, msHeaderSize
+	default:
+		return nil, fmt.Errorf("%w: %s", NotSupportedCodec, wav.Codec().Name)
+	}
+
+	blockAlign := wav.BlockAlign()
+	channels := wav.Channels()
+	samplesPerBlock := wav.SamplesPerBlock()
+	data := wav.Data()
+
+	blockCount := (len(data) + blockAlign - 1) / blockAlign
+	samples := make([]int16, 0, blockCount*samplesPerBlock*channels)
+	for len(data) > 0 {
+		block := data[:min(blockAlign, len(data))]
+		data = data[len(block):]
+		if len(block) < headerSize*channels {
+			// the tail of a truncated file shorter than the block header holds no samples
+			break
+		}
+
+		start := len(samples)
+		var err error
+		if samples, err = decode(samples, block, channels); err != nil {
+			return nil, err
+		}
+		// samples beyond the declared block length are padding
+		samples = samples[:min(len(samples), start+samplesPerBlock*channels)]
+	}
+	// the last block is padded beyond the sample count of the fact chunk
+	samples = samples[:min(len(samples), wav.SampleCount()*channels)]
+
+	pcm := make([]byte, 0, 2*len(samples))
+	for _, s := range samples {
+		pcm = binary.LittleEndian.AppendUint16(pcm, uint16(s))
+	}
+
+	result := audiocodec.NewWavWithChannels(audiocodec.NewPcmCodec(wav.Codec().SampleRate, 16), channels)
+	if _, err := result.Write(pcm); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// EncodeWav encodes a WAV of 16-bit PCM into IMA or Microsoft ADPCM with blocks of blockAlign bytes,
+// 256 bytes per channel is the usual choice for 8 kHz audio.
+func EncodeWav(wav *audiocodec.Wav, name audiocodec.Name, blockAlign int) (*audiocodec.Wav, error) {
+	codec := wav.Codec()
+	if !codec.IsPcm() || codec.BitRate != 16 {
+		return nil, fmt.Errorf("%w: %s", NotSupportedCodec, codec.Preset())
+	}
+
+	channels := wav.Channels()
+	var encodeBlock func(dst []byte, samples []int16) []byte
+	var samplesPerBlock int
+	switch name {
+	case audiocodec.ImaAdpcm:
+		e, err := NewImaEncoder(channels, blockAlign)
+		if err != nil {
+			return nil, err
+		}
+		encodeBlock, samplesPerBlock = e.EncodeBlock, e.SamplesPerBlock()
+	case audiocodec.MsAdpcm:
+		e, err := NewMsEncoder(channels, blockAlign)
+		if err != nil {
+			return nil, err
+		}
+		encodeBlock, samplesPerBlock = e.EncodeBlock, e.SamplesPerBlock()
+	default:
+		return nil, fmt.Errorf("%w: %s", NotSupportedCodec, name)
+	}
+
+	pcm := wav.Data()
+	samples := make([]int16, len(pcm)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(pcm[2*i:]))
+	}
+
+	var data []byte
+	for step := samplesPerBlock * channels; len(samples) > 0; {
+		block := samples[:min(step, len(samples))]
+		samples = samples[len(block):]
+		data = encodeBlock(data, block)
+	}
+
+	result := audiocodec.NewWavWithBlockAlign(audiocodec.NewCodec(name, codec.SampleRate, 16), channels, blockAlign)
+	result.SetSampleCount(len(pcm) / 2 / channels)
+	if _, err := result.Write(data); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package adpcm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+func TestWavRoundTrip(t *testing.T) {
+	for _, name := range []audiocodec.Name{audiocodec.ImaAdpcm, audiocodec.MsAdpcm} {
+		for _, channels := range []int{1, 2} {
+			// the last block is shorter
+			samples := interleave(channels, 2000)
+			pcm := make([]byte, 0, 2*len(samples))
+			for _, s := range samples {
+				pcm = binary.LittleEndian.AppendUint16(pcm, uint16(s))
+			}
+			wav := audiocodec.NewWavWithChannels(audiocodec.Pcm8kHz16bCodec, channels)
+			wav.Write(pcm)
+
+			encoded, err := EncodeWav(wav, name, 256*channels)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var file bytes.Buffer
+			if _, err = encoded.WriteTo(&file); err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := audiocodec.NewWavFromBytes(file.Bytes())
+			if err != nil {
+				t.Fatalf("%s, %d channels: %v", name, channels, err)
+			}
+			// IMA ADPCM pads the last block with silence up to a group of 8 codes, the fact chunk keeps the real count
+			sampleCount := 2000
+			if parsed.Codec().Name != name || parsed.Channels() != channels || parsed.SampleCount() != sampleCount {
+				t.Errorf("%s, %d channels: parsed %s of %d channels and %d samples", name, channels, parsed.Codec().Name, parsed.Channels(), parsed.SampleCount())
+			}
+
+			decoded, err := DecodeWav(parsed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !decoded.Codec().IsEqual(audiocodec.Pcm8kHz16bCodec) || decoded.Channels() != channels || decoded.SampleCount() != sampleCount {
+				t.Errorf("%s, %d channels: decoded %s of %d channels and %d samples", name, channels, decoded.Codec().Preset(), decoded.Channels(), decoded.SampleCount())
+			}
+		}
+	}
+
+	wav := audiocodec.NewWav(audiocodec.PcmA8kHz8bCodec)
+	if _, err := EncodeWav(wav, audiocodec.ImaAdpcm, 256); !errors.Is(err, NotSupportedCodec) {
+		t.Errorf("encoding of A-law: %v, want %v", err, NotSupportedCodec)
+	}
+}
+
+func TestDecodeTruncatedWav(t *testing.T) {
+	for _, name := range []audiocodec.Name{audiocodec.ImaAdpcm, audiocodec.MsAdpcm} {
+		headerSize := imaHeaderSize
+		if name == audiocodec.MsAdpcm {
+			headerSize = msHeaderSize
+		}
+		for _, channels := range []int{1, 2} {
+			codec := audiocodec.NewCodec(name, 8_000, 16)
+			blockAlign := 256 * channels
+			samplesPerBlock := audiocodec.SamplesPerBlock(codec, channels, blockAlign)
+			// a whole block of silence followed by a tail shorter than the block header
+			block := make([]byte, blockAlign)
+			for tail := 0; tail < headerSize*channels; tail++ {
+				wav := audiocodec.NewWavWithBlockAlign(codec, channels, blockAlign)
+				wav.Write(append(block, make([]byte, tail)...))
+				if wav.SampleCount() != samplesPerBlock {
+					t.Errorf("%s, %d channels, tail of %d bytes: %d samples, want %d", name, channels, tail, wav.SampleCount(), samplesPerBlock)
+				}
+
+				decoded, err := DecodeWav(wav)
+				if err != nil {
+					t.Fatalf("%s, %d channels, tail of %d bytes: %v", name, channels, tail, err)
+				}
+				if decoded.SampleCount() != samplesPerBlock {
+					t.Errorf("%s, %d channels, tail of %d bytes: decoded %d samples, want %d", name, channels, tail, decoded.SampleCount(), samplesPerBlock)
+				}
+			}
+		}
+	}
+}
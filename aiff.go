@@ -0,0 +1,463 @@
+package audiocodec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+)
+
+// aifcVersion is the only version of AIFF-C in the FVER chunk
+const aifcVersion = 0xA2805140
+
+// Aiff is an AIFF or AIFF-C file. Data holds the samples in little-endian order as any other data described by Codec,
+// the big-endian samples of the file are converted on reading and writing.
+type Aiff struct {
+	headers  []byte
+	data     []byte
+	sound    []byte // data of the SSND chunk as stored in the file
+	codec    *Codec
+	channels int
+	read     int
+	editable bool
+}
+
+func NewAiff(codec *Codec) *Aiff {
+	return NewAiffWithChannels(codec, 1)
+}
+
+// NewAiffWithChannels creates an AIFF for interleaved multichannel data, every channel is described by the codec.
+// Linear PCM is written as AIFF, G.711 and floating point PCM as AIFF-C.
+func NewAiffWithChannels(codec *Codec, channels int) *Aiff {
+	return &Aiff{
+		codec:    codec,
+		channels: channels,
+		editable: true,
+	}
+}
+
+func NewAiffFromBytes(b []byte) (*Aiff, error) {
+	if len(b) < 12 || string(b[0:4]) != "FORM" || (string(b[8:12]) != "AIFF" && string(b[8:12]) != "AIFC") {
+		return nil, InvalidAiff
+	}
+	aifc := string(b[8:12]) == "AIFC"
+
+	var a Aiff
+	var frameCount int
+	var sound []byte
+	var littleEndian, unsigned bool
+	var err error
+
+	i := 12
+	n := len(b)
+	for i+8 <= n {
+		chunkId := string(b[i : i+4])
+		chunkSize := int(binary.BigEndian.Uint32(b[i+4 : i+8]))
+		payloadStart := i + 8
+		payloadEnd := payloadStart + chunkSize
+		if payloadEnd > n {
+			return nil, TruncatedAiff
+		}
+		payload := b[payloadStart:payloadEnd]
+
+		switch chunkId {
+		case "COMM":
+			if frameCount, littleEndian, unsigned, err = a.parseComm(payload, aifc); err != nil {
+				return nil, err
+			}
+		case "SSND":
+			if chunkSize < 8 {
+				return nil, fmt.Errorf("invalid SSND chunk: size=%d", chunkSize)
+			}
+			offset := int(binary.BigEndian.Uint32(payload[0:4]))
+			if 8+offset > chunkSize {
+				return nil, fmt.Errorf("invalid SSND chunk: offset=%d", offset)
+			}
+			sound = payload[8+offset:]
+		}
+
+		// chunks are padded to even sizes
+		i = payloadEnd + chunkSize&1
+	}
+
+	if a.codec == nil {
+		return nil, fmt.Errorf("COMM chunk not found: %w", UnsupportedAiffFormat)
+	}
+	if sound == nil {
+		return nil, fmt.Errorf("SSND chunk not found: %w", UnsupportedAiffFormat)
+	}
+
+	// the number of sample frames of COMM is authoritative, the SSND chunk may contain padding
+	frameSize := a.codec.SampleSize() * a.channels
+	sound = sound[:min(len(sound), frameCount*frameSize)/frameSize*frameSize]
+	a.data = make([]byte, len(sound))
+	copy(a.data, sound)
+	if !littleEndian {
+		swapByteOrder(a.data, a.codec)
+	}
+	if a.codec.Name == Pcm && a.codec.BitRate == 8 && !unsigned {
+		flipSign(a.data)
+	}
+	// the parsed file is written back as AIFF or AIFF-C in big-endian order
+	a.prepare()
+
+	return &a, nil
+}
+
+// ReadAiffHeader reads the COMM chunk of an AIFF or AIFF-C file and seeks past the sample data. It returns an editable
+// AIFF with the format of the file but without data, and the number of samples per channel of the file.
+func ReadAiffHeader(r io.ReadSeeker) (*Aiff, int, error) {
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, 0, err
+	}
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err = r.Seek(start, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+
+	b := make([]byte, 12)
+	if _, err = io.ReadFull(r, b); err != nil || string(b[0:4]) != "FORM" || (string(b[8:12]) != "AIFF" && string(b[8:12]) != "AIFC") {
+		return nil, 0, InvalidAiff
+	}
+	aifc := string(b[8:12]) == "AIFC"
+
+	a := &Aiff{editable: true}
+	var frameCount int
+	soundSize := int64(-1)
+
+	offset := start + 12
+	for {
+		header := make([]byte, 8)
+		if _, err = io.ReadFull(r, header); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			return nil, 0, err
+		}
+		chunkSize := int64(binary.BigEndian.Uint32(header[4:8]))
+		payloadEnd := offset + 8 + chunkSize
+		if payloadEnd > end {
+			return nil, 0, TruncatedAiff
+		}
+
+		switch string(header[0:4]) {
+		case "COMM":
+			payload := make([]byte, chunkSize)
+			if _, err = io.ReadFull(r, payload); err != nil {
+				return nil, 0, err
+			}
+			if frameCount, _, _, err = a.parseComm(payload, aifc); err != nil {
+				return nil, 0, err
+			}
+		case "SSND":
+			if chunkSize < 8 {
+				return nil, 0, fmt.Errorf("invalid SSND chunk: size=%d", chunkSize)
+			}
+			payload := make([]byte, 8)
+			if _, err = io.ReadFull(r, payload); err != nil {
+				return nil, 0, err
+			}
+			soundOffset := int64(binary.BigEndian.Uint32(payload[0:4]))
+			if 8+soundOffset > chunkSize {
+				return nil, 0, fmt.Errorf("invalid SSND chunk: offset=%d", soundOffset)
+			}
+			soundSize = chunkSize - 8 - soundOffset
+		}
+
+		// chunks are padded to even sizes
+		offset = payloadEnd + chunkSize&1
+		if _, err = r.Seek(offset, io.SeekStart); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if a.codec == nil {
+		return nil, 0, fmt.Errorf("COMM chunk not found: %w", UnsupportedAiffFormat)
+	}
+	if soundSize < 0 {
+		return nil, 0, fmt.Errorf("SSND chunk not found: %w", UnsupportedAiffFormat)
+	}
+
+	// the number of sample frames of COMM is authoritative, the SSND chunk may contain padding
+	return a, int(min(int64(frameCount), soundSize/int64(a.codec.SampleSize()*a.channels))), nil
+}
+
+// parseComm parses the COMM chunk, it returns the number of sample frames and the byte order and signedness
+// of the PCM samples.
+func (a *Aiff) parseComm(p []byte, aifc bool) (frameCount int, littleEndian bool, unsigned bool, err error) {
+	if len(p) < 18 || (aifc && len(p) < 22) {
+		return 0, false, false, fmt.Errorf("invalid COMM chunk: size=%d", len(p))
+	}
+	a.channels = int(binary.BigEndian.Uint16(p[0:2]))
+	frameCount = int(binary.BigEndian.Uint32(p[2:6]))
+	sampleSize := int(binary.BigEndian.Uint16(p[6:8]))
+	sampleRate := int(math.Round(decodeExtended(p[8:18])))
+
+	compressionType := "NONE"
+	if aifc {
+		compressionType = string(p[18:22])
+	}
+	a.codec = &Codec{SampleRate: sampleRate}
+	switch compressionType {
+	case "NONE", "twos", "sowt", "raw ":
+		// samples are left-justified in whole bytes
+		a.codec.Name = Pcm
+		a.codec.BitRate = (sampleSize + 7) / 8 * 8
+		littleEndian = compressionType == "sowt"
+		unsigned = compressionType == "raw "
+	case "ulaw", "ULAW":
+		a.codec.Name = PcmU
+		a.codec.BitRate = 8
+	case "alaw", "ALAW":
+		a.codec.Name = PcmA
+		a.codec.BitRate = 8
+	case "fl32", "FL32":
+		a.codec.Name = PcmFloat
+		a.codec.BitRate = 32
+	case "fl64", "FL64":
+		a.codec.Name = PcmFloat
+		a.codec.BitRate = 64
+	default:
+		return 0, false, false, fmt.Errorf("%w: compression type=%q", UnsupportedAiffFormat, compressionType)
+	}
+	if a.channels == 0 || a.codec.SampleRate <= 0 || a.codec.BitRate == 0 || a.codec.BitRate > 64 {
+		return 0, false, false, fmt.Errorf("invalid COMM chunk: channels=%d, sample size=%d, sample rate=%d", a.channels, sampleSize, sampleRate)
+	}
+	return frameCount, littleEndian, unsigned, nil
+}
+
+func (a *Aiff) DataSize() int {
+	return len(a.data)
+}
+
+func (a *Aiff) Write(data []byte) (int, error) {
+	if !a.editable {
+		return 0, AiffFileIsNotEditable
+	}
+
+	a.data = append(a.data, data...)
+	return len(data), nil
+}
+
+func (a *Aiff) Read(p []byte) (n int, err error) {
+	if a.editable {
+		a.editable = false
+		a.prepare()
+	}
+
+	total := len(a.headers) + a.soundChunkPadding()
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if a.read >= total+len(a.sound) {
+		return 0, io.EOF
+	}
+
+	for n < len(p) && a.read < total+len(a.sound) {
+		var m int
+		switch {
+		case a.read < len(a.headers):
+			m = copy(p[n:], a.headers[a.read:])
+		case a.read < len(a.headers)+len(a.sound):
+			m = copy(p[n:], a.sound[a.read-len(a.headers):])
+		default:
+			// pad byte of an odd-sized SSND chunk
+			p[n] = 0
+			m = 1
+		}
+		n += m
+		a.read += m
+	}
+	return n, nil
+}
+
+func (a *Aiff) Codec() *Codec {
+	return a.codec
+}
+
+func (a *Aiff) Channels() int {
+	return a.channels
+}
+
+// SampleCount returns the number of samples per channel.
+func (a *Aiff) SampleCount() int {
+	return len(a.data) / (a.codec.SampleSize() * a.channels)
+}
+
+func (a *Aiff) WriteTo(writer io.Writer) (size int64, err error) {
+	if a.editable {
+		a.editable = false
+		a.prepare()
+	}
+
+	var n int
+	for _, b := range [][]byte{a.headers, a.sound, make([]byte, a.soundChunkPadding())} {
+		if n, err = writer.Write(b); err != nil {
+			return 0, err
+		}
+		size += int64(n)
+	}
+
+	return size, nil
+}
+
+func (a *Aiff) Data() []byte {
+	return a.data
+}
+
+func (a *Aiff) isAifc() bool {
+	return a.codec.Name != Pcm
+}
+
+// prepare builds the headers and converts the data into the big-endian samples of the file
+func (a *Aiff) prepare() {
+	a.sound = make([]byte, len(a.data))
+	copy(a.sound, a.data)
+	if a.codec.Name == Pcm && a.codec.BitRate == 8 {
+		flipSign(a.sound)
+	}
+	swapByteOrder(a.sound, a.codec)
+
+	formType := "AIFF"
+	if a.isAifc() {
+		formType = "AIFC"
+	}
+	h := make([]byte, 0, 12+a.fverChunkSize()+a.commChunkSize()+16)
+	h = append(h, "FORM"...)
+	h = binary.BigEndian.AppendUint32(h, uint32(4+a.fverChunkSize()+a.commChunkSize()+a.ssndChunkSize()))
+	h = append(h, formType...)
+
+	if a.isAifc() {
+		h = append(h, "FVER"...)
+		h = binary.BigEndian.AppendUint32(h, 4)
+		h = binary.BigEndian.AppendUint32(h, aifcVersion)
+	}
+
+	h = append(h, "COMM"...)
+	h = binary.BigEndian.AppendUint32(h, uint32(a.commChunkSize()-8))
+	h = binary.BigEndian.AppendUint16(h, uint16(a.channels))
+	h = binary.BigEndian.AppendUint32(h, uint32(a.SampleCount()))
+	h = binary.BigEndian.AppendUint16(h, uint16(a.codec.BitRate))
+	h = appendExtended(h, uint32(a.codec.SampleRate))
+	if a.isAifc() {
+		compressionType, compressionName := a.compression()
+		h = append(h, compressionType...)
+		h = append(h, byte(len(compressionName)))
+		h = append(h, compressionName...)
+		if len(compressionName)%2 == 0 {
+			h = append(h, 0)
+		}
+	}
+
+	h = append(h, "SSND"...)
+	h = binary.BigEndian.AppendUint32(h, uint32(8+len(a.sound)))
+	h = binary.BigEndian.AppendUint32(h, 0) // Offset
+	h = binary.BigEndian.AppendUint32(h, 0) // Block Size
+
+	a.headers = h
+}
+
+// Смещение	Размер 	Описание 			Значение
+// 0x00 	4		Chunk ID			"FVER"
+// 0x04 	4		Chunk Data Size 	4
+// 0x08 	4		Timestamp			0xA2805140 - версия AIFF-C
+func (a *Aiff) fverChunkSize() int {
+	if a.isAifc() {
+		return 12
+	}
+	return 0
+}
+
+// Смещение	Размер 	Описание 			Значение
+// 0x00 	4		Chunk ID			"COMM"
+// 0x04 	4		Chunk Data Size 	18 для AIFF, 22 + имя сжатия для AIFF-C
+// 0x08 	2		Number of channels	1 - 65535
+// 0x0a 	4		Sample frames		число выборок одного канала
+// 0x0e 	2		Sample size			1 - 32 бит
+// 0x10 	10		Sample rate			80-битное число с плавающей точкой (IEEE 754 extended)
+// 0x1a 	4		Compression type	только AIFF-C: "NONE", "sowt", "ulaw", "alaw", "fl32", ...
+// 0x1e 	*		Compression name	только AIFF-C: строка Pascal, дополненная до четной длины
+func (a *Aiff) commChunkSize() int {
+	if !a.isAifc() {
+		return 26
+	}
+	_, compressionName := a.compression()
+	return 30 + (1+len(compressionName)+1)/2*2
+}
+
+// Смещение	Размер 	Описание 			Значение
+// 0x00 	4		Chunk ID			"SSND"
+// 0x04 	4		Chunk Data Size 	8 + размер данных
+// 0x08 	4		Offset				смещение данных после поля Block size
+// 0x0c 	4		Block size			размер блока выравнивания, обычно 0
+// 0x10 	*		Sound data			выборки в порядке big-endian
+func (a *Aiff) ssndChunkSize() int {
+	return 16 + len(a.sound) + a.soundChunkPadding()
+}
+
+func (a *Aiff) soundChunkPadding() int {
+	return len(a.sound) & 1
+}
+
+func (a *Aiff) compression() (string, string) {
+	switch {
+	case a.codec.Name == PcmU:
+		return "ulaw", "uLaw 2:1"
+	case a.codec.Name == PcmA:
+		return "alaw", "ALaw 2:1"
+	case a.codec.Name == PcmFloat && a.codec.BitRate == 64:
+		return "fl64", "64-bit floating point"
+	case a.codec.Name == PcmFloat:
+		return "fl32", "32-bit floating point"
+	}
+
+	panic(fmt.Errorf("compression type not found for \"%s\" codec", a.codec.Name))
+}
+
+// decodeExtended decodes the 80-bit IEEE 754 extended precision number: sign, 15-bit exponent and
+// 64-bit mantissa with the explicit integer bit
+func decodeExtended(b []byte) float64 {
+	exponent := int(binary.BigEndian.Uint16(b[0:2]) & 0x7FFF)
+	mantissa := binary.BigEndian.Uint64(b[2:10])
+	if exponent == 0 && mantissa == 0 {
+		return 0
+	}
+
+	v := math.Ldexp(float64(mantissa), exponent-16383-63)
+	if b[0]&0x80 != 0 {
+		v = -v
+	}
+	return v
+}
+
+// appendExtended appends the integer as the 80-bit IEEE 754 extended precision number
+func appendExtended(dst []byte, v uint32) []byte {
+	if v == 0 {
+		return append(dst, make([]byte, 10)...)
+	}
+	shift := bits.LeadingZeros64(uint64(v))
+	dst = binary.BigEndian.AppendUint16(dst, uint16(16383+63-shift))
+	return binary.BigEndian.AppendUint64(dst, uint64(v)<<shift)
+}
+
+// swapByteOrder converts samples between little-endian and big-endian in place
+func swapByteOrder(data []byte, codec *Codec) {
+	size := codec.SampleSize()
+	for i := 0; i+size <= len(data); i += size {
+		for l, r := i, i+size-1; l < r; l, r = l+1, r-1 {
+			data[l], data[r] = data[r], data[l]
+		}
+	}
+}
+
+// flipSign converts 8-bit samples between signed and unsigned in place
+func flipSign(data []byte) {
+	for i := range data {
+		data[i] ^= 0x80
+	}
+}
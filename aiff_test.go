@@ -0,0 +1,181 @@
+package audiocodec
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// testData returns data of n sample frames of all channels with distinct bytes
+func testData(codec *Codec, channels int, n int) []byte {
+	data := make([]byte, codec.SampleSize()*channels*n)
+	for i := range data {
+		data[i] = byte(i*37 + 1)
+	}
+	return data
+}
+
+func TestAiffRoundTrip(t *testing.T) {
+	codecs := []*Codec{
+		NewPcmCodec(44_100, 8),
+		NewPcmCodec(44_100, 16),
+		NewPcmCodec(48_000, 24),
+		NewPcmCodec(8_000, 32),
+		NewCodec(PcmFloat, 8_000, 32),
+		NewCodec(PcmFloat, 8_000, 64),
+		PcmA8kHz8bCodec,
+		PcmU8kHz8bCodec,
+	}
+	for _, codec := range codecs {
+		for _, channels := range []int{1, 2} {
+			data := testData(codec, channels, 3)
+			a := NewAiffWithChannels(codec, channels)
+			if _, err := a.Write(data); err != nil {
+				t.Fatal(err)
+			}
+			var file bytes.Buffer
+			if _, err := a.WriteTo(&file); err != nil {
+				t.Fatal(err)
+			}
+
+			parsed, err := NewAiffFromBytes(file.Bytes())
+			if err != nil {
+				t.Fatalf("%s, channels=%d: %v", codec.Preset(), channels, err)
+			}
+			if !parsed.Codec().IsEqual(codec) || parsed.Channels() != channels || parsed.SampleCount() != 3 {
+				t.Errorf("%s, channels=%d: parsed %s, channels=%d, samples=%d", codec.Preset(), channels,
+					parsed.Codec().Preset(), parsed.Channels(), parsed.SampleCount())
+			}
+			if !bytes.Equal(parsed.Data(), data) {
+				t.Errorf("%s, channels=%d: data mismatch", codec.Preset(), channels)
+			}
+
+			// the parsed file is written back unchanged
+			var written bytes.Buffer
+			if _, err = parsed.WriteTo(&written); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(written.Bytes(), file.Bytes()) {
+				t.Errorf("%s, channels=%d: WriteTo of the parsed file differs", codec.Preset(), channels)
+			}
+			read, err := io.ReadAll(parsed)
+			if err != nil || !bytes.Equal(read, file.Bytes()) {
+				t.Errorf("%s, channels=%d: Read of the parsed file differs", codec.Preset(), channels)
+			}
+		}
+	}
+}
+
+func TestAiffSampleRate(t *testing.T) {
+	a := NewAiff(NewPcmCodec(44_100, 16))
+	a.Write(make([]byte, 2))
+	var file bytes.Buffer
+	a.WriteTo(&file)
+
+	// COMM: ID, size, channels, sample frames, sample size, then the 80-bit rate
+	rate := file.Bytes()[12+8+8 : 12+8+8+10]
+	if want := []byte{0x40, 0x0e, 0xac, 0x44, 0, 0, 0, 0, 0, 0}; !bytes.Equal(rate, want) {
+		t.Errorf("sample rate = % x, want % x", rate, want)
+	}
+}
+
+func TestAiffLittleEndian(t *testing.T) {
+	// AIFF-C "sowt" stores 16-bit samples in little-endian order
+	file := []byte("FORM\x00\x00\x00\x44AIFC" +
+		"COMM\x00\x00\x00\x18\x00\x01\x00\x00\x00\x02\x00\x10\x40\x0b\xfa\x00\x00\x00\x00\x00\x00\x00sowt\x00\x00" +
+		"SSND\x00\x00\x00\x0c\x00\x00\x00\x00\x00\x00\x00\x00\x34\x12\x78\x56")
+	a, err := NewAiffFromBytes(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.Codec().IsEqual(Pcm8kHz16bCodec) || !bytes.Equal(a.Data(), []byte{0x34, 0x12, 0x78, 0x56}) {
+		t.Errorf("parsed %s, data=% x", a.Codec().Preset(), a.Data())
+	}
+
+	// it is written back as big-endian AIFF
+	var written bytes.Buffer
+	a.WriteTo(&written)
+	if b := written.Bytes(); string(b[8:12]) != "AIFF" || !bytes.Equal(b[len(b)-4:], []byte{0x12, 0x34, 0x56, 0x78}) {
+		t.Errorf("written form=%q, samples=% x", b[8:12], b[len(b)-4:])
+	}
+}
+
+func TestReadAiffHeader(t *testing.T) {
+	codec := NewPcmCodec(44_100, 24)
+	a := NewAiffWithChannels(codec, 2)
+	a.Write(testData(codec, 2, 1000))
+	var file bytes.Buffer
+	if _, err := a.WriteTo(&file); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &memFile{b: file.Bytes()}
+	header, sampleCount, err := ReadAiffHeader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !header.Codec().IsEqual(codec) || header.Channels() != 2 || sampleCount != 1000 || header.DataSize() != 0 {
+		t.Errorf("header codec=%s, channels=%d, samples=%d, data size=%d", header.Codec().Preset(), header.Channels(), sampleCount, header.DataSize())
+	}
+	if f.read > len(f.b)-6000 {
+		t.Errorf("%d of %d bytes read", f.read, len(f.b))
+	}
+}
+
+func TestAiffReference(t *testing.T) {
+	// files written by the aifc module of Python: 16-bit stereo AIFF at 44.1 kHz and μ-law AIFF-C at 8 kHz
+	aiff := []byte{
+		0x46, 0x4f, 0x52, 0x4d, 0x00, 0x00, 0x00, 0x3a, 0x41, 0x49, 0x46, 0x46, 0x43, 0x4f, 0x4d, 0x4d, 0x00, 0x00, 0x00,
+		0x12, 0x00, 0x02, 0x00, 0x00, 0x00, 0x03, 0x00, 0x10, 0x40, 0x0e, 0xac, 0x44, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x53, 0x53, 0x4e, 0x44, 0x00, 0x00, 0x00, 0x14, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0xff,
+		0xfe, 0x01, 0x2c, 0xfe, 0x70, 0x7f, 0xff, 0x80, 0x00,
+	}
+	aifc := []byte{
+		0x46, 0x4f, 0x52, 0x4d, 0x00, 0x00, 0x00, 0x44, 0x41, 0x49, 0x46, 0x43, 0x46, 0x56, 0x45, 0x52, 0x00, 0x00, 0x00,
+		0x04, 0xa2, 0x80, 0x51, 0x40, 0x43, 0x4f, 0x4d, 0x4d, 0x00, 0x00, 0x00, 0x18, 0x00, 0x01, 0x00, 0x00, 0x00, 0x04,
+		0x00, 0x08, 0x40, 0x0b, 0xfa, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x55, 0x4c, 0x41, 0x57, 0x00, 0x00, 0x53,
+		0x53, 0x4e, 0x44, 0x00, 0x00, 0x00, 0x0c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x27, 0xa6, 0xef,
+	}
+	tests := []struct {
+		file     []byte
+		codec    *Codec
+		channels int
+		data     []byte
+	}{
+		{aiff, NewPcmCodec(44_100, 16), 2, []byte{0x01, 0x00, 0xfe, 0xff, 0x2c, 0x01, 0x70, 0xfe, 0xff, 0x7f, 0x00, 0x80}},
+		{aifc, PcmU8kHz8bCodec, 1, []byte{0xff, 0x27, 0xa6, 0xef}},
+	}
+	for _, test := range tests {
+		a, err := NewAiffFromBytes(test.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !a.Codec().IsEqual(test.codec) || a.Channels() != test.channels || !bytes.Equal(a.Data(), test.data) {
+			t.Errorf("parsed %s of %d channels, data=% x", a.Codec().Preset(), a.Channels(), a.Data())
+		}
+
+		// the file written from the data is parsed back the same
+		written := NewAiffWithChannels(test.codec, test.channels)
+		written.Write(test.data)
+		var file bytes.Buffer
+		if _, err = written.WriteTo(&file); err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := NewAiffFromBytes(file.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !parsed.Codec().IsEqual(test.codec) || parsed.Channels() != test.channels || !bytes.Equal(parsed.Data(), test.data) {
+			t.Errorf("written %s of %d channels, data=% x", parsed.Codec().Preset(), parsed.Channels(), parsed.Data())
+		}
+	}
+
+	// linear PCM is written as plain AIFF exactly like the reference
+	written := NewAiffWithChannels(tests[0].codec, 2)
+	written.Write(tests[0].data)
+	var file bytes.Buffer
+	written.WriteTo(&file)
+	if !bytes.Equal(file.Bytes(), aiff) {
+		t.Errorf("written file = % x, want % x", file.Bytes(), aiff)
+	}
+}
@@ -0,0 +1,113 @@
+package amr
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+// FrameDuration is the duration of every AMR and AMR-WB frame
+const FrameDuration = 20 * time.Millisecond
+
+// NoData is the frame type of an empty frame, e.g. during DTX
+const NoData = 15
+
+type Format int
+
+// Nb - AMR narrowband, 8 kHz.
+// Wb - AMR-WB wideband, 16 kHz.
+const (
+	Nb Format = iota
+	Wb
+)
+
+var (
+	// frameBits are the numbers of speech bits of frame types, -1 marks the types for future use.
+	// The frame types after the speech modes are SID, legacy SIDs (AMR) and SPEECH_LOST (AMR-WB).
+	frameBits = [2][16]int{
+		{95, 103, 118, 134, 148, 159, 204, 244, 39, 43, 38, 37, -1, -1, -1, 0},
+		{132, 177, 253, 285, 317, 365, 397, 461, 477, 40, -1, -1, -1, -1, 0, 0},
+	}
+	bitRates = [2][]int{
+		{4750, 5150, 5900, 6700, 7400, 7950, 10200, 12200},
+		{6600, 8850, 12650, 14250, 15850, 18250, 19850, 23050, 23850},
+	}
+)
+
+func (f Format) Codec() *audiocodec.Codec {
+	if f == Wb {
+		return audiocodec.AmrWbCodec
+	}
+	return audiocodec.AmrCodec
+}
+
+// PcmCodec returns the codec of the decoded samples.
+func (f Format) PcmCodec() *audiocodec.Codec {
+	if f == Wb {
+		return audiocodec.Pcm16kHz16bCodec
+	}
+	return audiocodec.Pcm8kHz16bCodec
+}
+
+// FrameSampleCount returns the number of samples in a frame.
+func (f Format) FrameSampleCount() int {
+	return f.Codec().SampleCountByDuration(FrameDuration)
+}
+
+// FrameBits returns the number of speech bits in a frame of the type.
+func (f Format) FrameBits(frameType int) (int, error) {
+	if frameType < 0 || frameType > 15 || frameBits[f][frameType] < 0 {
+		return 0, fmt.Errorf("%w: %d", InvalidFrameType, frameType)
+	}
+	return frameBits[f][frameType], nil
+}
+
+// IsSpeech reports whether the frame type is a speech mode.
+func (f Format) IsSpeech(frameType int) bool {
+	return frameType >= 0 && frameType < len(bitRates[f])
+}
+
+// IsSid reports whether the frame type carries comfort noise parameters (SID) of the discontinuous transmission.
+func (f Format) IsSid(frameType int) bool {
+	return frameType == len(bitRates[f])
+}
+
+// BitRate returns the bit rate in bit/s of the speech mode.
+func (f Format) BitRate(mode int) int {
+	if !f.IsSpeech(mode) {
+		return 0
+	}
+	return bitRates[f][mode]
+}
+
+// ModeByBitRate returns the speech mode of the bit rate in bit/s.
+func (f Format) ModeByBitRate(bitRate int) (int, error) {
+	for mode, rate := range bitRates[f] {
+		if rate == bitRate {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("not supported bit rate: %d", bitRate)
+}
+
+func (f Format) String() string {
+	if f == Wb {
+		return "AMR-WB"
+	}
+	return "AMR"
+}
+
+// Frame is a frame of speech bits in the order of the storage format and RTP payloads (sorted by sensitivity classes).
+type Frame struct {
+	Type int
+	// Quality is false for damaged frames
+	Quality bool
+	// Data holds the speech bits starting from the most significant bit of the first byte,
+	// the last byte is padded with zeros
+	Data []byte
+}
+
+func frameSize(bits int) int {
+	return (bits + 7) / 8
+}
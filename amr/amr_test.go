@@ -0,0 +1,51 @@
+package amr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFrameBits(t *testing.T) {
+	// sizes of frames of the storage format with the header byte, NO_DATA is the header only
+	sizes := map[Format]map[int]int{
+		Nb: {0: 13, 1: 14, 2: 16, 3: 18, 4: 20, 5: 21, 6: 27, 7: 32, 8: 6, NoData: 1},
+		Wb: {0: 18, 1: 24, 2: 33, 3: 37, 4: 41, 5: 47, 6: 51, 7: 59, 8: 61, 9: 6, NoData: 1},
+	}
+	for format, types := range sizes {
+		for frameType, size := range types {
+			bits, err := format.FrameBits(frameType)
+			if err != nil || 1+frameSize(bits) != size {
+				t.Errorf("%s frame type %d: %d bits, %v, want %d bytes", format, frameType, bits, err, size)
+			}
+		}
+	}
+	for _, frameType := range []int{-1, 12, 16} {
+		if _, err := Nb.FrameBits(frameType); !errors.Is(err, InvalidFrameType) {
+			t.Errorf("AMR frame type %d: %v, want %v", frameType, err, InvalidFrameType)
+		}
+	}
+	if _, err := Wb.FrameBits(10); !errors.Is(err, InvalidFrameType) {
+		t.Errorf("AMR-WB frame type 10: %v, want %v", err, InvalidFrameType)
+	}
+}
+
+func TestModes(t *testing.T) {
+	if Nb.BitRate(7) != 12_200 || Wb.BitRate(8) != 23_850 || Nb.BitRate(8) != 0 {
+		t.Errorf("bit rates %d, %d, %d", Nb.BitRate(7), Wb.BitRate(8), Nb.BitRate(8))
+	}
+	if mode, err := Wb.ModeByBitRate(12_650); err != nil || mode != 2 {
+		t.Errorf("AMR-WB mode of 12.65 kbit/s = %d, %v", mode, err)
+	}
+	if _, err := Nb.ModeByBitRate(12_650); err == nil {
+		t.Error("AMR mode of 12.65 kbit/s found")
+	}
+	if !Nb.IsSpeech(7) || Nb.IsSpeech(8) || !Nb.IsSid(8) || !Wb.IsSid(9) || Wb.IsSid(NoData) {
+		t.Error("wrong speech and SID frame types")
+	}
+	if Nb.FrameSampleCount() != 160 || Wb.FrameSampleCount() != 320 {
+		t.Errorf("frame sample counts %d, %d", Nb.FrameSampleCount(), Wb.FrameSampleCount())
+	}
+	if Nb.PcmCodec().SampleRate != 8000 || Wb.PcmCodec().SampleRate != 16_000 {
+		t.Errorf("PCM codecs %s, %s", Nb.PcmCodec().Preset(), Wb.PcmCodec().Preset())
+	}
+}
@@ -0,0 +1,10 @@
+package amr
+
+import "errors"
+
+var (
+	InvalidHeader    = errors.New("invalid AMR file header")
+	InvalidFrameType = errors.New("invalid AMR frame type")
+	InvalidPayload   = errors.New("invalid AMR RTP payload")
+	TruncatedFrame   = errors.New("truncated AMR frame")
+)
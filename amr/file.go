@@ -0,0 +1,167 @@
+package amr
+
+import (
+	"bufio"
+	"io"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+// Magic numbers of the single channel storage format (RFC 4867 section 5)
+const (
+	nbMagic = "#!AMR\n"
+	wbMagic = "#!AMR-WB\n"
+)
+
+// Смещение	Размер 	Описание
+// 0x00 	1 		Заголовок фрейма: P (1 бит, 0), FT (4 бита), Q (1 бит), P (2 бита, 0)
+// 0x01 	* 		Биты речи, дополненные нулями до целого байта
+
+// ParseFrame parses a frame of the storage format and returns it with its size.
+// The data of the returned frame refers to b.
+func ParseFrame(format Format, b []byte) (*Frame, int, error) {
+	if len(b) == 0 {
+		return nil, 0, TruncatedFrame
+	}
+	frame := &Frame{
+		Type:    int(b[0] >> 3 & 0x0F),
+		Quality: b[0]&0x04 != 0,
+	}
+	bits, err := format.FrameBits(frame.Type)
+	if err != nil {
+		return nil, 0, err
+	}
+	size := 1 + frameSize(bits)
+	if len(b) < size {
+		return nil, 0, TruncatedFrame
+	}
+	frame.Data = b[1:size:size]
+	return frame, size, nil
+}
+
+// AppendFrame appends the frame in the storage format to dst.
+func AppendFrame(dst []byte, format Format, frame *Frame) ([]byte, error) {
+	bits, err := format.FrameBits(frame.Type)
+	if err != nil {
+		return nil, err
+	}
+	size := frameSize(bits)
+	if len(frame.Data) < size {
+		return nil, TruncatedFrame
+	}
+
+	header := byte(frame.Type) << 3
+	if frame.Quality {
+		header |= 0x04
+	}
+	return append(append(dst, header), frame.Data[:size]...), nil
+}
+
+// Reader reads frames of a single channel .amr or .awb file.
+type Reader struct {
+	r      *bufio.Reader
+	format Format
+	frames int
+}
+
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{r: bufio.NewReader(r)}
+
+	b, err := reader.r.Peek(len(wbMagic))
+	switch {
+	case len(b) >= len(wbMagic) && string(b) == wbMagic:
+		reader.format = Wb
+		_, err = reader.r.Discard(len(wbMagic))
+	case len(b) >= len(nbMagic) && string(b[:len(nbMagic)]) == nbMagic:
+		reader.format = Nb
+		_, err = reader.r.Discard(len(nbMagic))
+	case err != nil && err != io.EOF:
+		return nil, err
+	default:
+		return nil, InvalidHeader
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return reader, nil
+}
+
+func (r *Reader) Format() Format {
+	return r.format
+}
+
+func (r *Reader) Codec() *audiocodec.Codec {
+	return r.format.Codec()
+}
+
+// ReadFrame returns the next frame or io.EOF.
+func (r *Reader) ReadFrame() (*Frame, error) {
+	header, err := r.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	frameType := int(header >> 3 & 0x0F)
+	bits, err := r.format.FrameBits(frameType)
+	if err != nil {
+		return nil, err
+	}
+	frame := &Frame{
+		Type:    frameType,
+		Quality: header&0x04 != 0,
+		Data:    make([]byte, frameSize(bits)),
+	}
+	if _, err = io.ReadFull(r.r, frame.Data); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, TruncatedFrame
+		}
+		return nil, err
+	}
+	r.frames++
+
+	return frame, nil
+}
+
+// Position returns the duration of the frames read so far.
+func (r *Reader) Position() time.Duration {
+	return time.Duration(r.frames) * FrameDuration
+}
+
+// Writer writes frames into a single channel .amr or .awb file.
+type Writer struct {
+	w      io.Writer
+	format Format
+	frames int
+	buffer []byte
+}
+
+// NewWriter writes the file header and returns the writer of frames.
+func NewWriter(w io.Writer, format Format) (*Writer, error) {
+	magic := nbMagic
+	if format == Wb {
+		magic = wbMagic
+	}
+	if _, err := io.WriteString(w, magic); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, format: format}, nil
+}
+
+func (w *Writer) WriteFrame(frame *Frame) error {
+	var err error
+	if w.buffer, err = AppendFrame(w.buffer[:0], w.format, frame); err != nil {
+		return err
+	}
+	if _, err = w.w.Write(w.buffer); err != nil {
+		return err
+	}
+	w.frames++
+	return nil
+}
+
+// Duration returns the duration of the written frames.
+func (w *Writer) Duration() time.Duration {
+	return time.Duration(w.frames) * FrameDuration
+}
@@ -0,0 +1,112 @@
+package amr
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// speechBits returns the bits of a frame filled with a pattern, the last byte is padded with zeros
+func speechBits(format Format, frameType int, seed byte) []byte {
+	bits, _ := format.FrameBits(frameType)
+	b := make([]byte, frameSize(bits))
+	for i := range b {
+		b[i] = seed + byte(i*37)
+	}
+	if bits%8 != 0 {
+		b[len(b)-1] &= 0xFF << (8 - bits%8)
+	}
+	return b
+}
+
+func TestFrame(t *testing.T) {
+	frame := &Frame{Type: 7, Quality: true, Data: speechBits(Nb, 7, 1)}
+	b, err := AppendFrame(nil, Nb, frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 32 || b[0] != 0x3c || !bytes.Equal(b[1:], frame.Data) {
+		t.Errorf("frame = % x", b)
+	}
+
+	parsed, size, err := ParseFrame(Nb, append(b, 0x7c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 32 || parsed.Type != 7 || !parsed.Quality || !bytes.Equal(parsed.Data, frame.Data) {
+		t.Errorf("parsed %+v of %d bytes", parsed, size)
+	}
+
+	if _, _, err = ParseFrame(Nb, b[:31]); !errors.Is(err, TruncatedFrame) {
+		t.Errorf("truncated frame: %v, want %v", err, TruncatedFrame)
+	}
+	if _, err = AppendFrame(nil, Nb, &Frame{Type: 7, Data: b[:10]}); !errors.Is(err, TruncatedFrame) {
+		t.Errorf("short frame data: %v, want %v", err, TruncatedFrame)
+	}
+}
+
+func TestWriterReader(t *testing.T) {
+	for _, format := range []Format{Nb, Wb} {
+		frames := []*Frame{
+			{Type: 0, Quality: true, Data: speechBits(format, 0, 1)},
+			{Type: 7, Quality: true, Data: speechBits(format, 7, 2)},
+			{Type: NoData, Quality: true, Data: []byte{}},
+			{Type: len(bitRates[format]), Quality: true, Data: speechBits(format, len(bitRates[format]), 3)},
+			{Type: 2, Quality: false, Data: speechBits(format, 2, 4)},
+		}
+		var file bytes.Buffer
+		w, err := NewWriter(&file, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, frame := range frames {
+			if err = w.WriteFrame(frame); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if w.Duration() != 100*time.Millisecond {
+			t.Errorf("%s written duration = %s", format, w.Duration())
+		}
+
+		r, err := NewReader(bytes.NewReader(file.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Format() != format || !r.Codec().IsEqual(format.Codec()) {
+			t.Errorf("%s read as %s", format, r.Format())
+		}
+		for i, want := range frames {
+			frame, err := r.ReadFrame()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if frame.Type != want.Type || frame.Quality != want.Quality || !bytes.Equal(frame.Data, want.Data) {
+				t.Errorf("%s frame %d = %+v, want %+v", format, i, frame, want)
+			}
+		}
+		if _, err = r.ReadFrame(); err != io.EOF {
+			t.Errorf("%s ReadFrame at the end: %v, want %v", format, err, io.EOF)
+		}
+		if r.Position() != 100*time.Millisecond {
+			t.Errorf("%s position = %s", format, r.Position())
+		}
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	for _, file := range []string{"", "#!AMR-NB\n", "RIFF"} {
+		if _, err := NewReader(bytes.NewReader([]byte(file))); !errors.Is(err, InvalidHeader) {
+			t.Errorf("NewReader(%q): %v, want %v", file, err, InvalidHeader)
+		}
+	}
+
+	r, err := NewReader(bytes.NewReader([]byte("#!AMR-WB\n\x44\x00")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.ReadFrame(); !errors.Is(err, TruncatedFrame) {
+		t.Errorf("truncated frame: %v, want %v", err, TruncatedFrame)
+	}
+}
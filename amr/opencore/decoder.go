@@ -0,0 +1,115 @@
+package opencore
+
+/*
+#cgo pkg-config: opencore-amrnb opencore-amrwb
+
+#include <opencore-amrnb/interf_dec.h>
+#include <opencore-amrwb/dec_if.h>
+*/
+import "C"
+import (
+	"errors"
+	"io"
+	"unsafe"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/amr"
+)
+
+// Decoder decompresses AMR or AMR-WB frames into 16-bit PCM of 8 or 16 kHz.
+type Decoder struct {
+	state   unsafe.Pointer
+	format  amr.Format
+	samples []C.short
+	frame   []byte
+}
+
+func NewDecoder(format amr.Format) (*Decoder, error) {
+	var state unsafe.Pointer
+	if format == amr.Wb {
+		state = C.D_IF_init()
+	} else {
+		state = C.Decoder_Interface_init()
+	}
+	if state == nil {
+		return nil, errors.New("failed to create AMR decoder")
+	}
+	return &Decoder{
+		state:   state,
+		format:  format,
+		samples: make([]C.short, format.FrameSampleCount()),
+	}, nil
+}
+
+// Decode returns the PCM of the frame. Damaged and lost frames (NO_DATA) are concealed by the decoder,
+// SID frames produce comfort noise.
+func (d *Decoder) Decode(frame *amr.Frame) ([]byte, error) {
+	var err error
+	if d.frame, err = amr.AppendFrame(d.frame[:0], d.format, frame); err != nil {
+		return nil, err
+	}
+
+	in := (*C.uchar)(unsafe.Pointer(&d.frame[0]))
+	if d.format == amr.Wb {
+		C.D_IF_decode(d.state, in, &d.samples[0], C._good_frame)
+	} else {
+		C.Decoder_Interface_Decode(d.state, in, &d.samples[0], 0)
+	}
+
+	pcm := make([]byte, 2*len(d.samples))
+	for i, s := range d.samples {
+		pcm[2*i] = byte(s)
+		pcm[2*i+1] = byte(uint16(s) >> 8)
+	}
+	return pcm, nil
+}
+
+// Codec returns the PCM codec of the decoded data.
+func (d *Decoder) Codec() *audiocodec.Codec {
+	return d.format.PcmCodec()
+}
+
+func (d *Decoder) Free() {
+	if d.state == nil {
+		return
+	}
+	if d.format == amr.Wb {
+		C.D_IF_exit(d.state)
+	} else {
+		C.Decoder_Interface_exit(d.state)
+	}
+	d.state = nil
+}
+
+// Decode reads the whole .amr or .awb file into a WAV.
+func Decode(r io.Reader) (*audiocodec.Wav, error) {
+	reader, err := amr.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	d, err := NewDecoder(reader.Format())
+	if err != nil {
+		return nil, err
+	}
+	defer d.Free()
+
+	wav := audiocodec.NewWav(d.Codec())
+	for {
+		frame, err := reader.ReadFrame()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		pcm, err := d.Decode(frame)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = wav.Write(pcm); err != nil {
+			return nil, err
+		}
+	}
+	return wav, nil
+}
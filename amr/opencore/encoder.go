@@ -0,0 +1,162 @@
+package opencore
+
+/*
+#cgo pkg-config: opencore-amrnb
+
+#include <opencore-amrnb/interf_enc.h>
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"unsafe"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/amr"
+)
+
+// maxFrameSize is the size of the largest frame of the storage format: AMR 12.2 with the header
+const maxFrameSize = 32
+
+// Encoder compresses 8 kHz 16-bit PCM into AMR frames.
+type Encoder struct {
+	state   unsafe.Pointer
+	mode    C.enum_Mode
+	buffer  []byte
+	samples []C.short
+	frame   []byte
+}
+
+// NewEncoder creates an encoder of the bit rate 4750 - 12200 bit/s, DTX replaces silence with SID and NO_DATA frames.
+func NewEncoder(bitRate int, dtx bool) (*Encoder, error) {
+	mode, err := amr.Nb.ModeByBitRate(bitRate)
+	if err != nil {
+		return nil, err
+	}
+
+	var dtxFlag C.int
+	if dtx {
+		dtxFlag = 1
+	}
+	state := C.Encoder_Interface_init(dtxFlag)
+	if state == nil {
+		return nil, errors.New("failed to create AMR encoder")
+	}
+	return &Encoder{
+		state:   state,
+		mode:    C.enum_Mode(mode),
+		samples: make([]C.short, amr.Nb.FrameSampleCount()),
+		frame:   make([]byte, maxFrameSize),
+	}, nil
+}
+
+// SetBitRate changes the bit rate of the next frames, e.g. on the codec mode request of the other side.
+func (e *Encoder) SetBitRate(bitRate int) error {
+	mode, err := amr.Nb.ModeByBitRate(bitRate)
+	if err != nil {
+		return err
+	}
+	e.mode = C.enum_Mode(mode)
+	return nil
+}
+
+// Encode buffers PCM data and returns the frames of all completed 20 ms blocks.
+func (e *Encoder) Encode(data []byte) ([]*amr.Frame, error) {
+	e.buffer = append(e.buffer, data...)
+
+	frameBytes := e.PcmCodec().SizeBySampleCount(len(e.samples))
+	var frames []*amr.Frame
+	for len(e.buffer) >= frameBytes {
+		frame, err := e.encode(e.buffer[:frameBytes])
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+		e.buffer = e.buffer[frameBytes:]
+	}
+	e.buffer = append(e.buffer[:0:0], e.buffer...)
+
+	return frames, nil
+}
+
+// Flush encodes the buffered incomplete block padded with silence.
+func (e *Encoder) Flush() (*amr.Frame, error) {
+	if len(e.buffer) == 0 {
+		return nil, nil
+	}
+
+	block := make([]byte, e.PcmCodec().SizeBySampleCount(len(e.samples)))
+	copy(block, e.buffer)
+	e.buffer = e.buffer[:0]
+
+	return e.encode(block)
+}
+
+func (e *Encoder) encode(block []byte) (*amr.Frame, error) {
+	for i := range e.samples {
+		e.samples[i] = C.short(int16(block[2*i]) | int16(block[2*i+1])<<8)
+	}
+
+	n := C.Encoder_Interface_Encode(e.state, e.mode, &e.samples[0], (*C.uchar)(unsafe.Pointer(&e.frame[0])), 0)
+	if n <= 0 {
+		return nil, fmt.Errorf("AMR encoder error: %d", int(n))
+	}
+
+	frame, _, err := amr.ParseFrame(amr.Nb, e.frame[:n])
+	if err != nil {
+		return nil, err
+	}
+	frame.Data = append([]byte(nil), frame.Data...)
+	return frame, nil
+}
+
+func (e *Encoder) Codec() *audiocodec.Codec {
+	return amr.Nb.Codec()
+}
+
+func (e *Encoder) PcmCodec() *audiocodec.Codec {
+	return amr.Nb.PcmCodec()
+}
+
+func (e *Encoder) Free() {
+	if e.state != nil {
+		C.Encoder_Interface_exit(e.state)
+		e.state = nil
+	}
+}
+
+// Encode writes the mono 8 kHz 16-bit PCM of the WAV into w as an .amr file.
+func Encode(w io.Writer, wav *audiocodec.Wav, bitRate int) error {
+	e, err := NewEncoder(bitRate, false)
+	if err != nil {
+		return err
+	}
+	defer e.Free()
+
+	if !wav.Codec().IsEqual(e.PcmCodec()) || wav.Channels() != 1 {
+		return fmt.Errorf("%w: %s", audiocodec.UnsupportedFormat, wav.Codec().Preset())
+	}
+
+	writer, err := amr.NewWriter(w, amr.Nb)
+	if err != nil {
+		return err
+	}
+	frames, err := e.Encode(wav.Data())
+	if err != nil {
+		return err
+	}
+	last, err := e.Flush()
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		frames = append(frames, last)
+	}
+	for _, frame := range frames {
+		if err = writer.WriteFrame(frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package amr
+
+import "fmt"
+
+// NoModeRequest is the CMR value of a payload without a codec mode request
+const NoModeRequest = 15
+
+// Payload is the RTP payload of a single channel session without interleaving and CRC (RFC 4867 section 4).
+type Payload struct {
+	// Cmr is the codec mode requested from the other side or NoModeRequest
+	Cmr    int
+	Frames []*Frame
+}
+
+// Октетно-выровненный режим (octet-aligned):
+// Смещение	Размер 	Описание
+// 0x00 	1 		CMR (4 бита), резерв (4 бита)
+// 0x01 	N 		Оглавление: по байту на фрейм - F (1 бит, 1 если далее есть фрейм), FT (4 бита), Q (1 бит), P (2 бита)
+// 0x01+N 	* 		Фреймы, каждый дополнен нулями до целого байта
+//
+// Экономный режим (bandwidth-efficient): CMR (4 бита), оглавление по 6 бит на фрейм (F, FT, Q), биты всех фреймов
+// подряд без выравнивания, в конце нули до целого байта.
+
+// ParsePayload parses the RTP payload of the format in the octet-aligned or bandwidth-efficient mode.
+// The data of frames of octet-aligned payloads refers to b.
+func ParsePayload(format Format, b []byte, octetAligned bool) (*Payload, error) {
+	r := bitReader{data: b}
+	p := &Payload{}
+	var err error
+	if p.Cmr, err = r.read(4); err != nil {
+		return nil, err
+	}
+	if octetAligned {
+		if _, err = r.read(4); err != nil {
+			return nil, err
+		}
+	}
+
+	for follows := true; follows; {
+		toc, err := r.read(6)
+		if err != nil {
+			return nil, err
+		}
+		if octetAligned {
+			if _, err = r.read(2); err != nil {
+				return nil, err
+			}
+		}
+		follows = toc&0x20 != 0
+		p.Frames = append(p.Frames, &Frame{
+			Type:    toc >> 1 & 0x0F,
+			Quality: toc&0x01 != 0,
+		})
+	}
+
+	for _, frame := range p.Frames {
+		bits, err := format.FrameBits(frame.Type)
+		if err != nil {
+			return nil, err
+		}
+		if octetAligned {
+			offset := r.offset / 8
+			size := frameSize(bits)
+			if offset+size > len(b) {
+				return nil, fmt.Errorf("%w: truncated frame", InvalidPayload)
+			}
+			frame.Data = b[offset : offset+size : offset+size]
+			r.offset += 8 * size
+			continue
+		}
+
+		frame.Data = make([]byte, frameSize(bits))
+		for i := 0; i < bits; i++ {
+			bit, err := r.read(1)
+			if err != nil {
+				return nil, err
+			}
+			frame.Data[i/8] |= byte(bit) << (7 - i%8)
+		}
+	}
+	return p, nil
+}
+
+// AppendPayload appends the payload in the octet-aligned or bandwidth-efficient mode to dst.
+func (p *Payload) AppendPayload(dst []byte, format Format, octetAligned bool) ([]byte, error) {
+	if len(p.Frames) == 0 {
+		return nil, fmt.Errorf("%w: no frames", InvalidPayload)
+	}
+
+	w := bitWriter{buf: dst}
+	w.write(p.Cmr, 4)
+	if octetAligned {
+		w.write(0, 4)
+	}
+	for i, frame := range p.Frames {
+		toc := frame.Type << 1
+		if i < len(p.Frames)-1 {
+			toc |= 0x20
+		}
+		if frame.Quality {
+			toc |= 0x01
+		}
+		w.write(toc, 6)
+		if octetAligned {
+			w.write(0, 2)
+		}
+	}
+
+	for _, frame := range p.Frames {
+		bits, err := format.FrameBits(frame.Type)
+		if err != nil {
+			return nil, err
+		}
+		if len(frame.Data) < frameSize(bits) {
+			return nil, TruncatedFrame
+		}
+		for i := 0; i < bits; i++ {
+			w.write(int(frame.Data[i/8]>>(7-i%8)&1), 1)
+		}
+		if octetAligned {
+			w.align()
+		}
+	}
+	w.align()
+	return w.buf, nil
+}
+
+type bitReader struct {
+	data   []byte
+	offset int // in bits
+}
+
+func (r *bitReader) read(n int) (int, error) {
+	if r.offset+n > 8*len(r.data) {
+		return 0, fmt.Errorf("%w: unexpected end", InvalidPayload)
+	}
+	v := 0
+	for i := 0; i < n; i++ {
+		v = v<<1 | int(r.data[r.offset/8]>>(7-r.offset%8)&1)
+		r.offset++
+	}
+	return v, nil
+}
+
+type bitWriter struct {
+	buf []byte
+	n   int // bits used in the last byte
+}
+
+func (w *bitWriter) write(v int, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if w.n == 0 {
+			w.buf = append(w.buf, 0)
+		}
+		w.buf[len(w.buf)-1] |= byte(v>>i&1) << (7 - w.n)
+		w.n = (w.n + 1) % 8
+	}
+}
+
+// align pads the last byte with zeros
+func (w *bitWriter) align() {
+	w.n = 0
+}
@@ -0,0 +1,98 @@
+package amr
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// payloadFrames are a 12.2 kbit/s speech frame, a SID frame and a NO_DATA frame of AMR
+func payloadFrames() []*Frame {
+	return []*Frame{
+		{Type: 7, Quality: true, Data: speechBits(Nb, 7, 1)},
+		{Type: 8, Quality: true, Data: speechBits(Nb, 8, 2)},
+		{Type: NoData, Data: []byte{}},
+	}
+}
+
+// the payloads of payloadFrames with the mode request of 12.2 kbit/s packed independently after RFC 4867
+var (
+	bandwidthEfficientPayload = []byte{
+		0x7b, 0xf1, 0x78, 0x04, 0x99, 0x2d, 0xc2, 0x56, 0xeb, 0x7c, 0x10, 0xa5, 0x39, 0xce, 0x62, 0xf7,
+		0x88, 0x1c, 0xb1, 0x45, 0xda, 0x6f, 0x03, 0x94, 0x28, 0xbd, 0x51, 0xe6, 0x7b, 0x0f, 0xa0, 0x34,
+		0xc9, 0x40, 0x89, 0xd3, 0x1c, 0x65, 0x80,
+	}
+	octetAlignedPayload = []byte{
+		0x70, 0xbc, 0xc4, 0x78, 0x01, 0x26, 0x4b, 0x70, 0x95, 0xba, 0xdf, 0x04, 0x29, 0x4e, 0x73, 0x98,
+		0xbd, 0xe2, 0x07, 0x2c, 0x51, 0x76, 0x9b, 0xc0, 0xe5, 0x0a, 0x2f, 0x54, 0x79, 0x9e, 0xc3, 0xe8,
+		0x0d, 0x32, 0x50, 0x02, 0x27, 0x4c, 0x71, 0x96,
+	}
+)
+
+func TestPayload(t *testing.T) {
+	tests := []struct {
+		octetAligned bool
+		payload      []byte
+	}{
+		{false, bandwidthEfficientPayload},
+		{true, octetAlignedPayload},
+	}
+	for _, test := range tests {
+		p := &Payload{Cmr: 7, Frames: payloadFrames()}
+		b, err := p.AppendPayload(nil, Nb, test.octetAligned)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, test.payload) {
+			t.Errorf("octet-aligned=%t: payload = % x, want % x", test.octetAligned, b, test.payload)
+		}
+
+		parsed, err := ParsePayload(Nb, test.payload, test.octetAligned)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.Cmr != 7 || len(parsed.Frames) != 3 {
+			t.Fatalf("octet-aligned=%t: parsed CMR=%d, %d frames", test.octetAligned, parsed.Cmr, len(parsed.Frames))
+		}
+		for i, want := range payloadFrames() {
+			frame := parsed.Frames[i]
+			if frame.Type != want.Type || frame.Quality != want.Quality || !bytes.Equal(frame.Data, want.Data) {
+				t.Errorf("octet-aligned=%t: frame %d = %+v, want %+v", test.octetAligned, i, frame, want)
+			}
+		}
+
+		if _, err = ParsePayload(Nb, test.payload[:len(test.payload)-2], test.octetAligned); !errors.Is(err, InvalidPayload) {
+			t.Errorf("octet-aligned=%t: truncated payload: %v, want %v", test.octetAligned, err, InvalidPayload)
+		}
+	}
+}
+
+func TestPayloadRoundTrip(t *testing.T) {
+	// every AMR-WB frame type with the codec mode request omitted
+	p := &Payload{Cmr: NoModeRequest}
+	for frameType := range 10 {
+		p.Frames = append(p.Frames, &Frame{Type: frameType, Quality: frameType%2 == 0, Data: speechBits(Wb, frameType, byte(frameType))})
+	}
+	for _, octetAligned := range []bool{false, true} {
+		b, err := p.AppendPayload(nil, Wb, octetAligned)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := ParsePayload(Wb, b, octetAligned)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.Cmr != NoModeRequest || len(parsed.Frames) != len(p.Frames) {
+			t.Fatalf("octet-aligned=%t: parsed CMR=%d, %d frames", octetAligned, parsed.Cmr, len(parsed.Frames))
+		}
+		for i, want := range p.Frames {
+			if frame := parsed.Frames[i]; frame.Type != want.Type || frame.Quality != want.Quality || !bytes.Equal(frame.Data, want.Data) {
+				t.Errorf("octet-aligned=%t: frame %d = %+v, want %+v", octetAligned, i, frame, want)
+			}
+		}
+	}
+
+	if _, err := (&Payload{}).AppendPayload(nil, Wb, true); !errors.Is(err, InvalidPayload) {
+		t.Errorf("payload without frames: %v, want %v", err, InvalidPayload)
+	}
+}
@@ -0,0 +1,163 @@
+package voamrwbenc
+
+/*
+#cgo pkg-config: vo-amrwbenc
+
+#include <vo-amrwbenc/enc_if.h>
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"unsafe"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/amr"
+)
+
+// maxFrameSize is the size of the largest frame of the storage format: AMR-WB 23.85 with the header
+const maxFrameSize = 61
+
+// Encoder compresses 16 kHz 16-bit PCM into AMR-WB frames.
+type Encoder struct {
+	state   unsafe.Pointer
+	mode    C.int
+	dtx     C.int
+	buffer  []byte
+	samples []C.short
+	frame   []byte
+}
+
+// NewEncoder creates an encoder of the bit rate 6600 - 23850 bit/s, DTX replaces silence with SID and NO_DATA frames.
+func NewEncoder(bitRate int, dtx bool) (*Encoder, error) {
+	mode, err := amr.Wb.ModeByBitRate(bitRate)
+	if err != nil {
+		return nil, err
+	}
+
+	state := C.E_IF_init()
+	if state == nil {
+		return nil, errors.New("failed to create AMR-WB encoder")
+	}
+	e := &Encoder{
+		state:   state,
+		mode:    C.int(mode),
+		samples: make([]C.short, amr.Wb.FrameSampleCount()),
+		frame:   make([]byte, maxFrameSize),
+	}
+	if dtx {
+		e.dtx = 1
+	}
+	return e, nil
+}
+
+// SetBitRate changes the bit rate of the next frames, e.g. on the codec mode request of the other side.
+func (e *Encoder) SetBitRate(bitRate int) error {
+	mode, err := amr.Wb.ModeByBitRate(bitRate)
+	if err != nil {
+		return err
+	}
+	e.mode = C.int(mode)
+	return nil
+}
+
+// Encode buffers PCM data and returns the frames of all completed 20 ms blocks.
+func (e *Encoder) Encode(data []byte) ([]*amr.Frame, error) {
+	e.buffer = append(e.buffer, data...)
+
+	frameBytes := e.PcmCodec().SizeBySampleCount(len(e.samples))
+	var frames []*amr.Frame
+	for len(e.buffer) >= frameBytes {
+		frame, err := e.encode(e.buffer[:frameBytes])
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+		e.buffer = e.buffer[frameBytes:]
+	}
+	e.buffer = append(e.buffer[:0:0], e.buffer...)
+
+	return frames, nil
+}
+
+// Flush encodes the buffered incomplete block padded with silence.
+func (e *Encoder) Flush() (*amr.Frame, error) {
+	if len(e.buffer) == 0 {
+		return nil, nil
+	}
+
+	block := make([]byte, e.PcmCodec().SizeBySampleCount(len(e.samples)))
+	copy(block, e.buffer)
+	e.buffer = e.buffer[:0]
+
+	return e.encode(block)
+}
+
+func (e *Encoder) encode(block []byte) (*amr.Frame, error) {
+	for i := range e.samples {
+		e.samples[i] = C.short(int16(block[2*i]) | int16(block[2*i+1])<<8)
+	}
+
+	n := C.E_IF_encode(e.state, e.mode, &e.samples[0], (*C.uchar)(unsafe.Pointer(&e.frame[0])), e.dtx)
+	if n <= 0 {
+		return nil, fmt.Errorf("AMR-WB encoder error: %d", int(n))
+	}
+
+	frame, _, err := amr.ParseFrame(amr.Wb, e.frame[:n])
+	if err != nil {
+		return nil, err
+	}
+	frame.Data = append([]byte(nil), frame.Data...)
+	return frame, nil
+}
+
+func (e *Encoder) Codec() *audiocodec.Codec {
+	return amr.Wb.Codec()
+}
+
+func (e *Encoder) PcmCodec() *audiocodec.Codec {
+	return amr.Wb.PcmCodec()
+}
+
+func (e *Encoder) Free() {
+	if e.state != nil {
+		C.E_IF_exit(e.state)
+		e.state = nil
+	}
+}
+
+// Encode writes the mono 16 kHz 16-bit PCM of the WAV into w as an .awb file.
+func Encode(w io.Writer, wav *audiocodec.Wav, bitRate int) error {
+	e, err := NewEncoder(bitRate, false)
+	if err != nil {
+		return err
+	}
+	defer e.Free()
+
+	if !wav.Codec().IsEqual(e.PcmCodec()) || wav.Channels() != 1 {
+		return fmt.Errorf("%w: %s", audiocodec.UnsupportedFormat, wav.Codec().Preset())
+	}
+
+	writer, err := amr.NewWriter(w, amr.Wb)
+	if err != nil {
+		return err
+	}
+	frames, err := e.Encode(wav.Data())
+	if err != nil {
+		return err
+	}
+	last, err := e.Flush()
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		frames = append(frames, last)
+	}
+	for _, frame := range frames {
+		if err = writer.WriteFrame(frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
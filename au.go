@@ -0,0 +1,358 @@
+package audiocodec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	auHeaderSize = 24
+	// auUnknownSize is the data size of streams written before their length is known
+	auUnknownSize = 0xFFFFFFFF
+)
+
+// Кодировки AU (поле Encoding заголовка)
+const (
+	auUlaw     = 1
+	auLinear8  = 2
+	auLinear16 = 3
+	auLinear24 = 4
+	auLinear32 = 5
+	auFloat    = 6
+	auDouble   = 7
+	auAlaw     = 27
+)
+
+// Au is a Sun/NeXT AU (.au, .snd) file. Data holds the samples in little-endian order as any other data described
+// by Codec, the big-endian samples of the file are converted on reading and writing.
+type Au struct {
+	header     []byte
+	data       []byte
+	sound      []byte // data as stored in the file
+	codec      *Codec
+	channels   int
+	annotation string
+	read       int
+	editable   bool
+}
+
+func NewAu(codec *Codec) *Au {
+	return NewAuWithChannels(codec, 1)
+}
+
+// NewAuWithChannels creates an AU for interleaved multichannel data, every channel is described by the codec.
+func NewAuWithChannels(codec *Codec, channels int) *Au {
+	return &Au{
+		codec:    codec,
+		channels: channels,
+		editable: true,
+	}
+}
+
+// Смещение	Размер 	Описание 			Значение
+// 0x00 	4		Magic number		".snd" (0x2E736E64)
+// 0x04 	4		Data offset			смещение данных, не меньше 24
+// 0x08 	4		Data size			размер данных или 0xFFFFFFFF, если неизвестен
+// 0x0c 	4		Encoding			1 - μ-law, 2/3/4/5 - PCM 8/16/24/32 бит, 6/7 - float/double, 27 - A-law
+// 0x10 	4		Sample rate			число выборок в секунду
+// 0x14 	4		Channels			число каналов
+// 0x18 	*		Annotation			произвольная строка, заканчивающаяся нулем, не меньше 4 байт
+
+func NewAuFromBytes(b []byte) (*Au, error) {
+	if len(b) < auHeaderSize || string(b[0:4]) != ".snd" {
+		return nil, InvalidAu
+	}
+
+	a := new(Au)
+	offset, size, err := a.parseHeader(b)
+	if err != nil {
+		return nil, err
+	}
+
+	sound := b[offset:]
+	if size != auUnknownSize && int(size) < len(sound) {
+		sound = sound[:size]
+	}
+	// a truncated last sample frame is dropped
+	frameSize := a.codec.SampleSize() * a.channels
+	sound = sound[:len(sound)/frameSize*frameSize]
+
+	a.data = make([]byte, len(sound))
+	copy(a.data, sound)
+	fromAuSamples(a.data, a.codec)
+	// the parsed file is written back with the known data size
+	a.prepare()
+	return a, nil
+}
+
+// ReadAuHeader reads the header of an AU file and seeks past the sample data. It returns an editable AU with
+// the format and annotation of the file but without data, and the number of samples per channel of the file.
+func ReadAuHeader(r io.ReadSeeker) (*Au, int, error) {
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, 0, err
+	}
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err = r.Seek(start, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+
+	b := make([]byte, auHeaderSize)
+	if _, err = io.ReadFull(r, b); err != nil || string(b[0:4]) != ".snd" {
+		return nil, 0, InvalidAu
+	}
+	// the annotation is read up to the data offset
+	if offset := int64(binary.BigEndian.Uint32(b[4:8])); offset > auHeaderSize && start+offset <= end {
+		b = append(b, make([]byte, offset-auHeaderSize)...)
+		if _, err = io.ReadFull(r, b[auHeaderSize:]); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	a := &Au{editable: true}
+	offset, size, err := a.parseHeader(b)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	soundSize := end - start - int64(offset)
+	if size != auUnknownSize && int64(size) < soundSize {
+		soundSize = int64(size)
+	}
+	if _, err = r.Seek(start+int64(offset)+soundSize, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+	return a, int(soundSize / int64(a.codec.SampleSize()*a.channels)), nil
+}
+
+// parseHeader parses the header and annotation of b, it returns the data offset and the data size of the header.
+func (a *Au) parseHeader(b []byte) (int, uint32, error) {
+	offset := int(binary.BigEndian.Uint32(b[4:8]))
+	size := binary.BigEndian.Uint32(b[8:12])
+	encoding := binary.BigEndian.Uint32(b[12:16])
+	sampleRate := int(binary.BigEndian.Uint32(b[16:20]))
+	channels := int(binary.BigEndian.Uint32(b[20:24]))
+	if offset < auHeaderSize || offset > len(b) {
+		return 0, 0, fmt.Errorf("%w: data offset=%d", InvalidAu, offset)
+	}
+
+	codec, err := auCodec(encoding, sampleRate)
+	if err != nil {
+		return 0, 0, err
+	}
+	if channels == 0 || sampleRate == 0 {
+		return 0, 0, fmt.Errorf("%w: channels=%d, sample rate=%d", InvalidAu, channels, sampleRate)
+	}
+
+	annotation := b[auHeaderSize:offset]
+	for i, c := range annotation {
+		if c == 0 {
+			annotation = annotation[:i]
+			break
+		}
+	}
+
+	a.codec = codec
+	a.channels = channels
+	a.annotation = string(annotation)
+	return offset, size, nil
+}
+
+func (a *Au) DataSize() int {
+	return len(a.data)
+}
+
+func (a *Au) Write(data []byte) (int, error) {
+	if !a.editable {
+		return 0, AuFileIsNotEditable
+	}
+
+	a.data = append(a.data, data...)
+	return len(data), nil
+}
+
+func (a *Au) Read(p []byte) (n int, err error) {
+	if a.editable {
+		a.editable = false
+		a.prepare()
+	}
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if a.read >= len(a.header)+len(a.sound) {
+		return 0, io.EOF
+	}
+
+	if a.read < len(a.header) {
+		n = copy(p, a.header[a.read:])
+		a.read += n
+	}
+	m := copy(p[n:], a.sound[a.read-len(a.header):])
+	a.read += m
+	return n + m, nil
+}
+
+func (a *Au) Codec() *Codec {
+	return a.codec
+}
+
+func (a *Au) Channels() int {
+	return a.channels
+}
+
+// SampleCount returns the number of samples per channel.
+func (a *Au) SampleCount() int {
+	return len(a.data) / (a.codec.SampleSize() * a.channels)
+}
+
+func (a *Au) Annotation() string {
+	return a.annotation
+}
+
+func (a *Au) SetAnnotation(annotation string) {
+	a.annotation = annotation
+}
+
+func (a *Au) WriteTo(writer io.Writer) (size int64, err error) {
+	if a.editable {
+		a.editable = false
+		a.prepare()
+	}
+
+	var n int
+	if n, err = writer.Write(a.header); err != nil {
+		return 0, err
+	}
+	size += int64(n)
+
+	if n, err = writer.Write(a.sound); err != nil {
+		return 0, err
+	}
+	size += int64(n)
+
+	return size, nil
+}
+
+func (a *Au) Data() []byte {
+	return a.data
+}
+
+func (a *Au) prepare() {
+	a.header = auHeader(a.codec, a.channels, a.annotation, uint32(len(a.data)))
+	a.sound = make([]byte, len(a.data))
+	copy(a.sound, a.data)
+	toAuSamples(a.sound, a.codec)
+}
+
+// AuWriter streams an AU file of unknown length: the data size of the header is 0xFFFFFFFF.
+type AuWriter struct {
+	w      io.Writer
+	codec  *Codec
+	buffer []byte
+}
+
+// NewAuWriter writes the header of the stream and returns the writer of little-endian data described by the codec.
+func NewAuWriter(w io.Writer, codec *Codec, channels int) (*AuWriter, error) {
+	if _, err := auEncoding(codec); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(auHeader(codec, channels, "", auUnknownSize)); err != nil {
+		return nil, err
+	}
+	return &AuWriter{w: w, codec: codec}, nil
+}
+
+// Write converts whole samples of data into the byte order of the file and writes them, the tail of an incomplete
+// sample is kept until the next call.
+func (w *AuWriter) Write(data []byte) (int, error) {
+	w.buffer = append(w.buffer, data...)
+	size := w.codec.SampleSize()
+	n := len(w.buffer) / size * size
+	sound := w.buffer[:n]
+	toAuSamples(sound, w.codec)
+	if _, err := w.w.Write(sound); err != nil {
+		return 0, err
+	}
+	w.buffer = append(w.buffer[:0], w.buffer[n:]...)
+	return len(data), nil
+}
+
+// Close checks that the stream ends with a whole sample, it does not close the underlying writer.
+func (w *AuWriter) Close() error {
+	if len(w.buffer) > 0 {
+		return fmt.Errorf("%w: %d bytes left", IncompleteAuSample, len(w.buffer))
+	}
+	return nil
+}
+
+func auHeader(codec *Codec, channels int, annotation string, size uint32) []byte {
+	encoding, err := auEncoding(codec)
+	if err != nil {
+		panic(err)
+	}
+
+	// the annotation is null-terminated and padded to a multiple of 4 bytes
+	annotationSize := (len(annotation) + 4) / 4 * 4
+	h := make([]byte, 0, auHeaderSize+annotationSize)
+	h = append(h, ".snd"...)
+	h = binary.BigEndian.AppendUint32(h, uint32(auHeaderSize+annotationSize))
+	h = binary.BigEndian.AppendUint32(h, size)
+	h = binary.BigEndian.AppendUint32(h, encoding)
+	h = binary.BigEndian.AppendUint32(h, uint32(codec.SampleRate))
+	h = binary.BigEndian.AppendUint32(h, uint32(channels))
+	h = append(h, annotation...)
+	return append(h, make([]byte, annotationSize-len(annotation))...)
+}
+
+func auCodec(encoding uint32, sampleRate int) (*Codec, error) {
+	switch encoding {
+	case auUlaw:
+		return NewCodec(PcmU, sampleRate, 8), nil
+	case auAlaw:
+		return NewCodec(PcmA, sampleRate, 8), nil
+	case auLinear8, auLinear16, auLinear24, auLinear32:
+		return NewPcmCodec(sampleRate, int(encoding-auLinear8+1)*8), nil
+	case auFloat:
+		return NewCodec(PcmFloat, sampleRate, 32), nil
+	case auDouble:
+		return NewCodec(PcmFloat, sampleRate, 64), nil
+	}
+	return nil, fmt.Errorf("%w: encoding=%d", UnsupportedAuFormat, encoding)
+}
+
+func auEncoding(codec *Codec) (uint32, error) {
+	switch {
+	case codec.Name == PcmU:
+		return auUlaw, nil
+	case codec.Name == PcmA:
+		return auAlaw, nil
+	case codec.Name == Pcm && codec.BitRate >= 8 && codec.BitRate <= 32 && codec.BitRate%8 == 0:
+		return auLinear8 + uint32(codec.BitRate/8-1), nil
+	case codec.Name == PcmFloat && codec.BitRate == 32:
+		return auFloat, nil
+	case codec.Name == PcmFloat && codec.BitRate == 64:
+		return auDouble, nil
+	}
+	return 0, fmt.Errorf("%w: %s", UnsupportedAuFormat, codec.Preset())
+}
+
+// toAuSamples converts little-endian data in place into the signed big-endian samples of AU files
+func toAuSamples(data []byte, codec *Codec) {
+	if codec.Name == Pcm && codec.BitRate == 8 {
+		flipSign(data)
+	}
+	swapByteOrder(data, codec)
+}
+
+// fromAuSamples converts the samples of AU files in place into little-endian data
+func fromAuSamples(data []byte, codec *Codec) {
+	swapByteOrder(data, codec)
+	if codec.Name == Pcm && codec.BitRate == 8 {
+		flipSign(data)
+	}
+}
@@ -0,0 +1,191 @@
+package audiocodec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestAuRoundTrip(t *testing.T) {
+	codecs := []*Codec{
+		NewPcmCodec(8_000, 8),
+		NewPcmCodec(8_000, 16),
+		NewPcmCodec(8_000, 24),
+		NewPcmCodec(8_000, 32),
+		NewCodec(PcmFloat, 8_000, 32),
+		NewCodec(PcmFloat, 8_000, 64),
+		PcmA8kHz8bCodec,
+		PcmU8kHz8bCodec,
+	}
+	for _, codec := range codecs {
+		for _, annotation := range []string{"", "call 42"} {
+			data := testData(codec, 2, 3)
+			a := NewAuWithChannels(codec, 2)
+			a.SetAnnotation(annotation)
+			a.Write(data)
+			var file bytes.Buffer
+			if _, err := a.WriteTo(&file); err != nil {
+				t.Fatal(err)
+			}
+
+			parsed, err := NewAuFromBytes(file.Bytes())
+			if err != nil {
+				t.Fatalf("%s: %v", codec.Preset(), err)
+			}
+			if !parsed.Codec().IsEqual(codec) || parsed.Channels() != 2 || parsed.SampleCount() != 3 || parsed.Annotation() != annotation {
+				t.Errorf("%s: parsed %s, channels=%d, samples=%d, annotation=%q", codec.Preset(),
+					parsed.Codec().Preset(), parsed.Channels(), parsed.SampleCount(), parsed.Annotation())
+			}
+			if !bytes.Equal(parsed.Data(), data) {
+				t.Errorf("%s: data mismatch", codec.Preset())
+			}
+
+			var written bytes.Buffer
+			if _, err = parsed.WriteTo(&written); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(written.Bytes(), file.Bytes()) {
+				t.Errorf("%s: WriteTo of the parsed file differs", codec.Preset())
+			}
+			read, err := io.ReadAll(parsed)
+			if err != nil || !bytes.Equal(read, file.Bytes()) {
+				t.Errorf("%s: Read of the parsed file differs", codec.Preset())
+			}
+		}
+	}
+}
+
+func TestAuByteOrder(t *testing.T) {
+	// 16-bit linear PCM at 8 kHz, mono, big-endian samples 0x1234 and 0x5678
+	file := []byte(".snd\x00\x00\x00\x18\x00\x00\x00\x04\x00\x00\x00\x03\x00\x00\x1f\x40\x00\x00\x00\x01\x12\x34\x56\x78")
+	a, err := NewAuFromBytes(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.Codec().IsEqual(Pcm8kHz16bCodec) || !bytes.Equal(a.Data(), []byte{0x34, 0x12, 0x78, 0x56}) {
+		t.Errorf("parsed %s, data=% x", a.Codec().Preset(), a.Data())
+	}
+}
+
+func TestAuUnknownSize(t *testing.T) {
+	var file bytes.Buffer
+	w, err := NewAuWriter(&file, PcmU8kHz8bCodec, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte{1, 2, 3})
+	if size := binary.BigEndian.Uint32(file.Bytes()[8:12]); size != auUnknownSize {
+		t.Errorf("data size = %#x, want %#x", size, uint32(auUnknownSize))
+	}
+
+	a, err := NewAuFromBytes(file.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.Data(), []byte{1, 2, 3}) {
+		t.Errorf("data = % x", a.Data())
+	}
+
+	// the parsed stream is written back with the known size
+	var written bytes.Buffer
+	a.WriteTo(&written)
+	if size := binary.BigEndian.Uint32(written.Bytes()[8:12]); size != 3 {
+		t.Errorf("written data size = %d, want 3", size)
+	}
+}
+
+func TestAuWriterClose(t *testing.T) {
+	var file bytes.Buffer
+	w, _ := NewAuWriter(&file, Pcm8kHz16bCodec, 1)
+	w.Write([]byte{1, 2, 3})
+	if err := w.Close(); !errors.Is(err, IncompleteAuSample) {
+		t.Errorf("Close() = %v, want %v", err, IncompleteAuSample)
+	}
+	w.Write([]byte{4})
+	if err := w.Close(); err != nil {
+		t.Errorf("Close() = %v", err)
+	}
+	offset := binary.BigEndian.Uint32(file.Bytes()[4:8])
+	if b := file.Bytes()[offset:]; !bytes.Equal(b, []byte{2, 1, 4, 3}) {
+		t.Errorf("samples = % x", b)
+	}
+}
+
+func TestReadAuHeader(t *testing.T) {
+	a := NewAuWithChannels(PcmA8kHz8bCodec, 2)
+	a.SetAnnotation("call")
+	a.Write(testData(PcmA8kHz8bCodec, 2, 1000))
+	var file bytes.Buffer
+	if _, err := a.WriteTo(&file); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &memFile{b: file.Bytes()}
+	header, sampleCount, err := ReadAuHeader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !header.Codec().IsEqual(PcmA8kHz8bCodec) || header.Channels() != 2 || header.Annotation() != "call" || sampleCount != 1000 {
+		t.Errorf("header codec=%s, channels=%d, annotation=%q, samples=%d", header.Codec().Preset(), header.Channels(), header.Annotation(), sampleCount)
+	}
+	if f.read > len(f.b)-2000 {
+		t.Errorf("%d of %d bytes read", f.read, len(f.b))
+	}
+
+	// the size of streams of unknown length is the rest of the file
+	binary.BigEndian.PutUint32(f.b[8:12], auUnknownSize)
+	f.b = f.b[:len(f.b)-3]
+	f.pos = 0
+	if _, sampleCount, err = ReadAuHeader(f); err != nil || sampleCount != 998 {
+		t.Errorf("unknown size: samples=%d, err=%v", sampleCount, err)
+	}
+}
+
+func TestAuReference(t *testing.T) {
+	// files written by the sunau module of Python: 16-bit stereo and μ-law mono at 8 kHz with 8 bytes of annotation
+	linear := []byte{
+		0x2e, 0x73, 0x6e, 0x64, 0x00, 0x00, 0x00, 0x20, 0x00, 0x00, 0x00, 0x08, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x1f,
+		0x40, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0xfe, 0xff, 0x2c, 0x01,
+		0x70, 0xfe,
+	}
+	ulaw := []byte{
+		0x2e, 0x73, 0x6e, 0x64, 0x00, 0x00, 0x00, 0x20, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x1f,
+		0x40, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0xce, 0x4e, 0x80,
+	}
+	tests := []struct {
+		file     []byte
+		codec    *Codec
+		channels int
+		data     []byte
+	}{
+		{linear, Pcm8kHz16bCodec, 2, []byte{0x00, 0x01, 0xff, 0xfe, 0x01, 0x2c, 0xfe, 0x70}},
+		{ulaw, PcmU8kHz8bCodec, 1, []byte{0xff, 0xce, 0x4e, 0x80}},
+	}
+	for _, test := range tests {
+		a, err := NewAuFromBytes(test.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !a.Codec().IsEqual(test.codec) || a.Channels() != test.channels || a.Annotation() != "" || !bytes.Equal(a.Data(), test.data) {
+			t.Errorf("parsed %s of %d channels, annotation=%q, data=% x", a.Codec().Preset(), a.Channels(), a.Annotation(), a.Data())
+		}
+
+		// the file written from the data is parsed back the same
+		written := NewAuWithChannels(test.codec, test.channels)
+		written.SetAnnotation("reference")
+		written.Write(test.data)
+		var file bytes.Buffer
+		if _, err = written.WriteTo(&file); err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := NewAuFromBytes(file.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !parsed.Codec().IsEqual(test.codec) || parsed.Channels() != test.channels || parsed.Annotation() != "reference" || !bytes.Equal(parsed.Data(), test.data) {
+			t.Errorf("written %s of %d channels, annotation=%q, data=% x", parsed.Codec().Preset(), parsed.Channels(), parsed.Annotation(), parsed.Data())
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package binary
 
+import (
+	"encoding/binary"
+	"math"
+)
+
 func Bytes16bitToFloat32(sample []byte) float32 {
 	_ = sample[1]
 	v := int16(sample[0]) | int16(sample[1])<<8
@@ -19,3 +24,34 @@ func Bytes32bitToFloat32(sample []byte) float32 {
 		return float32(v) / 2_147_483_647
 	}
 }
+
+// Bytes8bitToFloat32 decodes an unsigned 8-bit sample as used by WAV files
+func Bytes8bitToFloat32(sample []byte) float32 {
+	v := int16(sample[0]) - 128
+	if v < 0 {
+		return float32(v) / 128
+	} else {
+		return float32(v) / 127
+	}
+}
+
+func Bytes24bitToFloat32(sample []byte) float32 {
+	_ = sample[2]
+	v := int32(sample[0])<<8 | int32(sample[1])<<16 | int32(sample[2])<<24
+	v >>= 8
+	if v < 0 {
+		return float32(v) / 8_388_608
+	} else {
+		return float32(v) / 8_388_607
+	}
+}
+
+// BytesFloat32ToFloat32 decodes a little-endian IEEE 754 single precision sample
+func BytesFloat32ToFloat32(sample []byte) float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(sample))
+}
+
+// BytesFloat64ToFloat32 decodes a little-endian IEEE 754 double precision sample
+func BytesFloat64ToFloat32(sample []byte) float32 {
+	return float32(math.Float64frombits(binary.LittleEndian.Uint64(sample)))
+}
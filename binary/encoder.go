@@ -1,6 +1,9 @@
 package binary
 
-import "encoding/binary"
+import (
+	"encoding/binary"
+	"math"
+)
 
 func Float32ToBytes16bit(sample float32, buf []byte) {
 	var v uint16
@@ -21,3 +24,37 @@ func Float32ToBytes32bit(sample float32, buf []byte) {
 	}
 	binary.LittleEndian.PutUint32(buf, v)
 }
+
+// Float32ToBytes8bit encodes an unsigned 8-bit sample as used by WAV files
+func Float32ToBytes8bit(sample float32, buf []byte) {
+	var v int16
+	if sample < 0 {
+		v = int16(128 * sample)
+	} else {
+		v = int16(127 * sample)
+	}
+	buf[0] = byte(v + 128)
+}
+
+func Float32ToBytes24bit(sample float32, buf []byte) {
+	_ = buf[2]
+	var v int32
+	if sample < 0 {
+		v = int32(8_388_608 * sample)
+	} else {
+		v = int32(8_388_607 * sample)
+	}
+	buf[0] = byte(v)
+	buf[1] = byte(v >> 8)
+	buf[2] = byte(v >> 16)
+}
+
+// Float32ToBytesFloat32 encodes a little-endian IEEE 754 single precision sample
+func Float32ToBytesFloat32(sample float32, buf []byte) {
+	binary.LittleEndian.PutUint32(buf, math.Float32bits(sample))
+}
+
+// Float32ToBytesFloat64 encodes a little-endian IEEE 754 double precision sample
+func Float32ToBytesFloat64(sample float32, buf []byte) {
+	binary.LittleEndian.PutUint64(buf, math.Float64bits(float64(sample)))
+}
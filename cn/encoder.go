@@ -0,0 +1,147 @@
+package cn
+
+import (
+	"math"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/pcm"
+)
+
+const (
+	DefaultOrder = 10
+	// smoothing is the weight of the previous frames in the averaged autocorrelation
+	smoothing = 0.7
+)
+
+// Encoder estimates the level and the spectral envelope of background noise and builds comfort noise payloads.
+// The estimation is averaged over consecutive frames, so frames classified as silence by VAD should be passed.
+type Encoder struct {
+	codec   *audiocodec.Codec
+	decoder func(sample []byte) float32
+	order   int
+
+	samples         []float64
+	autocorrelation []float64
+	initialized     bool
+}
+
+func NewEncoder(codec *audiocodec.Codec, order int) (*Encoder, error) {
+	if order < 0 || order > 254 {
+		return nil, InvalidOrder
+	}
+
+	decoder, err := pcm.Decoder(codec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Encoder{
+		codec:           codec,
+		decoder:         decoder,
+		order:           order,
+		autocorrelation: make([]float64, order+1),
+	}, nil
+}
+
+// Encode updates the noise estimation with the frame and returns the payload describing it.
+func (e *Encoder) Encode(frame []byte) (Payload, error) {
+	sampleSize := e.codec.SampleSize()
+	if len(frame)%sampleSize != 0 {
+		return Payload{}, pcm.InvalidFrameSize
+	}
+
+	e.samples = e.samples[:0]
+	for i := 0; i < len(frame); i += sampleSize {
+		e.samples = append(e.samples, float64(e.decoder(frame[i:i+sampleSize])))
+	}
+	if len(e.samples) > 0 {
+		e.update()
+	}
+
+	return e.Payload(), nil
+}
+
+// Payload returns the current noise estimation.
+func (e *Encoder) Payload() Payload {
+	r := e.autocorrelation
+	p := Payload{
+		Level: LevelByPower(r[0]),
+	}
+	if e.order == 0 || r[0] == 0 {
+		return p
+	}
+
+	// Levinson-Durbin recursion
+	p.Coefficients = make([]float64, 0, e.order)
+	predictor := make([]float64, 0, e.order)
+	predictionError := r[0]
+	for m := 0; m < e.order; m++ {
+		acc := r[m+1]
+		for i, a := range predictor {
+			acc += a * r[m-i]
+		}
+		k := clampCoefficient(-acc / predictionError)
+		p.Coefficients = append(p.Coefficients, k)
+		predictor = stepUp(predictor, k)
+		predictionError *= 1 - k*k
+	}
+
+	return p
+}
+
+func (e *Encoder) Reset() {
+	e.initialized = false
+	clear(e.autocorrelation)
+}
+
+func (e *Encoder) Codec() *audiocodec.Codec {
+	return e.codec
+}
+
+// update mixes the normalized autocorrelation of the Hamming windowed frame into the running estimation
+func (e *Encoder) update() {
+	n := len(e.samples)
+
+	var power float64
+	for _, x := range e.samples {
+		power += x * x
+	}
+	power /= float64(n)
+
+	if n > 1 {
+		for i := range e.samples {
+			e.samples[i] *= 0.54 - 0.46*math.Cos(2*math.Pi*float64(i)/float64(n-1))
+		}
+	}
+
+	current := make([]float64, len(e.autocorrelation))
+	for lag := range current {
+		if lag >= n {
+			break
+		}
+		var acc float64
+		for i := lag; i < n; i++ {
+			acc += e.samples[i] * e.samples[i-lag]
+		}
+		current[lag] = acc
+	}
+
+	// Scale the windowed autocorrelation to the power of the frame before windowing,
+	// a slight white noise correction keeps the recursion well conditioned
+	if current[0] > 0 {
+		scale := power / current[0]
+		for lag := range current {
+			current[lag] *= scale
+		}
+		current[0] *= 1.0001
+	}
+
+	if !e.initialized {
+		copy(e.autocorrelation, current)
+		e.initialized = true
+		return
+	}
+	for lag := range current {
+		e.autocorrelation[lag] = smoothing*e.autocorrelation[lag] + (1-smoothing)*current[lag]
+	}
+}
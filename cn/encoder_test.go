@@ -0,0 +1,85 @@
+package cn
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+func TestNewEncoder(t *testing.T) {
+	for _, order := range []int{-1, 255} {
+		if _, err := NewEncoder(audiocodec.Pcm8kHz16bCodec, order); !errors.Is(err, InvalidOrder) {
+			t.Errorf("order %d: %v, want %v", order, err, InvalidOrder)
+		}
+	}
+}
+
+func TestEncodeSilence(t *testing.T) {
+	e, err := NewEncoder(audiocodec.Pcm8kHz16bCodec, DefaultOrder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := e.Encode(make([]byte, 320))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Level != MaxLevel || p.Coefficients != nil {
+		t.Errorf("payload of silence %+v", p)
+	}
+}
+
+func TestGeneratedNoiseRoundTrip(t *testing.T) {
+	// noise generated from a payload is described by the encoder with the same level and envelope
+	for _, codec := range []*audiocodec.Codec{audiocodec.Pcm8kHz16bCodec, audiocodec.PcmA8kHz8bCodec} {
+		payload := Payload{Level: 40, Coefficients: []float64{-0.75, 0.25}}
+		g, err := NewGenerator(codec, -70)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the estimation follows the last few frames, a fixed seed keeps it reproducible
+		g.random = rand.New(rand.NewPCG(1, 2))
+		g.Update(payload)
+		e, err := NewEncoder(codec, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var p Payload
+		for range 200 {
+			if p, err = e.Encode(g.Frame(20 * time.Millisecond)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if p.Level < 39 || p.Level > 41 || len(p.Coefficients) != 2 {
+			t.Fatalf("%s: payload %+v", codec.Preset(), p)
+		}
+		for i, k := range payload.Coefficients {
+			if math.Abs(p.Coefficients[i]-k) > 0.1 {
+				t.Errorf("%s: coefficient %d = %.3f, want %.3f", codec.Preset(), i, p.Coefficients[i], k)
+			}
+		}
+	}
+}
+
+func TestGeneratorLevel(t *testing.T) {
+	g, err := NewGenerator(audiocodec.Pcm16kHz16bCodec, -30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := g.Frame(time.Second)
+	if len(frame) != 32_000 {
+		t.Fatalf("frame of %d bytes", len(frame))
+	}
+	var power float64
+	for i := 0; i < len(frame); i += 2 {
+		v := float64(int16(uint16(frame[i])|uint16(frame[i+1])<<8)) / 32_768
+		power += v * v
+	}
+	if level := 10 * math.Log10(power/16_000); math.Abs(level+30) > 0.5 {
+		t.Errorf("level = %.2f dBov", level)
+	}
+}
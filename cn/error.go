@@ -0,0 +1,8 @@
+package cn
+
+import "errors"
+
+var (
+	EmptyPayload = errors.New("comfort noise payload is empty")
+	InvalidOrder = errors.New("comfort noise model order must be in range 0-254")
+)
@@ -0,0 +1,117 @@
+package cn
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/pcm"
+)
+
+// Generator renders comfort noise: white noise shaped by the all-pole filter of the reflection coefficients
+// and scaled to the noise level.
+type Generator struct {
+	codec      *audiocodec.Codec
+	encoder    func(sample float32, buf []byte)
+	sampleSize int
+	random     *rand.Rand
+
+	coefficients []float64 // reflection coefficients
+	predictor    []float64 // direct form coefficients of the synthesis filter
+	history      []float64 // previous output samples, the latest first
+	gain         float64   // standard deviation of the excitation
+}
+
+// NewGenerator creates a generator of white noise with the level in dBov (e.g. -70).
+func NewGenerator(codec *audiocodec.Codec, level float64) (*Generator, error) {
+	encoder, err := pcm.Encoder(codec)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Generator{
+		codec:      codec,
+		encoder:    encoder,
+		sampleSize: codec.SampleSize(),
+		random:     rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+	g.SetLevel(level)
+
+	return g, nil
+}
+
+// SetLevel changes the level of the noise in dBov keeping its spectral envelope.
+func (g *Generator) SetLevel(level float64) {
+	g.setPower(math.Pow(10, level/10))
+}
+
+// Update applies a received comfort noise payload.
+func (g *Generator) Update(p Payload) {
+	g.coefficients = g.coefficients[:0]
+	g.predictor = g.predictor[:0]
+	for _, k := range p.Coefficients {
+		k = clampCoefficient(k)
+		g.coefficients = append(g.coefficients, k)
+		g.predictor = stepUp(g.predictor, k)
+	}
+	if len(g.history) != len(g.predictor) {
+		g.history = make([]float64, len(g.predictor))
+	}
+	g.setPower(p.Power())
+}
+
+// Read fills p with whole samples of noise, it never fails.
+func (g *Generator) Read(p []byte) (int, error) {
+	n := 0
+	for ; n+g.sampleSize <= len(p); n += g.sampleSize {
+		g.encoder(g.next(), p[n:n+g.sampleSize])
+	}
+	return n, nil
+}
+
+func (g *Generator) Frame(duration time.Duration) []byte {
+	frame := make([]byte, g.codec.Size(duration))
+	_, _ = g.Read(frame)
+	return frame
+}
+
+func (g *Generator) Codec() *audiocodec.Codec {
+	return g.codec
+}
+
+// setPower derives the excitation gain: the prediction error power of the filter is the output power
+// multiplied by the product of (1 - k²) of its reflection coefficients
+func (g *Generator) setPower(power float64) {
+	for _, k := range g.coefficients {
+		power *= 1 - k*k
+	}
+	g.gain = math.Sqrt(power)
+}
+
+func (g *Generator) next() float32 {
+	v := g.random.NormFloat64() * g.gain
+	for i, a := range g.predictor {
+		v -= a * g.history[i]
+	}
+	if len(g.history) > 0 {
+		copy(g.history[1:], g.history)
+		g.history[0] = v
+	}
+	return float32(v)
+}
+
+func clampCoefficient(k float64) float64 {
+	return math.Max(-maxCoefficient, math.Min(maxCoefficient, k))
+}
+
+// stepUp extends the predictor of order m to order m+1 with the reflection coefficient k
+func stepUp(a []float64, k float64) []float64 {
+	m := len(a)
+	next := make([]float64, m+1)
+	for i := 0; i < m; i++ {
+		next[i] = a[i] + k*a[m-1-i]
+	}
+	next[m] = k
+	return append(a[:0], next...)
+}
@@ -0,0 +1,87 @@
+package cn
+
+import "math"
+
+const (
+	MaxLevel = 127
+	// maxCoefficient keeps the synthesis filter stable when a reflection coefficient is quantized to ±1
+	maxCoefficient = 0.995
+)
+
+// Payload is an RFC 3389 comfort noise payload: the noise level followed by the reflection coefficients
+// describing its spectral envelope.
+//
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|0|   level     |      N1       |      N2       |      ...      |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//
+// Level is the magnitude of the noise in -dBov, Coefficients are in range (-1, 1).
+type Payload struct {
+	Level        uint8
+	Coefficients []float64
+}
+
+func ParsePayload(b []byte) (Payload, error) {
+	if len(b) == 0 {
+		return Payload{}, EmptyPayload
+	}
+
+	p := Payload{
+		Level: b[0] & 0x7F,
+	}
+	if len(b) > 1 {
+		p.Coefficients = make([]float64, len(b)-1)
+		for i, q := range b[1:] {
+			p.Coefficients[i] = dequantize(q)
+		}
+	}
+
+	return p, nil
+}
+
+func (p Payload) Bytes() []byte {
+	b := make([]byte, 1+len(p.Coefficients))
+	b[0] = p.Level & 0x7F
+	for i, k := range p.Coefficients {
+		b[i+1] = quantize(k)
+	}
+	return b
+}
+
+// Power returns the mean square of the noise relative to the full scale.
+func (p Payload) Power() float64 {
+	return math.Pow(10, -float64(p.Level)/10)
+}
+
+// LevelByPower converts the mean square of a signal relative to the full scale into the -dBov level.
+func LevelByPower(power float64) uint8 {
+	if power <= 0 {
+		return MaxLevel
+	}
+
+	level := math.Round(-10 * math.Log10(power))
+	if level < 0 {
+		return 0
+	}
+	if level > MaxLevel {
+		return MaxLevel
+	}
+	return uint8(level)
+}
+
+// quantize maps a reflection coefficient linearly onto 0-254 with the step of 1/128
+func quantize(k float64) byte {
+	q := math.Round(k*128) + 127
+	if q < 0 {
+		return 0
+	}
+	if q > 254 {
+		return 254
+	}
+	return byte(q)
+}
+
+func dequantize(q byte) float64 {
+	return float64(int(q)-127) / 128
+}
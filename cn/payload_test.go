@@ -0,0 +1,61 @@
+package cn
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPayload(t *testing.T) {
+	p := Payload{Level: 0xC5, Coefficients: []float64{0, -1, 1, 0.5, -0.25, -2}}
+	// the first bit is reserved, coefficients are quantized with the step of 1/128 around 127
+	want := []byte{0x45, 127, 0, 254, 191, 95, 0}
+	b := p.Bytes()
+	if !bytes.Equal(b, want) {
+		t.Errorf("payload = % x, want % x", b, want)
+	}
+
+	parsed, err := ParsePayload(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coefficients := []float64{0, -127.0 / 128, 127.0 / 128, 0.5, -0.25, -127.0 / 128}
+	if parsed.Level != 0x45 || len(parsed.Coefficients) != len(coefficients) {
+		t.Fatalf("parsed %+v", parsed)
+	}
+	for i, k := range coefficients {
+		if parsed.Coefficients[i] != k {
+			t.Errorf("coefficient %d = %v, want %v", i, parsed.Coefficients[i], k)
+		}
+	}
+
+	// a payload of the level only describes white noise
+	if parsed, err = ParsePayload([]byte{70}); err != nil || parsed.Level != 70 || parsed.Coefficients != nil {
+		t.Errorf("parsed %+v, %v", parsed, err)
+	}
+	if _, err = ParsePayload(nil); !errors.Is(err, EmptyPayload) {
+		t.Errorf("empty payload: %v, want %v", err, EmptyPayload)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		power float64
+		level uint8
+	}{
+		{1, 0},
+		{2, 0},
+		{0.001, 30},
+		{1e-7, 70},
+		{1e-20, MaxLevel},
+		{0, MaxLevel},
+	}
+	for _, test := range tests {
+		if level := LevelByPower(test.power); level != test.level {
+			t.Errorf("LevelByPower(%v) = %d, want %d", test.power, level, test.level)
+		}
+	}
+	if power := (Payload{Level: 30}).Power(); power < 0.000999 || power > 0.001001 {
+		t.Errorf("power of 30 -dBov = %v", power)
+	}
+}
@@ -42,10 +42,65 @@ var PcmU8kHz8bCodec = &Codec{
 	BitRate:    8,
 }
 
+var Opus48kHzCodec = &Codec{
+	Name:       Opus,
+	SampleRate: 48_000,
+	BitRate:    16,
+}
+
+// G722Codec is 16 kHz audio coded at 64 kbit/s
+var G722Codec = &Codec{
+	Name:       G722,
+	SampleRate: 16_000,
+	BitRate:    16,
+}
+
+// G726Codec is G.726 of any bit rate, the number of bits per code is set on its encoder, decoder and WAV
+var G726Codec = &Codec{
+	Name:       G726,
+	SampleRate: 8_000,
+	BitRate:    16,
+}
+
+// GsmCodec is GSM 06.10 full rate (13 kbit/s)
+var GsmCodec = &Codec{
+	Name:       Gsm,
+	SampleRate: 8_000,
+	BitRate:    16,
+}
+
+// AmrCodec and AmrWbCodec are AMR narrowband and wideband
+var AmrCodec = &Codec{
+	Name:       Amr,
+	SampleRate: 8_000,
+	BitRate:    16,
+}
+
+var AmrWbCodec = &Codec{
+	Name:       AmrWb,
+	SampleRate: 16_000,
+	BitRate:    16,
+}
+
+// G729Codec is G.729 (Annex A/B) at 8 kbit/s
+var G729Codec = &Codec{
+	Name:       G729,
+	SampleRate: 8_000,
+	BitRate:    16,
+}
+
+// Codec2Codec is Codec 2 of any mode
+var Codec2Codec = &Codec{
+	Name:       Codec2,
+	SampleRate: 8_000,
+	BitRate:    16,
+}
+
 type Codec struct {
 	Name       Name `json:"name"`
 	SampleRate int  `json:"sampleRate"`
-	BitRate    int  `json:"bitRate"`
+	// BitRate is the number of bits of a PCM sample, for compressed codecs it is the sample size of the decoded PCM
+	BitRate int `json:"bitRate"`
 }
 
 func NewCodec(name Name, sampleRate int, bitRate int) *Codec {
@@ -60,12 +115,22 @@ func NewPcmCodec(sampleRate int, bitRate int) *Codec {
 	return NewCodec(Pcm, sampleRate, bitRate)
 }
 
+// ClockRate returns the RTP timestamp clock rate of the codec.
+// G.722 keeps the 8000 Hz clock rate of RFC 1890 despite sampling at 16 kHz (RFC 3551 section 4.5.2).
+func (c *Codec) ClockRate() int {
+	if c.Name == G722 {
+		return c.SampleRate / 2
+	}
+	return c.SampleRate
+}
+
+// SampleSize returns the size of a sample in bytes, for compressed codecs it is the size of a decoded sample.
 func (c *Codec) SampleSize() int {
 	return c.BitRate / 8
 }
 
 func (c *Codec) Size(duration time.Duration) int {
-	return c.SampleCountByDuration(duration) * c.SampleSize()
+	return c.SizeBySampleCount(c.SampleCountByDuration(duration))
 }
 
 func (c *Codec) SizeBySampleCount(sampleCount int) int {
@@ -102,6 +167,22 @@ const (
 	Pcm  Name = "PCM"
 	PcmA Name = "PCMA"
 	PcmU Name = "PCMU"
+	Opus Name = "OPUS"
+	Mp3  Name = "MP3"
+	G722 Name = "G722"
+	G726 Name = "G726"
+	// ImaAdpcm and MsAdpcm are the block based ADPCM formats of WAV files
+	ImaAdpcm Name = "IMA_ADPCM"
+	MsAdpcm  Name = "MS_ADPCM"
+	Gsm      Name = "GSM"
+	Amr      Name = "AMR"
+	AmrWb    Name = "AMR_WB"
+	G729     Name = "G729"
+	Codec2   Name = "CODEC2"
+	Speex    Name = "SPEEX"
+	Vorbis   Name = "VORBIS"
+	// PcmFloat is IEEE 754 floating point PCM with BitRate of 32 or 64
+	PcmFloat Name = "PCM_FLOAT"
 )
 
 func MustParseName(s string) Name {
@@ -112,6 +193,34 @@ func MustParseName(s string) Name {
 		return PcmA
 	case "PCMU":
 		return PcmU
+	case "OPUS":
+		return Opus
+	case "MP3":
+		return Mp3
+	case "G722":
+		return G722
+	case "G726":
+		return G726
+	case "IMA_ADPCM":
+		return ImaAdpcm
+	case "MS_ADPCM":
+		return MsAdpcm
+	case "GSM":
+		return Gsm
+	case "AMR":
+		return Amr
+	case "AMR_WB":
+		return AmrWb
+	case "G729":
+		return G729
+	case "CODEC2":
+		return Codec2
+	case "SPEEX":
+		return Speex
+	case "VORBIS":
+		return Vorbis
+	case "PCM_FLOAT":
+		return PcmFloat
 	}
 
 	panic(fmt.Errorf("constant \"%s\" does not exist", s))
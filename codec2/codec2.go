@@ -0,0 +1,172 @@
+package codec2
+
+/*
+#cgo pkg-config: codec2
+
+#include <codec2/codec2.h>
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+type Mode C.int
+
+// The modes are named by the bit rate in bit/s.
+const (
+	Mode3200 Mode = C.CODEC2_MODE_3200
+	Mode2400 Mode = C.CODEC2_MODE_2400
+	Mode1600 Mode = C.CODEC2_MODE_1600
+	Mode1400 Mode = C.CODEC2_MODE_1400
+	Mode1300 Mode = C.CODEC2_MODE_1300
+	Mode1200 Mode = C.CODEC2_MODE_1200
+	Mode700C Mode = C.CODEC2_MODE_700C
+)
+
+var InvalidFrameSize = errors.New("invalid Codec 2 frame size")
+
+// codec2 wraps the library state shared by the encoder and the decoder
+type codec2 struct {
+	state     *C.struct_CODEC2
+	frameSize int // samples per frame
+	bytes     int // bytes per frame
+	samples   []C.short
+	frame     []byte
+}
+
+func newCodec2(mode Mode) (*codec2, error) {
+	state := C.codec2_create(C.int(mode))
+	if state == nil {
+		return nil, fmt.Errorf("not supported Codec 2 mode: %d", int(mode))
+	}
+	c := &codec2{
+		state:     state,
+		frameSize: int(C.codec2_samples_per_frame(state)),
+		bytes:     int(C.codec2_bytes_per_frame(state)),
+	}
+	c.samples = make([]C.short, c.frameSize)
+	c.frame = make([]byte, c.bytes)
+	return c, nil
+}
+
+func (c *codec2) free() {
+	if c.state != nil {
+		C.codec2_destroy(c.state)
+		c.state = nil
+	}
+}
+
+// Encoder compresses 8 kHz 16-bit PCM into Codec 2 frames.
+type Encoder struct {
+	*codec2
+	buffer []byte
+}
+
+func NewEncoder(mode Mode) (*Encoder, error) {
+	c, err := newCodec2(mode)
+	if err != nil {
+		return nil, err
+	}
+	return &Encoder{codec2: c}, nil
+}
+
+// Encode buffers PCM data and returns the frames of all completed blocks.
+func (e *Encoder) Encode(data []byte) [][]byte {
+	e.buffer = append(e.buffer, data...)
+
+	frameBytes := e.PcmCodec().SizeBySampleCount(e.frameSize)
+	var frames [][]byte
+	for len(e.buffer) >= frameBytes {
+		frames = append(frames, e.encode(e.buffer[:frameBytes]))
+		e.buffer = e.buffer[frameBytes:]
+	}
+	e.buffer = append(e.buffer[:0:0], e.buffer...)
+
+	return frames
+}
+
+// Flush encodes the buffered incomplete block padded with silence.
+func (e *Encoder) Flush() []byte {
+	if len(e.buffer) == 0 {
+		return nil
+	}
+
+	block := make([]byte, e.PcmCodec().SizeBySampleCount(e.frameSize))
+	copy(block, e.buffer)
+	e.buffer = e.buffer[:0]
+
+	return e.encode(block)
+}
+
+func (e *Encoder) encode(block []byte) []byte {
+	for i := range e.samples {
+		e.samples[i] = C.short(int16(block[2*i]) | int16(block[2*i+1])<<8)
+	}
+
+	C.codec2_encode(e.state, (*C.uchar)(unsafe.Pointer(&e.frame[0])), &e.samples[0])
+
+	frame := make([]byte, e.bytes)
+	copy(frame, e.frame)
+	return frame
+}
+
+func (e *Encoder) Free() {
+	e.free()
+}
+
+// Decoder decompresses Codec 2 frames into 8 kHz 16-bit PCM.
+type Decoder struct {
+	*codec2
+}
+
+func NewDecoder(mode Mode) (*Decoder, error) {
+	c, err := newCodec2(mode)
+	if err != nil {
+		return nil, err
+	}
+	return &Decoder{codec2: c}, nil
+}
+
+// Decode returns PCM data of one or more frames, the size of data must be a multiple of FrameSize.
+func (d *Decoder) Decode(data []byte) ([]byte, error) {
+	if len(data)%d.bytes != 0 {
+		return nil, fmt.Errorf("%w: %d", InvalidFrameSize, len(data))
+	}
+
+	pcm := make([]byte, 0, len(data)/d.bytes*2*d.frameSize)
+	for ; len(data) > 0; data = data[d.bytes:] {
+		copy(d.frame, data)
+		C.codec2_decode(d.state, &d.samples[0], (*C.uchar)(unsafe.Pointer(&d.frame[0])))
+		for _, sample := range d.samples {
+			pcm = append(pcm, byte(sample), byte(uint16(sample)>>8))
+		}
+	}
+	return pcm, nil
+}
+
+func (d *Decoder) Free() {
+	d.free()
+}
+
+// FrameSize returns the size of a frame in bytes.
+func (c *codec2) FrameSize() int {
+	return c.bytes
+}
+
+// FrameDuration returns the duration of audio in every frame.
+func (c *codec2) FrameDuration() time.Duration {
+	return time.Duration(c.frameSize) * time.Second / time.Duration(c.PcmCodec().SampleRate)
+}
+
+func (c *codec2) Codec() *audiocodec.Codec {
+	return audiocodec.Codec2Codec
+}
+
+func (c *codec2) PcmCodec() *audiocodec.Codec {
+	return audiocodec.Pcm8kHz16bCodec
+}
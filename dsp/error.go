@@ -0,0 +1,5 @@
+package dsp
+
+import "errors"
+
+var NotSupportedChannels = errors.New("only mono and stereo are supported")
@@ -0,0 +1,92 @@
+package dsp
+
+import (
+	"math"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/pcm"
+)
+
+// Silence is the level reported for digital silence, in dB
+var Silence = math.Inf(-1)
+
+func DbToGain(db float64) float64 {
+	return math.Pow(10, db/20)
+}
+
+func GainToDb(gain float64) float64 {
+	if gain <= 0 {
+		return Silence
+	}
+	return 20 * math.Log10(gain)
+}
+
+// ApplyGain amplifies the frame in place by the gain in dB. Samples exceeding the full scale are clipped
+// instead of wrapping around, the number of clipped samples is returned.
+func ApplyGain(codec *audiocodec.Codec, frame []byte, db float64) (int, error) {
+	p, err := newProcessor(codec)
+	if err != nil {
+		return 0, err
+	}
+	if err = p.check(frame); err != nil {
+		return 0, err
+	}
+
+	return p.amplify(frame, DbToGain(db)), nil
+}
+
+// MaxGain returns the largest gain in dB that can be applied to the frame without clipping.
+func MaxGain(codec *audiocodec.Codec, frame []byte) (float64, error) {
+	level, err := Measure(codec, frame)
+	if err != nil {
+		return 0, err
+	}
+	return -level.PeakDbfs(), nil
+}
+
+// processor converts samples of a codec from and to floats
+type processor struct {
+	decoder    func(sample []byte) float32
+	encoder    func(sample float32, buf []byte)
+	sampleSize int
+}
+
+func newProcessor(codec *audiocodec.Codec) (*processor, error) {
+	decoder, err := pcm.Decoder(codec)
+	if err != nil {
+		return nil, err
+	}
+	encoder, err := pcm.Encoder(codec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &processor{
+		decoder:    decoder,
+		encoder:    encoder,
+		sampleSize: codec.SampleSize(),
+	}, nil
+}
+
+func (p *processor) check(frame []byte) error {
+	if len(frame)%p.sampleSize != 0 {
+		return pcm.InvalidFrameSize
+	}
+	return nil
+}
+
+func (p *processor) amplify(frame []byte, gain float64) (clipped int) {
+	if gain == 1 {
+		return 0
+	}
+
+	for i := 0; i < len(frame); i += p.sampleSize {
+		sample := frame[i : i+p.sampleSize]
+		v := float64(p.decoder(sample)) * gain
+		if v > 1 || v < -1 {
+			clipped++
+		}
+		p.encoder(float32(v), sample)
+	}
+	return clipped
+}
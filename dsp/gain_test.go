@@ -0,0 +1,62 @@
+package dsp
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/pcm"
+)
+
+func TestGainDb(t *testing.T) {
+	for _, db := range []float64{-60, -6, 0, 3, 20} {
+		if got := GainToDb(DbToGain(db)); math.Abs(got-db) > 1e-9 {
+			t.Errorf("GainToDb(DbToGain(%v)) = %v", db, got)
+		}
+	}
+	if !math.IsInf(GainToDb(0), -1) {
+		t.Errorf("GainToDb(0) = %v, want %v", GainToDb(0), Silence)
+	}
+}
+
+func TestApplyGain(t *testing.T) {
+	codec := audiocodec.Pcm8kHz16bCodec
+	frame := sine(t, codec, 1000, -12, 100*time.Millisecond)
+	maxGain, err := MaxGain(codec, frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(maxGain-12) > 0.01 {
+		t.Errorf("MaxGain = %.3f, want 12", maxGain)
+	}
+
+	amplified := bytes.Clone(frame)
+	clipped, err := ApplyGain(codec, amplified, 6)
+	if err != nil || clipped != 0 {
+		t.Fatalf("ApplyGain = %d, %v", clipped, err)
+	}
+	if level, _ := Measure(codec, amplified); math.Abs(level.PeakDbfs()+6) > 0.01 {
+		t.Errorf("peak = %.3f dBFS, want -6", level.PeakDbfs())
+	}
+
+	// samples above the full scale are clipped instead of wrapping around
+	clipped, err = ApplyGain(codec, amplified, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clipped == 0 {
+		t.Error("nothing is clipped")
+	}
+	if level, _ := Measure(codec, amplified); level.Peak < 0.999 {
+		t.Errorf("peak = %v after clipping", level.Peak)
+	}
+	original, _ := pcm.Decode(codec, nil, frame)
+	samples, _ := pcm.Decode(codec, nil, amplified)
+	for i, s := range samples {
+		if s*original[i] < 0 {
+			t.Fatalf("sample %d changed its sign", i)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package dsp
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+const (
+	// loudnessStep is the hop of the gating blocks (75% overlap of 400 ms blocks)
+	loudnessStep        = 100 * time.Millisecond
+	momentaryStepCount  = 4
+	shortTermStepCount  = 30
+	absoluteGate        = -70.0
+	relativeGate        = -10.0
+	loudnessCalibration = -0.691
+
+	// gating blocks are kept in a histogram of 0.1 LU bins from the absolute gate up to +30 LUFS like libebur128 does,
+	// so memory stays bounded and the integrated loudness is computed in O(bins)
+	histogramStep = 0.1
+	histogramBins = 1000
+)
+
+// histogramBin holds the gating blocks whose loudness falls into the bin
+type histogramBin struct {
+	count int
+	power float64 // sum of the mean squares of the blocks
+}
+
+// biquad is a second order IIR filter in direct form I
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x1, f.x2 = x, f.x1
+	f.y1, f.y2 = y, f.y1
+	return y
+}
+
+func (f *biquad) reset() {
+	f.x1, f.x2, f.y1, f.y2 = 0, 0, 0, 0
+}
+
+// kWeighting returns the pre-filter (high shelf) and the RLB filter (high pass) of ITU-R BS.1770
+// recalculated for the sample rate
+func kWeighting(sampleRate int) (biquad, biquad) {
+	fs := float64(sampleRate)
+
+	f0 := 1681.974450955533
+	g := 3.999843853973347
+	q := 0.7071752369554196
+	k := math.Tan(math.Pi * f0 / fs)
+	vh := math.Pow(10, g/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	f0 = 38.13547087602444
+	q = 0.5003270373238773
+	k = math.Tan(math.Pi * f0 / fs)
+	a0 = 1 + k/q + k*k
+	highPass := biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	return shelf, highPass
+}
+
+// kFilter is the K-weighting filter of a single channel
+type kFilter struct {
+	shelf    biquad
+	highPass biquad
+}
+
+func (f *kFilter) process(x float64) float64 {
+	return f.highPass.process(f.shelf.process(x))
+}
+
+// LoudnessMeter measures the loudness of a mono or stereo stream according to EBU R128 (ITU-R BS.1770).
+// Channels are K-weighted separately and their powers are summed. All values are in LUFS, -Inf is returned
+// while there is not enough audio.
+type LoudnessMeter struct {
+	codec   *audiocodec.Codec
+	p       *processor
+	filters []kFilter
+	channel int // channel of the next sample
+
+	stepSize  int // samples per channel in a 100 ms step
+	stepSum   float64
+	stepCount int                         // samples per channel accumulated in the current step
+	steps     []float64                   // mean squares of the completed steps
+	histogram [histogramBins]histogramBin // 400 ms gating blocks above the absolute gate
+}
+
+func NewLoudnessMeter(codec *audiocodec.Codec) (*LoudnessMeter, error) {
+	return NewLoudnessMeterWithChannels(codec, 1)
+}
+
+// NewLoudnessMeterWithChannels creates a meter of interleaved stereo or mono data.
+func NewLoudnessMeterWithChannels(codec *audiocodec.Codec, channels int) (*LoudnessMeter, error) {
+	if channels != 1 && channels != 2 {
+		return nil, fmt.Errorf("%w: %d", NotSupportedChannels, channels)
+	}
+	p, err := newProcessor(codec)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &LoudnessMeter{
+		codec:    codec,
+		p:        p,
+		filters:  make([]kFilter, channels),
+		stepSize: codec.SampleCountByDuration(loudnessStep),
+	}
+	for i := range m.filters {
+		m.filters[i].shelf, m.filters[i].highPass = kWeighting(codec.SampleRate)
+	}
+
+	return m, nil
+}
+
+// Process measures the frame, a frame may end in the middle of the samples of a stereo pair.
+func (m *LoudnessMeter) Process(frame []byte) error {
+	if err := m.p.check(frame); err != nil {
+		return err
+	}
+
+	for i := 0; i < len(frame); i += m.p.sampleSize {
+		v := m.filters[m.channel].process(float64(m.p.decoder(frame[i : i+m.p.sampleSize])))
+		m.stepSum += v * v
+		if m.channel++; m.channel < len(m.filters) {
+			continue
+		}
+		m.channel = 0
+		m.stepCount++
+		if m.stepCount == m.stepSize {
+			m.completeStep()
+		}
+	}
+
+	return nil
+}
+
+// Integrated returns the gated loudness of everything processed so far.
+// Blocks within 0.1 LU below the relative gate may still be counted.
+func (m *LoudnessMeter) Integrated() float64 {
+	var sum float64
+	var count int
+	for _, bin := range m.histogram {
+		sum += bin.power
+		count += bin.count
+	}
+	if count == 0 {
+		return Silence
+	}
+
+	relativeThreshold := sum / float64(count) * math.Pow(10, relativeGate/10)
+	sum, count = 0, 0
+	for _, bin := range m.histogram[histogramIndex(powerToLoudness(relativeThreshold)):] {
+		sum += bin.power
+		count += bin.count
+	}
+	if count == 0 {
+		return Silence
+	}
+
+	return powerToLoudness(sum / float64(count))
+}
+
+// Momentary returns the loudness of the last 400 ms.
+func (m *LoudnessMeter) Momentary() float64 {
+	return m.window(momentaryStepCount)
+}
+
+// ShortTerm returns the loudness of the last 3 seconds.
+func (m *LoudnessMeter) ShortTerm() float64 {
+	return m.window(shortTermStepCount)
+}
+
+func (m *LoudnessMeter) Reset() {
+	for i := range m.filters {
+		m.filters[i].shelf.reset()
+		m.filters[i].highPass.reset()
+	}
+	m.channel = 0
+	m.stepSum = 0
+	m.stepCount = 0
+	m.steps = m.steps[:0]
+	m.histogram = [histogramBins]histogramBin{}
+}
+
+func (m *LoudnessMeter) Codec() *audiocodec.Codec {
+	return m.codec
+}
+
+func (m *LoudnessMeter) completeStep() {
+	m.steps = append(m.steps, m.stepSum/float64(m.stepCount))
+	m.stepSum = 0
+	m.stepCount = 0
+
+	if len(m.steps) > shortTermStepCount {
+		copy(m.steps, m.steps[1:])
+		m.steps = m.steps[:shortTermStepCount]
+	}
+	if len(m.steps) >= momentaryStepCount {
+		block := mean(m.steps[len(m.steps)-momentaryStepCount:])
+		if block > loudnessToPower(absoluteGate) {
+			bin := &m.histogram[histogramIndex(powerToLoudness(block))]
+			bin.count++
+			bin.power += block
+		}
+	}
+}
+
+func (m *LoudnessMeter) window(stepCount int) float64 {
+	if len(m.steps) < stepCount {
+		return Silence
+	}
+	return powerToLoudness(mean(m.steps[len(m.steps)-stepCount:]))
+}
+
+// histogramIndex returns the bin of the loudness, values out of the histogram fall into the first or the last bin
+func histogramIndex(loudness float64) int {
+	i := int(math.Floor((loudness - absoluteGate) / histogramStep))
+	return max(0, min(i, histogramBins-1))
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func powerToLoudness(power float64) float64 {
+	if power <= 0 {
+		return Silence
+	}
+	return loudnessCalibration + 10*math.Log10(power)
+}
+
+func loudnessToPower(loudness float64) float64 {
+	return math.Pow(10, (loudness-loudnessCalibration)/10)
+}
@@ -0,0 +1,201 @@
+package dsp
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/pcm"
+)
+
+var pcmFloat48kHzCodec = &audiocodec.Codec{Name: audiocodec.PcmFloat, SampleRate: 48_000, BitRate: 32}
+
+// sine returns a sine wave of the frequency whose peak is at the level in dBFS
+func sine(t *testing.T, codec *audiocodec.Codec, frequency float64, level float64, duration time.Duration) []byte {
+	t.Helper()
+	samples := make([]float32, codec.SampleCountByDuration(duration))
+	amplitude := DbToGain(level)
+	for i := range samples {
+		samples[i] = float32(amplitude * math.Sin(2*math.Pi*frequency*float64(i)/float64(codec.SampleRate)))
+	}
+	frame, err := pcm.Encode(codec, nil, samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return frame
+}
+
+func TestKWeighting(t *testing.T) {
+	// ITU-R BS.1770-4 table 1 and table 2, coefficients at 48 kHz
+	shelf, highPass := kWeighting(48_000)
+	tests := []struct {
+		name string
+		got  []float64
+		want []float64
+	}{
+		{
+			"pre-filter",
+			[]float64{shelf.b0, shelf.b1, shelf.b2, shelf.a1, shelf.a2},
+			[]float64{1.53512485958697, -2.69169618940638, 1.19839281085285, -1.69065929318241, 0.73248077421585},
+		},
+		{
+			"RLB filter",
+			[]float64{highPass.b0, highPass.b1, highPass.b2, highPass.a1, highPass.a2},
+			[]float64{1, -2, 1, -1.99004745483398, 0.99007225036621},
+		},
+	}
+	for _, tt := range tests {
+		for i := range tt.want {
+			if math.Abs(tt.got[i]-tt.want[i]) > 1e-9 {
+				t.Errorf("%s coefficient %d = %.14f, want %.14f", tt.name, i, tt.got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestLoudnessMeter(t *testing.T) {
+	// EBU Tech 3341 test cases 1 and 2 give -23 LUFS for a stereo 1 kHz sine at -23 dBFS and -33 LUFS at -33 dBFS,
+	// a single channel is 3.01 dB quieter
+	for _, codec := range []*audiocodec.Codec{pcmFloat48kHzCodec, audiocodec.Pcm16kHz16bCodec} {
+		for _, level := range []float64{-23, -33} {
+			m, err := NewLoudnessMeter(codec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = m.Process(sine(t, codec, 1000, level, 20*time.Second)); err != nil {
+				t.Fatal(err)
+			}
+
+			want := level - 10*math.Log10(2)
+			for name, loudness := range map[string]float64{"integrated": m.Integrated(), "momentary": m.Momentary(), "short-term": m.ShortTerm()} {
+				if math.Abs(loudness-want) > 0.1 {
+					t.Errorf("%d Hz, %v dBFS: %s loudness = %.2f LUFS, want %.2f", codec.SampleRate, level, name, loudness, want)
+				}
+			}
+		}
+	}
+}
+
+func TestLoudnessGating(t *testing.T) {
+	// EBU Tech 3341 test case 4: -72, -36, -23, -36, -72 dBFS, only the -23 dBFS part passes the gates
+	m, err := NewLoudnessMeter(pcmFloat48kHzCodec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, part := range []struct {
+		level    float64
+		duration time.Duration
+	}{{-72, 10 * time.Second}, {-36, 10 * time.Second}, {-23, 60 * time.Second}, {-36, 10 * time.Second}, {-72, 10 * time.Second}} {
+		// 10 s frames hold whole periods of the sine
+		for elapsed := time.Duration(0); elapsed < part.duration; elapsed += 10 * time.Second {
+			if err = m.Process(sine(t, pcmFloat48kHzCodec, 1000, part.level, 10*time.Second)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if loudness, want := m.Integrated(), -23-10*math.Log10(2); math.Abs(loudness-want) > 0.1 {
+		t.Errorf("integrated loudness = %.2f LUFS, want %.2f", loudness, want)
+	}
+
+	// the last 3 seconds are below the absolute gate, but short-term loudness is not gated
+	if loudness := m.ShortTerm(); loudness > -70 || math.IsInf(loudness, -1) {
+		t.Errorf("short-term loudness = %.2f LUFS", loudness)
+	}
+
+	m.Reset()
+	if err = m.Process(make([]byte, pcmFloat48kHzCodec.Size(time.Second))); err != nil {
+		t.Fatal(err)
+	}
+	if loudness := m.Integrated(); !math.IsInf(loudness, -1) {
+		t.Errorf("integrated loudness of silence = %v", loudness)
+	}
+	if loudness := m.Momentary(); !math.IsInf(loudness, -1) {
+		t.Errorf("momentary loudness of silence = %v", loudness)
+	}
+}
+
+func TestLoudnessHistogram(t *testing.T) {
+	// EBU Tech 3341 test case 5: -26, -20, -26 dBFS, gating blocks of both levels are counted
+	m, err := NewLoudnessMeter(pcmFloat48kHzCodec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, part := range []struct {
+		level    float64
+		duration time.Duration
+	}{{-26, 20 * time.Second}, {-20, 20100 * time.Millisecond}, {-26, 20 * time.Second}} {
+		if err = m.Process(sine(t, pcmFloat48kHzCodec, 1000, part.level, part.duration)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if loudness, want := m.Integrated(), -23-10*math.Log10(2); math.Abs(loudness-want) > 0.1 {
+		t.Errorf("integrated loudness = %.2f LUFS, want %.2f", loudness, want)
+	}
+
+	// the histogram is cleared on reset
+	m.Reset()
+	if loudness := m.Integrated(); !math.IsInf(loudness, -1) {
+		t.Errorf("integrated loudness after reset = %v", loudness)
+	}
+}
+
+// interleave joins two mono frames of the codec into a stereo frame
+func interleave(codec *audiocodec.Codec, left []byte, right []byte) []byte {
+	size := codec.SampleSize()
+	stereo := make([]byte, 0, len(left)+len(right))
+	for i := 0; i < len(left); i += size {
+		stereo = append(stereo, left[i:i+size]...)
+		stereo = append(stereo, right[i:i+size]...)
+	}
+	return stereo
+}
+
+func TestLoudnessMeterStereo(t *testing.T) {
+	codec := pcmFloat48kHzCodec
+	tests := []struct {
+		name        string
+		left, right []byte
+		want        float64
+	}{
+		// EBU Tech 3341 test case 1
+		{"both channels", sine(t, codec, 1000, -23, 20*time.Second), sine(t, codec, 1000, -23, 20*time.Second), -23},
+		{"left channel", sine(t, codec, 1000, -23, 20*time.Second), make([]byte, codec.Size(20*time.Second)), -23 - 10*math.Log10(2)},
+		// channels are filtered separately: a sine of 100 Hz does not mix with the sine of 1 kHz
+		{"different channels", sine(t, codec, 100, -23, 20*time.Second), sine(t, codec, 1000, -23, 20*time.Second), math.NaN()},
+	}
+	for _, tt := range tests {
+		if math.IsNaN(tt.want) {
+			// the powers of the channels measured as mono are summed
+			var power float64
+			for _, channel := range [][]byte{tt.left, tt.right} {
+				m, _ := NewLoudnessMeter(codec)
+				if err := m.Process(channel); err != nil {
+					t.Fatal(err)
+				}
+				power += loudnessToPower(m.Integrated())
+			}
+			tt.want = powerToLoudness(power)
+		}
+
+		m, err := NewLoudnessMeterWithChannels(codec, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// frames of an odd number of samples split stereo pairs
+		stereo := interleave(codec, tt.left, tt.right)
+		for frame := codec.SizeBySampleCount(333); len(stereo) > 0; stereo = stereo[min(frame, len(stereo)):] {
+			if err = m.Process(stereo[:min(frame, len(stereo))]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if loudness := m.Integrated(); math.Abs(loudness-tt.want) > 0.1 {
+			t.Errorf("%s: integrated loudness = %.2f LUFS, want %.2f", tt.name, loudness, tt.want)
+		}
+	}
+
+	if _, err := NewLoudnessMeterWithChannels(codec, 6); !errors.Is(err, NotSupportedChannels) {
+		t.Errorf("NewLoudnessMeterWithChannels = %v, want %v", err, NotSupportedChannels)
+	}
+}
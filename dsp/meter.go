@@ -0,0 +1,104 @@
+package dsp
+
+import (
+	"math"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+// Level is the peak and the RMS of a signal relative to the full scale.
+type Level struct {
+	Peak        float64
+	Rms         float64
+	SampleCount int
+}
+
+func (l Level) PeakDbfs() float64 {
+	return GainToDb(l.Peak)
+}
+
+func (l Level) RmsDbfs() float64 {
+	return GainToDb(l.Rms)
+}
+
+// Measure returns the level of a single frame.
+func Measure(codec *audiocodec.Codec, frame []byte) (Level, error) {
+	m, err := NewMeter(codec)
+	if err != nil {
+		return Level{}, err
+	}
+	return m.Process(frame)
+}
+
+// Meter measures peak and RMS levels of a stream frame by frame and keeps the totals.
+type Meter struct {
+	codec *audiocodec.Codec
+	p     *processor
+
+	peak        float64
+	sum         float64
+	sampleCount int
+}
+
+func NewMeter(codec *audiocodec.Codec) (*Meter, error) {
+	p, err := newProcessor(codec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Meter{
+		codec: codec,
+		p:     p,
+	}, nil
+}
+
+// Process returns the level of the frame and adds it to the totals.
+func (m *Meter) Process(frame []byte) (Level, error) {
+	if err := m.p.check(frame); err != nil {
+		return Level{}, err
+	}
+
+	var peak, sum float64
+	for i := 0; i < len(frame); i += m.p.sampleSize {
+		v := float64(m.p.decoder(frame[i : i+m.p.sampleSize]))
+		sum += v * v
+		if v = math.Abs(v); v > peak {
+			peak = v
+		}
+	}
+
+	sampleCount := len(frame) / m.p.sampleSize
+	m.sum += sum
+	m.sampleCount += sampleCount
+	if peak > m.peak {
+		m.peak = peak
+	}
+
+	return level(peak, sum, sampleCount), nil
+}
+
+// Total returns the level of everything processed since the creation or the last reset.
+func (m *Meter) Total() Level {
+	return level(m.peak, m.sum, m.sampleCount)
+}
+
+func (m *Meter) Reset() {
+	m.peak = 0
+	m.sum = 0
+	m.sampleCount = 0
+}
+
+func (m *Meter) Codec() *audiocodec.Codec {
+	return m.codec
+}
+
+func level(peak float64, sum float64, sampleCount int) Level {
+	l := Level{
+		Peak:        peak,
+		SampleCount: sampleCount,
+	}
+	if sampleCount > 0 {
+		l.Rms = math.Sqrt(sum / float64(sampleCount))
+	}
+	return l
+}
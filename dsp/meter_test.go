@@ -0,0 +1,60 @@
+package dsp
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/pcm"
+)
+
+func TestMeasure(t *testing.T) {
+	codec := audiocodec.Pcm16kHz16bCodec
+	level, err := Measure(codec, sine(t, codec, 1000, -6, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the RMS of a sine is 3.01 dB below its peak
+	if math.Abs(level.PeakDbfs()+6) > 0.01 || math.Abs(level.RmsDbfs()+6+10*math.Log10(2)) > 0.01 {
+		t.Errorf("peak %.3f dBFS, rms %.3f dBFS", level.PeakDbfs(), level.RmsDbfs())
+	}
+	if level.SampleCount != 16_000 {
+		t.Errorf("sample count = %d, want 16000", level.SampleCount)
+	}
+
+	if level, err = Measure(codec, make([]byte, 320)); err != nil || !math.IsInf(level.PeakDbfs(), -1) || !math.IsInf(level.RmsDbfs(), -1) {
+		t.Errorf("silence: %+v, %v", level, err)
+	}
+	if _, err = Measure(codec, make([]byte, 3)); !errors.Is(err, pcm.InvalidFrameSize) {
+		t.Errorf("Measure = %v, want %v", err, pcm.InvalidFrameSize)
+	}
+}
+
+func TestMeter(t *testing.T) {
+	codec := audiocodec.Pcm8kHz16bCodec
+	m, err := NewMeter(codec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loud := sine(t, codec, 400, -10, time.Second)
+	quiet := sine(t, codec, 400, -30, time.Second)
+	for _, frame := range [][]byte{quiet, loud} {
+		if _, err = m.Process(frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the power of the totals is the mean of the powers of both frames
+	total := m.Total()
+	want := 10 * math.Log10((math.Pow(10, -1)+math.Pow(10, -3))/4)
+	if math.Abs(total.PeakDbfs()+10) > 0.01 || math.Abs(total.RmsDbfs()-want) > 0.01 || total.SampleCount != 16_000 {
+		t.Errorf("total %+v, rms %.3f dBFS, want %.3f", total, total.RmsDbfs(), want)
+	}
+
+	m.Reset()
+	if total = m.Total(); total != (Level{}) {
+		t.Errorf("total after reset %+v", total)
+	}
+}
@@ -0,0 +1,165 @@
+package dsp
+
+import (
+	"math"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+// gainTimeConstant is how fast the streaming loudness normalizer follows the changes of the measured loudness
+const gainTimeConstant = time.Second
+
+// NormalizePeak amplifies the WAV data in place so that its peak reaches the target in dBFS.
+// All channels get the same gain, so their balance is kept. The applied gain in dB is returned,
+// silent data is left untouched.
+func NormalizePeak(wav *audiocodec.Wav, target float64) (float64, error) {
+	level, err := Measure(wav.Codec(), wav.Data())
+	if err != nil {
+		return 0, err
+	}
+	if level.Peak == 0 {
+		return 0, nil
+	}
+
+	gain := target - level.PeakDbfs()
+	if _, err = ApplyGain(wav.Codec(), wav.Data(), gain); err != nil {
+		return 0, err
+	}
+	return gain, nil
+}
+
+// NormalizeLoudness amplifies the mono or stereo WAV data in place so that its integrated loudness reaches the target
+// in LUFS (EBU R128 recommends -23 LUFS). The gain is reduced if the peak would exceed maxPeak dBFS.
+// The applied gain in dB is returned, data quieter than the absolute gate is left untouched.
+func NormalizeLoudness(wav *audiocodec.Wav, target float64, maxPeak float64) (float64, error) {
+	meter, err := NewLoudnessMeterWithChannels(wav.Codec(), wav.Channels())
+	if err != nil {
+		return 0, err
+	}
+	if err = meter.Process(wav.Data()); err != nil {
+		return 0, err
+	}
+	loudness := meter.Integrated()
+	if math.IsInf(loudness, -1) {
+		return 0, nil
+	}
+
+	level, err := Measure(wav.Codec(), wav.Data())
+	if err != nil {
+		return 0, err
+	}
+
+	gain := math.Min(target-loudness, maxPeak-level.PeakDbfs())
+	if _, err = ApplyGain(wav.Codec(), wav.Data(), gain); err != nil {
+		return 0, err
+	}
+	return gain, nil
+}
+
+// Normalizer adjusts the gain of a stream frame by frame, either keeping the running peak at the target
+// or following the integrated loudness measured so far.
+type Normalizer struct {
+	codec    *audiocodec.Codec
+	p        *processor
+	target   float64
+	maxGain  float64
+	loudness *LoudnessMeter
+
+	peak      float64
+	gain      float64 // currently applied linear gain
+	smoothing float64 // per sample weight of the gain change
+}
+
+// NewPeakNormalizer creates a normalizer amplifying the stream so that its peak reaches the target in dBFS,
+// the gain never exceeds maxGain dB. The gain is lowered before a louder frame is output, so nothing is clipped.
+func NewPeakNormalizer(codec *audiocodec.Codec, target float64, maxGain float64) (*Normalizer, error) {
+	p, err := newProcessor(codec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Normalizer{
+		codec:   codec,
+		p:       p,
+		target:  target,
+		maxGain: maxGain,
+		gain:    1,
+	}, nil
+}
+
+// NewLoudnessNormalizer creates a normalizer bringing the integrated loudness of the mono stream to the target
+// in LUFS, the gain stays within ±maxGain dB and is lowered instantly when a frame would clip.
+func NewLoudnessNormalizer(codec *audiocodec.Codec, target float64, maxGain float64) (*Normalizer, error) {
+	n, err := NewPeakNormalizer(codec, target, maxGain)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.loudness, err = NewLoudnessMeter(codec); err != nil {
+		return nil, err
+	}
+	n.smoothing = 1 - math.Exp(-1/(gainTimeConstant.Seconds()*float64(codec.SampleRate)))
+
+	return n, nil
+}
+
+// Process normalizes the frame in place.
+func (n *Normalizer) Process(frame []byte) error {
+	if err := n.p.check(frame); err != nil {
+		return err
+	}
+
+	var framePeak float64
+	for i := 0; i < len(frame); i += n.p.sampleSize {
+		if v := math.Abs(float64(n.p.decoder(frame[i : i+n.p.sampleSize]))); v > framePeak {
+			framePeak = v
+		}
+	}
+
+	if n.loudness == nil {
+		n.peak = math.Max(n.peak, framePeak)
+		n.gain = DbToGain(math.Min(n.maxGain, n.target-GainToDb(n.peak)))
+		n.p.amplify(frame, n.gain)
+		return nil
+	}
+
+	if err := n.loudness.Process(frame); err != nil {
+		return err
+	}
+	desired := n.gain
+	if loudness := n.loudness.Integrated(); !math.IsInf(loudness, -1) {
+		desired = DbToGain(math.Max(-n.maxGain, math.Min(n.maxGain, n.target-loudness)))
+	}
+	if framePeak > 0 && framePeak*desired > 1 {
+		desired = 1 / framePeak
+	}
+	if framePeak > 0 && framePeak*n.gain > 1 {
+		n.gain = desired
+	}
+
+	for i := 0; i < len(frame); i += n.p.sampleSize {
+		n.gain += (desired - n.gain) * n.smoothing
+		sample := frame[i : i+n.p.sampleSize]
+		n.p.encoder(float32(float64(n.p.decoder(sample))*n.gain), sample)
+	}
+
+	return nil
+}
+
+// Gain returns the currently applied gain in dB.
+func (n *Normalizer) Gain() float64 {
+	return GainToDb(n.gain)
+}
+
+func (n *Normalizer) Reset() {
+	n.peak = 0
+	n.gain = 1
+	if n.loudness != nil {
+		n.loudness.Reset()
+	}
+}
+
+func (n *Normalizer) Codec() *audiocodec.Codec {
+	return n.codec
+}
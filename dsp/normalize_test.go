@@ -0,0 +1,162 @@
+package dsp
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+func TestNormalizePeak(t *testing.T) {
+	codec := audiocodec.Pcm16kHz16bCodec
+	wav := audiocodec.NewWav(codec)
+	if _, err := wav.Write(sine(t, codec, 1000, -20, time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	gain, err := NormalizePeak(wav, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	level, _ := Measure(codec, wav.Data())
+	if math.Abs(gain-19) > 0.01 || math.Abs(level.PeakDbfs()+1) > 0.01 {
+		t.Errorf("gain %.3f dB, peak %.3f dBFS", gain, level.PeakDbfs())
+	}
+
+	silence := audiocodec.NewWav(codec)
+	if _, err = silence.Write(make([]byte, 320)); err != nil {
+		t.Fatal(err)
+	}
+	if gain, err = NormalizePeak(silence, -1); err != nil || gain != 0 {
+		t.Errorf("silence: gain %v, %v", gain, err)
+	}
+}
+
+func TestNormalizeLoudness(t *testing.T) {
+	codec := pcmFloat48kHzCodec
+	wav := audiocodec.NewWav(codec)
+	if _, err := wav.Write(sine(t, codec, 1000, -40, 5*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the mono sine at -40 dBFS measures -43 LUFS
+	gain, err := NormalizeLoudness(wav, -23, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, _ := NewLoudnessMeter(codec)
+	if err = m.Process(wav.Data()); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(gain-20) > 0.1 || math.Abs(m.Integrated()+23) > 0.1 {
+		t.Errorf("gain %.2f dB, loudness %.2f LUFS", gain, m.Integrated())
+	}
+
+	// the peak limit wins over the loudness target
+	if gain, err = NormalizeLoudness(wav, -10, -15); err != nil {
+		t.Fatal(err)
+	}
+	if level, _ := Measure(codec, wav.Data()); math.Abs(level.PeakDbfs()+15) > 0.01 {
+		t.Errorf("gain %.2f dB, peak %.3f dBFS", gain, level.PeakDbfs())
+	}
+}
+
+func TestPeakNormalizer(t *testing.T) {
+	codec := audiocodec.Pcm8kHz16bCodec
+	n, err := NewPeakNormalizer(codec, -3, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the gain is limited for quiet frames and lowered before a louder frame is output
+	quiet := sine(t, codec, 500, -40, 100*time.Millisecond)
+	if err = n.Process(quiet); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(n.Gain()-20) > 0.01 {
+		t.Errorf("gain = %.3f dB, want 20", n.Gain())
+	}
+	loud := sine(t, codec, 500, -6, 100*time.Millisecond)
+	if err = n.Process(loud); err != nil {
+		t.Fatal(err)
+	}
+	if level, _ := Measure(codec, loud); math.Abs(n.Gain()-3) > 0.01 || math.Abs(level.PeakDbfs()+3) > 0.01 {
+		t.Errorf("gain %.3f dB, peak %.3f dBFS", n.Gain(), level.PeakDbfs())
+	}
+
+	n.Reset()
+	if n.Gain() != 0 {
+		t.Errorf("gain after reset = %v", n.Gain())
+	}
+}
+
+func TestLoudnessNormalizer(t *testing.T) {
+	codec := pcmFloat48kHzCodec
+	n, err := NewLoudnessNormalizer(codec, -23, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output []byte
+	for i := 0; i < 20; i++ {
+		frame := sine(t, codec, 1000, -35, time.Second)
+		if err = n.Process(frame); err != nil {
+			t.Fatal(err)
+		}
+		output = frame
+	}
+
+	// the mono sine at -35 dBFS measures -38 LUFS, after settling the gain reaches 15 dB
+	if math.Abs(n.Gain()-15) > 0.1 {
+		t.Errorf("gain = %.2f dB, want 15", n.Gain())
+	}
+	m, _ := NewLoudnessMeter(codec)
+	if err = m.Process(output); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(m.Integrated()+23) > 0.1 {
+		t.Errorf("loudness of the last second = %.2f LUFS, want -23", m.Integrated())
+	}
+}
+
+func TestNormalizeStereo(t *testing.T) {
+	codec := pcmFloat48kHzCodec
+	left, right := sine(t, codec, 1000, -40, 5*time.Second), sine(t, codec, 100, -30, 5*time.Second)
+	wav := audiocodec.NewWavWithChannels(codec, 2)
+	if _, err := wav.Write(interleave(codec, left, right)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the balance of the channels is kept
+	gain, err := NormalizePeak(wav, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(gain-29) > 0.01 {
+		t.Errorf("peak gain = %.3f dB, want 29", gain)
+	}
+
+	if gain, err = NormalizeLoudness(wav, -23, 0); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewLoudnessMeterWithChannels(codec, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Process(wav.Data()); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(m.Integrated()+23) > 0.1 {
+		t.Errorf("gain %.2f dB, loudness %.2f LUFS, want -23", gain, m.Integrated())
+	}
+
+	surround := audiocodec.NewWavWithChannels(codec, 6)
+	if _, err = surround.Write(make([]byte, codec.SizeBySampleCount(6))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NormalizeLoudness(surround, -23, 0); !errors.Is(err, NotSupportedChannels) {
+		t.Errorf("NormalizeLoudness = %v, want %v", err, NotSupportedChannels)
+	}
+}
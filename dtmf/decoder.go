@@ -0,0 +1,106 @@
+package dtmf
+
+import (
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+// Decoder reassembles tones from a stream of RFC 4733 packets.
+// Retransmitted updates and redundant end packets are ignored, a tone whose end packets were lost
+// is completed as soon as the next event starts.
+type Decoder struct {
+	codec *audiocodec.Codec
+
+	active    bool
+	event     Event
+	volume    uint8
+	timestamp uint32
+	duration  uint16
+	elapsed   int64 // duration of the previous segments of a long event
+
+	ended          bool
+	endedTimestamp uint32
+}
+
+func NewDecoder(codec *audiocodec.Codec) *Decoder {
+	return &Decoder{
+		codec: codec,
+	}
+}
+
+// Decode consumes the payload of a single RTP packet and returns the tones completed by it.
+func (d *Decoder) Decode(timestamp uint32, payload []byte) ([]Tone, error) {
+	p, err := ParsePayload(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.ended && int32(timestamp-d.endedTimestamp) <= 0 {
+		return nil, nil
+	}
+
+	var tones []Tone
+	if d.active {
+		switch {
+		case timestamp == d.timestamp:
+			if p.Duration > d.duration {
+				d.duration = p.Duration
+			}
+			d.volume = p.Volume
+			if p.End {
+				tones = append(tones, d.finish())
+			}
+			return tones, nil
+		case p.Event == d.event && d.duration == MaxDuration && timestamp == d.timestamp+MaxDuration:
+			d.elapsed += MaxDuration
+			d.timestamp = timestamp
+			d.duration = p.Duration
+			d.volume = p.Volume
+			if p.End {
+				tones = append(tones, d.finish())
+			}
+			return tones, nil
+		default:
+			tones = append(tones, d.finish())
+		}
+	}
+
+	d.active = true
+	d.event = p.Event
+	d.volume = p.Volume
+	d.timestamp = timestamp
+	d.duration = p.Duration
+	d.elapsed = 0
+	if p.End {
+		tones = append(tones, d.finish())
+	}
+
+	return tones, nil
+}
+
+// Flush completes the tone in progress, if any, e.g. when the RTP stream stops.
+func (d *Decoder) Flush() []Tone {
+	if !d.active {
+		return nil
+	}
+	return []Tone{d.finish()}
+}
+
+func (d *Decoder) Reset() {
+	*d = Decoder{codec: d.codec}
+}
+
+func (d *Decoder) finish() Tone {
+	d.active = false
+	d.ended = true
+	d.endedTimestamp = d.timestamp
+
+	return Tone{
+		Event:    d.event,
+		Volume:   d.volume,
+		Duration: unitsToDuration(d.codec, d.elapsed+int64(d.duration)),
+	}
+}
+
+func (d *Decoder) Codec() *audiocodec.Codec {
+	return d.codec
+}
@@ -0,0 +1,113 @@
+package dtmf
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+func decode(t *testing.T, d *Decoder, packets []Packet) []Tone {
+	t.Helper()
+	var tones []Tone
+	for _, p := range packets {
+		decoded, err := d.Decode(p.Timestamp, p.Payload.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		tones = append(tones, decoded...)
+	}
+	return tones
+}
+
+func TestEncoderDecoder(t *testing.T) {
+	codec := audiocodec.Pcm8kHz16bCodec
+	tones, err := NewTones("159#*D", 120*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tones[2].Volume = 20
+	packets, err := NewDefaultEncoder(codec).EncodeSequence(tones, 80*time.Millisecond, 12345)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := decode(t, NewDecoder(codec), packets)
+	if len(decoded) != len(tones) {
+		t.Fatalf("decoded %q, want %q", Digits(decoded), Digits(tones))
+	}
+	for i, tone := range decoded {
+		if tone != tones[i] {
+			t.Errorf("tone %d = %+v, want %+v", i, tone, tones[i])
+		}
+	}
+}
+
+func TestDecoderLongEvent(t *testing.T) {
+	codec := audiocodec.Pcm8kHz16bCodec
+	tone := NewTone(Digit0, 10*time.Second)
+	packets, err := NewDefaultEncoder(codec).Encode(tone, 0xFFFFFF00)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := decode(t, NewDecoder(codec), packets)
+	if len(decoded) != 1 || decoded[0] != tone {
+		t.Errorf("decoded %+v, want %+v", decoded, tone)
+	}
+}
+
+func TestDecoderLostEndPackets(t *testing.T) {
+	codec := audiocodec.Pcm8kHz16bCodec
+	tones, err := NewTones("12", 120*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packets, err := NewDefaultEncoder(codec).EncodeSequence(tones, 80*time.Millisecond, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// all end packets are lost, the first update is retransmitted
+	received := []Packet{packets[0]}
+	for _, p := range packets {
+		if !p.Payload.End {
+			received = append(received, p)
+		}
+	}
+
+	d := NewDecoder(codec)
+	decoded := decode(t, d, received)
+	// the first tone is completed by the next event with the last known duration
+	if len(decoded) != 1 || decoded[0].Event != Digit1 || decoded[0].Duration != 100*time.Millisecond {
+		t.Fatalf("decoded %+v", decoded)
+	}
+	// the second tone lost all its end packets and is completed on flush
+	flushed := d.Flush()
+	if len(flushed) != 1 || flushed[0].Event != Digit2 || flushed[0].Duration != 100*time.Millisecond {
+		t.Errorf("flushed %+v", flushed)
+	}
+	if flushed = d.Flush(); flushed != nil {
+		t.Errorf("second flush = %+v", flushed)
+	}
+}
+
+func TestDecoderLateEndPacket(t *testing.T) {
+	d := NewDecoder(audiocodec.Pcm8kHz16bCodec)
+	end := Payload{Event: Digit3, End: true, Volume: 10, Duration: 800}.Bytes()
+	tones, err := d.Decode(100, end)
+	if err != nil || len(tones) != 1 {
+		t.Fatalf("decoded %+v, %v", tones, err)
+	}
+	// redundant end packets and late updates of the finished event are ignored
+	for _, ts := range []uint32{100, 99} {
+		if tones, err = d.Decode(ts, end); err != nil || tones != nil {
+			t.Errorf("timestamp %d: decoded %+v, %v", ts, tones, err)
+		}
+	}
+
+	if _, err = d.Decode(200, end[:2]); !errors.Is(err, TruncatedPayload) {
+		t.Errorf("Decode = %v, want %v", err, TruncatedPayload)
+	}
+}
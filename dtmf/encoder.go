@@ -0,0 +1,125 @@
+package dtmf
+
+import (
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+const (
+	DefaultPacketInterval = 50 * time.Millisecond
+	DefaultEndPackets     = 3
+)
+
+// Packet is a telephone-event payload together with the RTP header fields the sender has to set.
+type Packet struct {
+	Marker    bool
+	Timestamp uint32
+	// Offset is the moment the packet should be sent, relative to the beginning of the tone
+	Offset  time.Duration
+	Payload Payload
+}
+
+// Encoder converts tones into RFC 4733 packet sequences.
+// Durations are expressed in units of the clock rate of the codec used by the RTP session.
+type Encoder struct {
+	codec          *audiocodec.Codec
+	packetInterval time.Duration
+	endPackets     int
+}
+
+func NewEncoder(codec *audiocodec.Codec, packetInterval time.Duration, endPackets int) (*Encoder, error) {
+	if packetInterval <= 0 || durationToUnits(codec, packetInterval) == 0 {
+		return nil, InvalidPacketInterval
+	}
+	if endPackets < 1 {
+		endPackets = 1
+	}
+
+	return &Encoder{
+		codec:          codec,
+		packetInterval: packetInterval,
+		endPackets:     endPackets,
+	}, nil
+}
+
+func NewDefaultEncoder(codec *audiocodec.Codec) *Encoder {
+	return &Encoder{
+		codec:          codec,
+		packetInterval: DefaultPacketInterval,
+		endPackets:     DefaultEndPackets,
+	}
+}
+
+// Encode returns all packets describing the tone starting at the RTP timestamp.
+// An update is produced every packet interval, the final packet is repeated to survive packet loss.
+// Events longer than MaxDuration are split into segments as described in RFC 4733 section 2.5.1.3.
+func (e *Encoder) Encode(tone Tone, timestamp uint32) ([]Packet, error) {
+	if tone.Volume > MaxVolume {
+		return nil, InvalidVolume
+	}
+
+	total := durationToUnits(e.codec, tone.Duration)
+	step := durationToUnits(e.codec, e.packetInterval)
+	packets := make([]Packet, 0, total/step+int64(e.endPackets)+1)
+
+	var segmentStart int64
+	packet := func(duration int64, end bool, elapsed int64) Packet {
+		return Packet{
+			Marker:    len(packets) == 0,
+			Timestamp: timestamp + uint32(segmentStart),
+			Offset:    unitsToDuration(e.codec, elapsed),
+			Payload: Payload{
+				Event:    tone.Event,
+				End:      end,
+				Volume:   tone.Volume,
+				Duration: uint16(duration),
+			},
+		}
+	}
+
+	for elapsed := step; ; elapsed += step {
+		final := elapsed >= total
+		if final {
+			elapsed = total
+		}
+
+		for elapsed-segmentStart > MaxDuration {
+			packets = append(packets, packet(MaxDuration, false, segmentStart+MaxDuration))
+			segmentStart += MaxDuration
+		}
+
+		if !final {
+			packets = append(packets, packet(elapsed-segmentStart, false, elapsed))
+			continue
+		}
+
+		for i := 0; i < e.endPackets; i++ {
+			packets = append(packets, packet(elapsed-segmentStart, true, elapsed))
+		}
+		return packets, nil
+	}
+}
+
+// EncodeSequence encodes consecutive tones separated by gap, the first tone starts at the RTP timestamp.
+// Packet offsets are relative to the beginning of the first tone.
+func (e *Encoder) EncodeSequence(tones []Tone, gap time.Duration, timestamp uint32) ([]Packet, error) {
+	var packets []Packet
+	var start time.Duration
+	for _, tone := range tones {
+		tonePackets, err := e.Encode(tone, timestamp+uint32(durationToUnits(e.codec, start)))
+		if err != nil {
+			return nil, err
+		}
+		for i := range tonePackets {
+			tonePackets[i].Offset += start
+		}
+		packets = append(packets, tonePackets...)
+		start += tone.Duration + gap
+	}
+	return packets, nil
+}
+
+func (e *Encoder) Codec() *audiocodec.Codec {
+	return e.codec
+}
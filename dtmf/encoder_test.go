@@ -0,0 +1,108 @@
+package dtmf
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+func TestNewEncoder(t *testing.T) {
+	// one timestamp unit at 8 kHz lasts 125 µs
+	if _, err := NewEncoder(audiocodec.Pcm8kHz16bCodec, 100*time.Microsecond, 3); !errors.Is(err, InvalidPacketInterval) {
+		t.Errorf("NewEncoder = %v, want %v", err, InvalidPacketInterval)
+	}
+	e, err := NewEncoder(audiocodec.Pcm8kHz16bCodec, 20*time.Millisecond, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.endPackets != 1 {
+		t.Errorf("end packets = %d, want 1", e.endPackets)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	e := NewDefaultEncoder(audiocodec.Pcm8kHz16bCodec)
+	packets, err := e.Encode(NewTone(Digit7, 120*time.Millisecond), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// updates every 50 ms (400 units) and the final duration of 960 units sent three times
+	want := []Packet{
+		{Marker: true, Timestamp: 1000, Offset: 50 * time.Millisecond, Payload: Payload{Event: Digit7, Volume: DefaultVolume, Duration: 400}},
+		{Timestamp: 1000, Offset: 100 * time.Millisecond, Payload: Payload{Event: Digit7, Volume: DefaultVolume, Duration: 800}},
+		{Timestamp: 1000, Offset: 120 * time.Millisecond, Payload: Payload{Event: Digit7, End: true, Volume: DefaultVolume, Duration: 960}},
+		{Timestamp: 1000, Offset: 120 * time.Millisecond, Payload: Payload{Event: Digit7, End: true, Volume: DefaultVolume, Duration: 960}},
+		{Timestamp: 1000, Offset: 120 * time.Millisecond, Payload: Payload{Event: Digit7, End: true, Volume: DefaultVolume, Duration: 960}},
+	}
+	if len(packets) != len(want) {
+		t.Fatalf("%d packets, want %d", len(packets), len(want))
+	}
+	for i, p := range packets {
+		if p != want[i] {
+			t.Errorf("packet %d = %+v, want %+v", i, p, want[i])
+		}
+	}
+
+	if _, err = e.Encode(Tone{Event: Digit7, Volume: MaxVolume + 1, Duration: time.Second}, 0); !errors.Is(err, InvalidVolume) {
+		t.Errorf("Encode = %v, want %v", err, InvalidVolume)
+	}
+}
+
+func TestEncodeLongEvent(t *testing.T) {
+	e := NewDefaultEncoder(audiocodec.Pcm8kHz16bCodec)
+	// 10 s at 8 kHz is 80000 units, more than a single payload can express
+	start := uint32(0xFFFFFF00)
+	packets, err := e.Encode(NewTone(Pound, 10*time.Second), start)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var segments int
+	for i, p := range packets {
+		if p.Marker != (i == 0) {
+			t.Errorf("packet %d: marker %v", i, p.Marker)
+		}
+		if p.Payload.Duration == MaxDuration {
+			segments++
+			if p.Timestamp != start || p.Payload.End {
+				t.Errorf("segment end: %+v", p)
+			}
+		}
+	}
+	if segments != 1 {
+		t.Errorf("%d segments of the maximum duration, want 1", segments)
+	}
+
+	// the next segment starts at the timestamp advanced by the maximum duration, wrapping around
+	last := packets[len(packets)-1]
+	if last.Timestamp != start+MaxDuration || last.Payload.Duration != 80000-MaxDuration || !last.Payload.End {
+		t.Errorf("last packet = %+v", last)
+	}
+	if last.Offset != 10*time.Second {
+		t.Errorf("last offset = %v, want %v", last.Offset, 10*time.Second)
+	}
+}
+
+func TestEncodeSequence(t *testing.T) {
+	e := NewDefaultEncoder(audiocodec.Pcm8kHz16bCodec)
+	tones, err := NewTones("12", 100*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packets, err := e.EncodeSequence(tones, 50*time.Millisecond, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// each tone produces an update, and three end packets
+	if len(packets) != 8 {
+		t.Fatalf("%d packets, want 8", len(packets))
+	}
+	second := packets[4]
+	if !second.Marker || second.Payload.Event != Digit2 || second.Timestamp != 1200 || second.Offset != 200*time.Millisecond {
+		t.Errorf("first packet of the second tone = %+v", second)
+	}
+}
@@ -0,0 +1,10 @@
+package dtmf
+
+import "errors"
+
+var (
+	UnknownEvent          = errors.New("unknown telephone-event")
+	TruncatedPayload      = errors.New("telephone-event payload is truncated")
+	InvalidVolume         = errors.New("telephone-event volume must be in range 0-63")
+	InvalidPacketInterval = errors.New("telephone-event packet interval is shorter than one timestamp unit")
+)
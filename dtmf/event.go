@@ -0,0 +1,127 @@
+package dtmf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Event is an RFC 4733 telephone-event code.
+// Codes 0-15 are DTMF digits, 16 is a hook flash.
+type Event uint8
+
+const (
+	Digit0 Event = iota
+	Digit1
+	Digit2
+	Digit3
+	Digit4
+	Digit5
+	Digit6
+	Digit7
+	Digit8
+	Digit9
+	Star
+	Pound
+	DigitA
+	DigitB
+	DigitC
+	DigitD
+	Flash
+)
+
+const symbols = "0123456789*#ABCD"
+
+// Row and column frequencies of the DTMF keypad in Hz
+var (
+	lowFrequencies  = [4]float64{697, 770, 852, 941}
+	highFrequencies = [4]float64{1209, 1336, 1477, 1633}
+)
+
+// keypad maps events 0-15 to their [row, column] on the DTMF keypad
+var keypad = [16][2]int{
+	{3, 1}, // 0
+	{0, 0}, // 1
+	{0, 1}, // 2
+	{0, 2}, // 3
+	{1, 0}, // 4
+	{1, 1}, // 5
+	{1, 2}, // 6
+	{2, 0}, // 7
+	{2, 1}, // 8
+	{2, 2}, // 9
+	{3, 0}, // *
+	{3, 2}, // #
+	{0, 3}, // A
+	{1, 3}, // B
+	{2, 3}, // C
+	{3, 3}, // D
+}
+
+func ParseEvent(r rune) (Event, error) {
+	if i := strings.IndexRune(symbols, r); i >= 0 {
+		return Event(i), nil
+	}
+	if i := strings.IndexRune("abcd", r); i >= 0 {
+		return DigitA + Event(i), nil
+	}
+	if r == '!' {
+		return Flash, nil
+	}
+
+	return 0, fmt.Errorf("%w: %q", UnknownEvent, r)
+}
+
+// ParseEvents converts a dial string such as "123#" into events.
+func ParseEvents(s string) ([]Event, error) {
+	events := make([]Event, 0, len(s))
+	for _, r := range s {
+		e, err := ParseEvent(r)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+func (e Event) IsDtmf() bool {
+	return e <= DigitD
+}
+
+func (e Event) Rune() rune {
+	if e.IsDtmf() {
+		return rune(symbols[e])
+	}
+	if e == Flash {
+		return '!'
+	}
+	return '?'
+}
+
+// Frequencies returns the low and high frequency of the in-band DTMF tone.
+// Both values are zero for events that have no in-band representation.
+func (e Event) Frequencies() (low float64, high float64) {
+	if !e.IsDtmf() {
+		return 0, 0
+	}
+	pos := keypad[e]
+	return lowFrequencies[pos[0]], highFrequencies[pos[1]]
+}
+
+// EventByFrequencies returns the DTMF event whose row and column frequencies are exactly low and high.
+func EventByFrequencies(low float64, high float64) (Event, bool) {
+	for e := Digit0; e <= DigitD; e++ {
+		l, h := e.Frequencies()
+		if l == low && h == high {
+			return e, true
+		}
+	}
+	return 0, false
+}
+
+func (e Event) String() string {
+	if e.IsDtmf() || e == Flash {
+		return string(e.Rune())
+	}
+	return fmt.Sprintf("event(%d)", uint8(e))
+}
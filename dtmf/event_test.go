@@ -0,0 +1,76 @@
+package dtmf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseEvents(t *testing.T) {
+	events, err := ParseEvents("0123456789*#ABCDabcd!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range events {
+		want := Event(i)
+		if i >= 16 && i < 20 {
+			want = DigitA + Event(i-16)
+		} else if i == 20 {
+			want = Flash
+		}
+		if e != want {
+			t.Errorf("event %d = %v, want %v", i, e, want)
+		}
+	}
+
+	if _, err = ParseEvents("12x"); !errors.Is(err, UnknownEvent) {
+		t.Errorf("ParseEvents = %v, want %v", err, UnknownEvent)
+	}
+}
+
+func TestEventRune(t *testing.T) {
+	for _, r := range "0123456789*#ABCD!" {
+		e, err := ParseEvent(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Rune() != r || e.String() != string(r) {
+			t.Errorf("event %d: rune %q, string %q, want %q", e, e.Rune(), e.String(), r)
+		}
+	}
+	if e := Event(32); e.Rune() != '?' || e.String() != "event(32)" {
+		t.Errorf("event 32: rune %q, string %q", e.Rune(), e.String())
+	}
+}
+
+func TestFrequencies(t *testing.T) {
+	// ITU-T Q.23 keypad
+	tests := []struct {
+		event     Event
+		low, high float64
+	}{
+		{Digit1, 697, 1209},
+		{Digit5, 770, 1336},
+		{Digit9, 852, 1477},
+		{Digit0, 941, 1336},
+		{Star, 941, 1209},
+		{Pound, 941, 1477},
+		{DigitA, 697, 1633},
+		{DigitD, 941, 1633},
+		{Flash, 0, 0},
+	}
+	for _, tt := range tests {
+		if low, high := tt.event.Frequencies(); low != tt.low || high != tt.high {
+			t.Errorf("%v: frequencies %v, %v, want %v, %v", tt.event, low, high, tt.low, tt.high)
+		}
+	}
+
+	for e := Digit0; e <= DigitD; e++ {
+		low, high := e.Frequencies()
+		if found, ok := EventByFrequencies(low, high); !ok || found != e {
+			t.Errorf("EventByFrequencies(%v, %v) = %v, %v, want %v", low, high, found, ok, e)
+		}
+	}
+	if _, ok := EventByFrequencies(697, 1336.5); ok {
+		t.Error("EventByFrequencies matched an off-grid frequency")
+	}
+}
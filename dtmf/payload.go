@@ -0,0 +1,57 @@
+package dtmf
+
+import "encoding/binary"
+
+const (
+	PayloadSize = 4
+	MaxVolume   = 63
+	MaxDuration = 0xFFFF
+)
+
+// Payload is a single RFC 4733 telephone-event payload:
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|     event     |E|R| volume    |          duration             |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//
+// Volume is the power level of the tone expressed in dBm0 after dropping the sign,
+// Duration is measured in timestamp units of the RTP clock rate.
+type Payload struct {
+	Event    Event
+	End      bool
+	Volume   uint8
+	Duration uint16
+}
+
+func ParsePayload(b []byte) (Payload, error) {
+	if len(b) < PayloadSize {
+		return Payload{}, TruncatedPayload
+	}
+
+	return Payload{
+		Event:    Event(b[0]),
+		End:      b[1]&0x80 != 0,
+		Volume:   b[1] & 0x3F,
+		Duration: binary.BigEndian.Uint16(b[2:4]),
+	}, nil
+}
+
+func (p Payload) Bytes() []byte {
+	b := make([]byte, PayloadSize)
+	p.Put(b)
+	return b
+}
+
+// Put encodes the payload into the first PayloadSize bytes of buf.
+// The reserved bit is always sent as zero.
+func (p Payload) Put(buf []byte) {
+	_ = buf[PayloadSize-1]
+	buf[0] = byte(p.Event)
+	buf[1] = p.Volume & 0x3F
+	if p.End {
+		buf[1] |= 0x80
+	}
+	binary.BigEndian.PutUint16(buf[2:4], p.Duration)
+}
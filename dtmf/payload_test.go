@@ -0,0 +1,41 @@
+package dtmf
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPayload(t *testing.T) {
+	p := Payload{Event: Digit5, End: true, Volume: 10, Duration: 800}
+	// RFC 4733 section 2.3: event, E bit and volume, 16-bit duration in network byte order
+	want := []byte{0x05, 0x8a, 0x03, 0x20}
+	b := p.Bytes()
+	if !bytes.Equal(b, want) {
+		t.Errorf("payload = % x, want % x", b, want)
+	}
+
+	parsed, err := ParsePayload(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed != p {
+		t.Errorf("parsed %+v, want %+v", parsed, p)
+	}
+
+	// the reserved bit is ignored by the receiver
+	if parsed, err = ParsePayload([]byte{0x05, 0xca, 0x03, 0x20}); err != nil || parsed != p {
+		t.Errorf("parsed %+v, %v, want %+v", parsed, err, p)
+	}
+	if _, err = ParsePayload(b[:3]); !errors.Is(err, TruncatedPayload) {
+		t.Errorf("truncated payload: %v, want %v", err, TruncatedPayload)
+	}
+}
+
+func TestPayloadPut(t *testing.T) {
+	buf := bytes.Repeat([]byte{0xff}, PayloadSize)
+	Payload{Event: Flash, Volume: MaxVolume, Duration: MaxDuration}.Put(buf)
+	if want := []byte{0x10, 0x3f, 0xff, 0xff}; !bytes.Equal(buf, want) {
+		t.Errorf("payload = % x, want % x", buf, want)
+	}
+}
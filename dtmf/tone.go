@@ -0,0 +1,71 @@
+package dtmf
+
+import (
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/tone"
+)
+
+const DefaultVolume = 10
+
+// Tone is the in-band representation of a telephone-event: what is actually heard by the far end.
+type Tone struct {
+	Event    Event
+	Volume   uint8 // -dBm0
+	Duration time.Duration
+}
+
+func NewTone(event Event, duration time.Duration) Tone {
+	return Tone{
+		Event:    event,
+		Volume:   DefaultVolume,
+		Duration: duration,
+	}
+}
+
+// NewTones builds tones for the dial string, every digit lasting duration.
+func NewTones(digits string, duration time.Duration) ([]Tone, error) {
+	events, err := ParseEvents(digits)
+	if err != nil {
+		return nil, err
+	}
+
+	tones := make([]Tone, len(events))
+	for i, e := range events {
+		tones[i] = NewTone(e, duration)
+	}
+	return tones, nil
+}
+
+// Signal returns the in-band form of the tone, which can be synthesized by tone.Generator at level -Volume dBm0.
+func (t Tone) Signal() *tone.Signal {
+	low, high := t.Event.Frequencies()
+	seg := tone.Segment{Duration: t.Duration}
+	if t.Event.IsDtmf() {
+		seg.Frequencies = []float64{low, high}
+	}
+
+	return &tone.Signal{
+		Kind:     tone.Dtmf,
+		Name:     t.Event.String(),
+		Segments: []tone.Segment{seg},
+	}
+}
+
+// Digits converts tones back to a dial string.
+func Digits(tones []Tone) string {
+	digits := make([]rune, len(tones))
+	for i, t := range tones {
+		digits[i] = t.Event.Rune()
+	}
+	return string(digits)
+}
+
+func durationToUnits(codec *audiocodec.Codec, duration time.Duration) int64 {
+	return int64(duration) * int64(codec.ClockRate()) / int64(time.Second)
+}
+
+func unitsToDuration(codec *audiocodec.Codec, units int64) time.Duration {
+	return time.Duration(units * int64(time.Second) / int64(codec.ClockRate()))
+}
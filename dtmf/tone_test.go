@@ -0,0 +1,44 @@
+package dtmf
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec/tone"
+)
+
+func TestNewTones(t *testing.T) {
+	tones, err := NewTones("*0#", 80*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digits := Digits(tones); digits != "*0#" {
+		t.Errorf("digits = %q, want %q", digits, "*0#")
+	}
+	for _, tone := range tones {
+		if tone.Volume != DefaultVolume || tone.Duration != 80*time.Millisecond {
+			t.Errorf("tone %+v", tone)
+		}
+	}
+
+	if _, err = NewTones("1-2", time.Second); !errors.Is(err, UnknownEvent) {
+		t.Errorf("NewTones = %v, want %v", err, UnknownEvent)
+	}
+}
+
+func TestToneSignal(t *testing.T) {
+	s := NewTone(Digit8, 200*time.Millisecond).Signal()
+	if s.Kind != tone.Dtmf || s.Name != "8" || len(s.Segments) != 1 {
+		t.Fatalf("signal %+v", s)
+	}
+	seg := s.Segments[0]
+	if len(seg.Frequencies) != 2 || seg.Frequencies[0] != 852 || seg.Frequencies[1] != 1336 || seg.Duration != 200*time.Millisecond {
+		t.Errorf("segment %+v", seg)
+	}
+
+	// a hook flash has no in-band tone
+	if s = NewTone(Flash, 500*time.Millisecond).Signal(); !s.Segments[0].IsSilence() {
+		t.Errorf("flash segment %+v", s.Segments[0])
+	}
+}
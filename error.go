@@ -9,5 +9,18 @@ var (
 	InvalidWav                        = errors.New("invalid WAV: missing RIFF/WAVE")
 	TruncatedWav                      = errors.New("invalid WAV: truncated chunk")
 	UnsupportedFormat                 = errors.New("unsupported WAV format")
-	OnlyMonoSupported                 = errors.New("unsupported WAV: only mono supported by Codec")
+	InvalidInfoId                     = errors.New("invalid WAV: LIST/INFO item ID must have 4 characters")
+	InvalidCuePoint                   = errors.New("invalid WAV cue point")
+	InvalidAiff                       = errors.New("invalid AIFF: missing FORM/AIFF")
+	TruncatedAiff                     = errors.New("invalid AIFF: truncated chunk")
+	UnsupportedAiffFormat             = errors.New("unsupported AIFF format")
+	AiffFileIsNotEditable             = errors.New("aiff file is not editable")
+	InvalidAu                         = errors.New("invalid AU: missing .snd header")
+	UnsupportedAuFormat               = errors.New("unsupported AU format")
+	AuFileIsNotEditable               = errors.New("au file is not editable")
+	IncompleteAuSample                = errors.New("AU stream ends with an incomplete sample")
+	WavWriterIsClosed                 = errors.New("wav writer is closed")
 )
+
+// Deprecated: WAV files of any number of channels are parsed, every channel is described by Codec.
+var OnlyMonoSupported = errors.New("unsupported WAV: only mono supported by Codec")
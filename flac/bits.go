@@ -0,0 +1,131 @@
+package flac
+
+import (
+	"io"
+	"math/bits"
+)
+
+// bitReader reads MSB-first bit fields and keeps the CRCs of all consumed bytes
+type bitReader struct {
+	r     io.ByteReader
+	x     uint64
+	n     uint
+	crc8  byte
+	crc16 uint16
+}
+
+func (br *bitReader) resetCrc() {
+	br.crc8 = 0
+	br.crc16 = 0
+}
+
+func (br *bitReader) readByte() error {
+	b, err := br.r.ReadByte()
+	if err != nil {
+		return err
+	}
+	br.crc8 = crc8Table[br.crc8^b]
+	br.crc16 = br.crc16<<8 ^ crc16Table[byte(br.crc16>>8)^b]
+	br.x = br.x<<8 | uint64(b)
+	br.n += 8
+	return nil
+}
+
+func (br *bitReader) readBits(n uint) (uint64, error) {
+	for br.n < n {
+		if err := br.readByte(); err != nil {
+			return 0, unexpectedEOF(err)
+		}
+	}
+	br.n -= n
+	return br.x >> br.n & (1<<n - 1), nil
+}
+
+func (br *bitReader) readSigned(n uint) (int32, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	v, err := br.readBits(n)
+	if err != nil {
+		return 0, err
+	}
+	return int32(int64(v<<(64-n)) >> (64 - n)), nil
+}
+
+// readUnary returns the number of zero bits before the next set bit
+func (br *bitReader) readUnary() (uint64, error) {
+	var v uint64
+	for {
+		if br.n == 0 {
+			if err := br.readByte(); err != nil {
+				return 0, unexpectedEOF(err)
+			}
+		}
+		rest := br.x & (1<<br.n - 1)
+		if rest == 0 {
+			v += uint64(br.n)
+			br.n = 0
+			continue
+		}
+		l := uint(bits.Len64(rest))
+		v += uint64(br.n - l)
+		br.n = l - 1
+		return v, nil
+	}
+}
+
+// align skips the bits up to the byte boundary
+func (br *bitReader) align() {
+	br.n -= br.n % 8
+}
+
+// bitWriter writes MSB-first bit fields into a byte slice
+type bitWriter struct {
+	buf []byte
+	x   uint64
+	n   uint
+}
+
+func (bw *bitWriter) writeBits(v uint64, n uint) {
+	for n > 32 {
+		n -= 32
+		bw.writeBits(v>>n, 32)
+	}
+	bw.x = bw.x<<n | v&(1<<n-1)
+	bw.n += n
+	for bw.n >= 8 {
+		bw.n -= 8
+		bw.buf = append(bw.buf, byte(bw.x>>bw.n))
+	}
+}
+
+func (bw *bitWriter) writeSigned(v int32, n uint) {
+	bw.writeBits(uint64(int64(v)), n)
+}
+
+func (bw *bitWriter) writeUnary(v uint64) {
+	for v >= 32 {
+		bw.writeBits(0, 32)
+		v -= 32
+	}
+	bw.writeBits(1, uint(v)+1)
+}
+
+func (bw *bitWriter) align() {
+	if bw.n > 0 {
+		bw.writeBits(0, 8-bw.n)
+	}
+}
+
+func (bw *bitWriter) reset() {
+	bw.buf = bw.buf[:0]
+	bw.x = 0
+	bw.n = 0
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,585 @@
+package flac
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/ogg"
+)
+
+const (
+	channelLeftSide  = 8
+	channelRightSide = 9
+	channelMidSide   = 10
+)
+
+var sampleRates = [12]int{0, 88_200, 176_400, 192_000, 8_000, 16_000, 22_050, 24_000, 32_000, 44_100, 48_000, 96_000}
+
+var sampleSizes = [8]int{0, 8, 12, 0, 16, 20, 24, 32}
+
+// Decoder reads a native FLAC stream frame by frame.
+type Decoder struct {
+	br       bitReader
+	info     *StreamInfo
+	vendor   string
+	comments ogg.Comments
+	codec    *audiocodec.Codec
+	samples  [][]int32
+	md5      hash.Hash
+	md5buf   []byte
+	decoded  int64
+}
+
+// NewDecoder reads the "fLaC" marker and all metadata blocks preceding the first frame.
+func NewDecoder(r io.Reader) (*Decoder, error) {
+	byteReader, ok := r.(io.ByteReader)
+	if !ok {
+		byteReader = bufio.NewReader(r)
+	}
+	d := &Decoder{
+		br:  bitReader{r: byteReader},
+		md5: md5.New(),
+	}
+
+	header := make([]byte, 4)
+	if err := d.readFull(header); err != nil {
+		return nil, InvalidStream
+	}
+	if string(header[0:3]) == "ID3" {
+		if err := d.skipId3(header); err != nil {
+			return nil, err
+		}
+	}
+	if string(header) != "fLaC" {
+		return nil, InvalidStream
+	}
+
+	for last := false; !last; {
+		if err := d.readFull(header); err != nil {
+			return nil, err
+		}
+		last = header[0]&0x80 != 0
+		blockType := header[0] & 0x7F
+		block := make([]byte, int(header[1])<<16|int(header[2])<<8|int(header[3]))
+		if err := d.readFull(block); err != nil {
+			return nil, err
+		}
+
+		switch blockType {
+		case BlockStreamInfo:
+			info, err := ParseStreamInfo(block)
+			if err != nil {
+				return nil, err
+			}
+			d.info = info
+		case BlockVorbisComment:
+			vendor, comments, _, ok := ogg.ParseComments(block)
+			if !ok {
+				return nil, fmt.Errorf("%w: invalid VORBIS_COMMENT block", InvalidStream)
+			}
+			d.vendor = vendor
+			d.comments = comments
+		}
+	}
+
+	if d.info == nil {
+		return nil, fmt.Errorf("%w: STREAMINFO block not found", InvalidStream)
+	}
+	if d.info.BitsPerSample > 24 {
+		return nil, fmt.Errorf("not supported bit rate: %d", d.info.BitsPerSample)
+	}
+	d.codec = d.info.Codec()
+	d.samples = make([][]int32, d.info.Channels)
+	return d, nil
+}
+
+func (d *Decoder) StreamInfo() *StreamInfo {
+	return d.info
+}
+
+// Codec returns the PCM codec of the samples returned by ReadFrame.
+func (d *Decoder) Codec() *audiocodec.Codec {
+	return d.codec
+}
+
+func (d *Decoder) Channels() int {
+	return d.info.Channels
+}
+
+func (d *Decoder) Vendor() string {
+	return d.vendor
+}
+
+func (d *Decoder) Comments() ogg.Comments {
+	return d.comments
+}
+
+// ReadFrame decodes the next frame into interleaved little-endian PCM of the decoder codec.
+// At the end of the stream io.EOF is returned after the MD5 signature of the audio is verified.
+func (d *Decoder) ReadFrame() ([]byte, error) {
+	if err := d.readFrame(); err != nil {
+		if err == io.EOF {
+			return nil, d.verify()
+		}
+		return nil, err
+	}
+
+	blockSize := len(d.samples[0])
+	sampleSize := d.codec.SampleSize()
+	md5Size := (d.info.BitsPerSample + 7) / 8
+	shift := uint(sampleSize*8 - d.info.BitsPerSample)
+	out := make([]byte, 0, blockSize*sampleSize*len(d.samples))
+	d.md5buf = d.md5buf[:0]
+	for i := 0; i < blockSize; i++ {
+		for _, channel := range d.samples {
+			s := channel[i]
+			for b := 0; b < md5Size; b++ {
+				d.md5buf = append(d.md5buf, byte(s>>(8*b)))
+			}
+			s <<= shift
+			switch sampleSize {
+			case 1:
+				out = append(out, byte(s+128))
+			case 2:
+				out = append(out, byte(s), byte(s>>8))
+			default:
+				out = append(out, byte(s), byte(s>>8), byte(s>>16))
+			}
+		}
+	}
+	d.md5.Write(d.md5buf)
+	d.decoded += int64(blockSize)
+
+	return out, nil
+}
+
+func (d *Decoder) verify() error {
+	if d.info.Md5 == [16]byte{} {
+		return io.EOF
+	}
+	if d.info.TotalSamples != 0 && d.decoded != d.info.TotalSamples {
+		return fmt.Errorf("%w: decoded %d samples of %d", io.ErrUnexpectedEOF, d.decoded, d.info.TotalSamples)
+	}
+	if !bytes.Equal(d.md5.Sum(nil), d.info.Md5[:]) {
+		return fmt.Errorf("%w: MD5 signature mismatch", InvalidChecksum)
+	}
+	return io.EOF
+}
+
+// skipId3 skips the ID3v2 tag some taggers put before the stream marker and reads the marker into header
+func (d *Decoder) skipId3(header []byte) error {
+	// the tag header is "ID3", 2 bytes of version, flags and 4 bytes of syncsafe size
+	rest := make([]byte, 6)
+	if err := d.readFull(rest); err != nil {
+		return err
+	}
+	size := int(rest[2]&0x7F)<<21 | int(rest[3]&0x7F)<<14 | int(rest[4]&0x7F)<<7 | int(rest[5]&0x7F)
+	if rest[1]&0x10 != 0 {
+		size += 10 // footer
+	}
+	if err := d.readFull(make([]byte, size)); err != nil {
+		return err
+	}
+	return d.readFull(header)
+}
+
+func (d *Decoder) readFull(b []byte) error {
+	for i := range b {
+		v, err := d.br.readBits(8)
+		if err != nil {
+			return err
+		}
+		b[i] = byte(v)
+	}
+	return nil
+}
+
+// Смещение (бит)	Размер (бит)	Описание
+// 0				14				Sync code 0b11111111111110
+// 14				1				Reserved
+// 15				1				Blocking strategy: 0 - fixed, 1 - variable block size
+// 16				4				Block size
+// 20				4				Sample rate
+// 24				4				Channel assignment
+// 28				3				Sample size
+// 31				1				Reserved
+// 32				*				UTF-8 coded frame or sample number, optional block size and sample rate, CRC-8
+func (d *Decoder) readFrame() error {
+	br := &d.br
+	br.resetCrc()
+
+	if d.info.TotalSamples != 0 && d.decoded >= d.info.TotalSamples {
+		return io.EOF
+	}
+	if err := br.readByte(); err != nil {
+		return err
+	}
+	br.n -= 8
+	if byte(br.x) != 0xFF {
+		return fmt.Errorf("%w: sync code not found", InvalidFrame)
+	}
+
+	header, err := br.readBits(24)
+	if err != nil {
+		return err
+	}
+	if header>>18 != 0x3E || header&0x01 != 0 {
+		return fmt.Errorf("%w: invalid header", InvalidFrame)
+	}
+	blockSizeCode := header >> 12 & 0x0F
+	sampleRateCode := header >> 8 & 0x0F
+	channelAssignment := int(header >> 4 & 0x0F)
+	sampleSizeCode := header >> 1 & 0x07
+
+	if err = d.skipUtf8Number(); err != nil {
+		return err
+	}
+
+	var blockSize int
+	switch {
+	case blockSizeCode == 0:
+		return fmt.Errorf("%w: reserved block size", InvalidFrame)
+	case blockSizeCode == 1:
+		blockSize = 192
+	case blockSizeCode <= 5:
+		blockSize = 576 << (blockSizeCode - 2)
+	case blockSizeCode == 6:
+		v, err := br.readBits(8)
+		if err != nil {
+			return err
+		}
+		blockSize = int(v) + 1
+	case blockSizeCode == 7:
+		v, err := br.readBits(16)
+		if err != nil {
+			return err
+		}
+		blockSize = int(v) + 1
+	default:
+		blockSize = 256 << (blockSizeCode - 8)
+	}
+
+	switch sampleRateCode {
+	case 12:
+		_, err = br.readBits(8)
+	case 13, 14:
+		_, err = br.readBits(16)
+	case 15:
+		err = fmt.Errorf("%w: invalid sample rate", InvalidFrame)
+	}
+	if err != nil {
+		return err
+	}
+
+	bps := d.info.BitsPerSample
+	if sampleSizeCode != 0 {
+		if bps = sampleSizes[sampleSizeCode]; bps == 0 {
+			return fmt.Errorf("%w: reserved sample size", InvalidFrame)
+		}
+	}
+
+	channels := channelAssignment + 1
+	if channelAssignment >= channelLeftSide {
+		if channelAssignment > channelMidSide {
+			return fmt.Errorf("%w: reserved channel assignment", InvalidFrame)
+		}
+		channels = 2
+	}
+	if channels != d.info.Channels || blockSize > d.info.MaxBlockSize || bps != d.info.BitsPerSample {
+		return fmt.Errorf("%w: frame does not match STREAMINFO", InvalidFrame)
+	}
+
+	crc := br.crc8
+	v, err := br.readBits(8)
+	if err != nil {
+		return err
+	}
+	if byte(v) != crc {
+		return fmt.Errorf("%w: frame header CRC-8", InvalidChecksum)
+	}
+
+	for ch := range d.samples {
+		channelBps := uint(bps)
+		if channelAssignment == channelLeftSide && ch == 1 ||
+			channelAssignment == channelRightSide && ch == 0 ||
+			channelAssignment == channelMidSide && ch == 1 {
+			channelBps++ // the side channel needs an extra bit
+		}
+		if cap(d.samples[ch]) < blockSize {
+			d.samples[ch] = make([]int32, blockSize)
+		}
+		d.samples[ch] = d.samples[ch][:blockSize]
+		if err = d.readSubframe(d.samples[ch], channelBps); err != nil {
+			return err
+		}
+	}
+
+	br.align()
+	crc16 := br.crc16
+	v, err = br.readBits(16)
+	if err != nil {
+		return err
+	}
+	if uint16(v) != crc16 {
+		return fmt.Errorf("%w: frame CRC-16", InvalidChecksum)
+	}
+
+	decorrelate(channelAssignment, d.samples)
+	return nil
+}
+
+func (d *Decoder) skipUtf8Number() error {
+	v, err := d.br.readBits(8)
+	if err != nil {
+		return err
+	}
+	var n int
+	for b := byte(v); b&0x80 != 0; b <<= 1 {
+		n++
+	}
+	if n == 1 || n > 7 {
+		return fmt.Errorf("%w: invalid frame number", InvalidFrame)
+	}
+	for i := 1; i < n; i++ {
+		if v, err = d.br.readBits(8); err != nil {
+			return err
+		}
+		if v&0xC0 != 0x80 {
+			return fmt.Errorf("%w: invalid frame number", InvalidFrame)
+		}
+	}
+	return nil
+}
+
+// Subframe header: zero padding bit, 6 bits of type, wasted bits flag followed by the unary coded number of wasted bits:
+//
+//	000000		Constant
+//	000001		Verbatim
+//	001xxx		Fixed predictor of order xxx (0 - 4)
+//	1xxxxx		Linear predictor of order xxxxx + 1
+func (d *Decoder) readSubframe(samples []int32, bps uint) error {
+	br := &d.br
+	header, err := br.readBits(8)
+	if err != nil {
+		return err
+	}
+	if header&0x80 != 0 {
+		return fmt.Errorf("%w: invalid padding", InvalidSubframe)
+	}
+
+	var wasted uint
+	if header&0x01 != 0 {
+		k, err := br.readUnary()
+		if err != nil {
+			return err
+		}
+		wasted = uint(k) + 1
+		if wasted >= bps {
+			return fmt.Errorf("%w: too many wasted bits", InvalidSubframe)
+		}
+		bps -= wasted
+	}
+
+	subframeType := header >> 1 & 0x3F
+	switch {
+	case subframeType == 0:
+		v, err := br.readSigned(bps)
+		if err != nil {
+			return err
+		}
+		for i := range samples {
+			samples[i] = v
+		}
+	case subframeType == 1:
+		for i := range samples {
+			if samples[i], err = br.readSigned(bps); err != nil {
+				return err
+			}
+		}
+	case subframeType >= 8 && subframeType <= 8+maxFixedOrder:
+		order := int(subframeType - 8)
+		if err = d.readPredicted(samples, bps, fixedCoefficients[order], 0); err != nil {
+			return err
+		}
+	case subframeType >= 32:
+		if err = d.readLpc(samples, bps, int(subframeType-31)); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: reserved subframe type %d", InvalidSubframe, subframeType)
+	}
+
+	if wasted > 0 {
+		for i := range samples {
+			samples[i] <<= wasted
+		}
+	}
+	return nil
+}
+
+func (d *Decoder) readLpc(samples []int32, bps uint, order int) error {
+	br := &d.br
+	if order > len(samples) {
+		return fmt.Errorf("%w: predictor order exceeds block size", InvalidSubframe)
+	}
+
+	for i := 0; i < order; i++ {
+		v, err := br.readSigned(bps)
+		if err != nil {
+			return err
+		}
+		samples[i] = v
+	}
+	precision, err := br.readBits(4)
+	if err != nil {
+		return err
+	}
+	if precision == 0x0F {
+		return fmt.Errorf("%w: invalid coefficient precision", InvalidSubframe)
+	}
+	shift, err := br.readSigned(5)
+	if err != nil {
+		return err
+	}
+	if shift < 0 {
+		return fmt.Errorf("%w: negative coefficient shift", InvalidSubframe)
+	}
+	coefficients := make([]int32, order)
+	for i := range coefficients {
+		if coefficients[i], err = br.readSigned(uint(precision) + 1); err != nil {
+			return err
+		}
+	}
+
+	if err = d.readResidual(samples[order:], len(samples), order); err != nil {
+		return err
+	}
+	restoreLpc(samples, coefficients, uint(shift))
+	return nil
+}
+
+func (d *Decoder) readPredicted(samples []int32, bps uint, coefficients []int32, shift uint) error {
+	order := len(coefficients)
+	if order > len(samples) {
+		return fmt.Errorf("%w: predictor order exceeds block size", InvalidSubframe)
+	}
+	for i := 0; i < order; i++ {
+		v, err := d.br.readSigned(bps)
+		if err != nil {
+			return err
+		}
+		samples[i] = v
+	}
+	if err := d.readResidual(samples[order:], len(samples), order); err != nil {
+		return err
+	}
+	restoreLpc(samples, coefficients, shift)
+	return nil
+}
+
+// readResidual reads rice coded partitions, the first partition is shorter by the predictor order
+func (d *Decoder) readResidual(residual []int32, blockSize int, order int) error {
+	br := &d.br
+	method, err := br.readBits(2)
+	if err != nil {
+		return err
+	}
+	if method > 1 {
+		return fmt.Errorf("%w: reserved residual coding method", InvalidSubframe)
+	}
+	paramBits, escape := uint(4), uint64(riceEscape4)
+	if method == 1 {
+		paramBits, escape = 5, riceEscape5
+	}
+
+	partitionOrder, err := br.readBits(4)
+	if err != nil {
+		return err
+	}
+	partitionSize := blockSize >> partitionOrder
+	if partitionSize<<partitionOrder != blockSize || partitionSize < order {
+		return fmt.Errorf("%w: invalid partition order", InvalidSubframe)
+	}
+
+	i := 0
+	for p := 0; p < 1<<partitionOrder; p++ {
+		n := partitionSize
+		if p == 0 {
+			n -= order
+		}
+		param, err := br.readBits(paramBits)
+		if err != nil {
+			return err
+		}
+		if param == escape {
+			size, err := br.readBits(5)
+			if err != nil {
+				return err
+			}
+			for end := i + n; i < end; i++ {
+				if residual[i], err = br.readSigned(uint(size)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		for end := i + n; i < end; i++ {
+			q, err := br.readUnary()
+			if err != nil {
+				return err
+			}
+			r, err := br.readBits(uint(param))
+			if err != nil {
+				return err
+			}
+			residual[i] = unzigzag(uint32(q<<param | r))
+		}
+	}
+	return nil
+}
+
+func decorrelate(channelAssignment int, samples [][]int32) {
+	switch channelAssignment {
+	case channelLeftSide:
+		for i, side := range samples[1] {
+			samples[1][i] = samples[0][i] - side
+		}
+	case channelRightSide:
+		for i, side := range samples[0] {
+			samples[0][i] = side + samples[1][i]
+		}
+	case channelMidSide:
+		for i, side := range samples[1] {
+			mid := samples[0][i]<<1 | side&1
+			samples[0][i] = (mid + side) >> 1
+			samples[1][i] = (mid - side) >> 1
+		}
+	}
+}
+
+// Decode reads the whole FLAC stream into a WAV with the same number of channels.
+func Decode(r io.Reader) (*audiocodec.Wav, error) {
+	d, err := NewDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+
+	wav := audiocodec.NewWavWithChannels(d.Codec(), d.Channels())
+	for {
+		frame, err := d.ReadFrame()
+		if err == io.EOF {
+			return wav, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, err = wav.Write(frame); err != nil {
+			return nil, err
+		}
+	}
+}
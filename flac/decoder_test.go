@@ -0,0 +1,118 @@
+package flac
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+// referenceStream is a stereo stream of 16-bit samples at 44.1 kHz built by hand after the format specification:
+// a frame of left/side channels with constant and verbatim subframes and a frame of independent channels with
+// fixed order 1 and verbatim subframes
+var referenceStream = []byte{
+	0x66, 0x4c, 0x61, 0x43, 0x80, 0x00, 0x00, 0x22, 0x00, 0x10, 0x00, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0a,
+	0xc4, 0x42, 0xf0, 0x00, 0x00, 0x00, 0x20, 0x18, 0x56, 0x52, 0x68, 0x22, 0xee, 0x48, 0x2e, 0xbf, 0x10, 0x70, 0xc0,
+	0x73, 0x53, 0x5a, 0xa3, 0xff, 0xf8, 0x69, 0x88, 0x00, 0x0f, 0x3b, 0x00, 0x03, 0xe8, 0x02, 0xff, 0xf3, 0x80, 0x03,
+	0x3f, 0xff, 0xff, 0xff, 0x20, 0x00, 0xb8, 0x00, 0x2b, 0xff, 0xfb, 0xff, 0xf0, 0x00, 0x0a, 0x80, 0x02, 0x3f, 0xff,
+	0x7f, 0xfe, 0xe0, 0x00, 0x98, 0x00, 0x1b, 0xff, 0xf3, 0xff, 0xec, 0x6c, 0xd3, 0xff, 0xf8, 0x69, 0x18, 0x01, 0x0f,
+	0x87, 0x12, 0xff, 0x38, 0x01, 0x66, 0xcb, 0xe5, 0x4d, 0x88, 0x8b, 0x8e, 0x84, 0xc3, 0x21, 0xf0, 0x54, 0x1b, 0x02,
+	0x20, 0x5c, 0x04, 0x00, 0x01, 0xff, 0x38, 0x01, 0x91, 0xfd, 0xa8, 0x03, 0x21, 0xfc, 0x18, 0x04, 0xb1, 0xfa, 0x88,
+	0x06, 0x41, 0xf8, 0xf8, 0x07, 0xd1, 0xf7, 0x68, 0x09, 0x61, 0xf5, 0xd8, 0x0a, 0xf1, 0xf4, 0x48, 0x2a, 0xe8,
+}
+
+var referenceSamples = []int16{
+	1000, 1025, 1000, 988, 1000, 1001, 1000, 1014, 1000, 977, 1000, 990, 1000, 1003, 1000, 1016, 1000, 979,
+	1000, 992, 1000, 1005, 1000, 1018, 1000, 981, 1000, 994, 1000, 1007, 1000, 1020, -200, 0, -197, -100, -188,
+	200, -173, -300, -152, 400, -125, -500, -92, 600, -53, -700, -8, 800, 43, -900, 100, 1000, 163, -1100, 232,
+	1200, 307, -1300, 388, 1400, 475, -1500,
+}
+
+func TestDecodeReference(t *testing.T) {
+	wav, err := Decode(bytes.NewReader(referenceStream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wav.Codec().SampleRate != 44_100 || wav.Codec().BitRate != 16 || wav.Channels() != 2 {
+		t.Errorf("decoded %s of %d channels", wav.Codec().Preset(), wav.Channels())
+	}
+	want := make([]byte, 0, 2*len(referenceSamples))
+	for _, s := range referenceSamples {
+		want = binary.LittleEndian.AppendUint16(want, uint16(s))
+	}
+	if !bytes.Equal(wav.Data(), want) {
+		t.Errorf("samples = %v, want %v", wav.Data(), want)
+	}
+}
+
+func TestDecodeLibFlac(t *testing.T) {
+	// 243749.flac of freesound.org (public domain) encoded by libFLAC 1.3.0: 24-bit mono at 8 kHz
+	file, err := os.ReadFile("testdata/243749.flac")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewDecoder(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the MD5 signature of the stream is verified at the end
+	var pcm []byte
+	for {
+		frame, err := d.ReadFrame()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Fatal(err)
+			}
+			break
+		}
+		pcm = append(pcm, frame...)
+	}
+	if d.Codec().SampleRate != 8000 || d.Codec().BitRate != 24 || d.Channels() != 1 || len(pcm) != 3*402 {
+		t.Errorf("decoded %d bytes of %s of %d channels", len(pcm), d.Codec().Preset(), d.Channels())
+	}
+
+	// the stream encoded again has the same audio
+	wav := audiocodec.NewWav(d.Codec())
+	wav.Write(pcm)
+	encoded, err := Encode(wav)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d2, err := NewDecoder(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d2.StreamInfo().Md5 != d.StreamInfo().Md5 || d2.StreamInfo().TotalSamples != 402 {
+		t.Errorf("encoded MD5 % x, samples=%d, want % x", d2.StreamInfo().Md5, d2.StreamInfo().TotalSamples, d.StreamInfo().Md5)
+	}
+	decoded, err := Decode(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.Data(), pcm) {
+		t.Error("decoded PCM differs")
+	}
+}
+
+func TestDecodeCorrupted(t *testing.T) {
+	// a flipped bit of a residual breaks the CRC-16 of the frame
+	stream := append([]byte(nil), referenceStream...)
+	stream[len(stream)-10] ^= 0x01
+	if _, err := Decode(bytes.NewReader(stream)); !errors.Is(err, InvalidChecksum) {
+		t.Errorf("corrupted frame: %v, want %v", err, InvalidChecksum)
+	}
+
+	// the frames end before the total number of samples of STREAMINFO
+	stream = referenceStream[:len(referenceStream)-2]
+	if _, err := Decode(bytes.NewReader(stream)); err == nil {
+		t.Errorf("truncated stream is decoded")
+	}
+
+	if _, err := Decode(bytes.NewReader([]byte("RIFF"))); !errors.Is(err, InvalidStream) {
+		t.Errorf("not FLAC: %v, want %v", err, InvalidStream)
+	}
+}
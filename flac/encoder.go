@@ -0,0 +1,536 @@
+package flac
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"hash"
+	"io"
+	"math/bits"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+const (
+	DefaultBlockSize = 4096
+
+	maxChannels       = 8
+	maxPartitionOrder = 8
+	maxEncoderLpc     = 12
+	lpcPrecision      = 14
+)
+
+// Encoder compresses interleaved little-endian PCM into a native FLAC stream.
+// When the underlying writer is an io.WriteSeeker, Close rewrites STREAMINFO with the total sample count and the MD5 signature,
+// otherwise they are left unknown as allowed by the format.
+type Encoder struct {
+	w           io.Writer
+	start       int64
+	codec       *audiocodec.Codec
+	info        *StreamInfo
+	pending     []byte
+	samples     [][]int32
+	bw          bitWriter
+	md5         hash.Hash
+	md5buf      []byte
+	frameNumber uint64
+	closed      bool
+}
+
+// NewEncoder writes the stream marker and STREAMINFO, 8, 16 and 24-bit linear PCM of up to 8 channels is supported.
+func NewEncoder(w io.Writer, codec *audiocodec.Codec, channels int) (*Encoder, error) {
+	if codec.Name != audiocodec.Pcm {
+		return nil, fmt.Errorf("%w: %s", NotSupportedCodec, codec.Name)
+	}
+	if codec.BitRate != 8 && codec.BitRate != 16 && codec.BitRate != 24 {
+		return nil, fmt.Errorf("not supported bit rate: %d", codec.BitRate)
+	}
+	if channels < 1 || channels > maxChannels {
+		return nil, fmt.Errorf("%w: %d", NotSupportedChannels, channels)
+	}
+
+	e := &Encoder{
+		w:     w,
+		codec: codec,
+		info: &StreamInfo{
+			MinBlockSize:  DefaultBlockSize,
+			MaxBlockSize:  DefaultBlockSize,
+			SampleRate:    codec.SampleRate,
+			Channels:      channels,
+			BitsPerSample: codec.BitRate,
+		},
+		samples: make([][]int32, channels),
+		md5:     md5.New(),
+	}
+	if s, ok := w.(io.Seeker); ok {
+		start, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		e.start = start
+	}
+
+	header := appendBlockHeader([]byte("fLaC"), BlockStreamInfo, true, streamInfoSize)
+	header = append(header, e.info.Bytes()...)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *Encoder) Codec() *audiocodec.Codec {
+	return e.codec
+}
+
+// StreamInfo returns the stream parameters, the sample count and the MD5 signature are final once the encoder is closed.
+func (e *Encoder) StreamInfo() *StreamInfo {
+	return e.info
+}
+
+// Write buffers the PCM and encodes every complete block.
+func (e *Encoder) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, EncoderIsClosed
+	}
+
+	e.pending = append(e.pending, p...)
+	blockBytes := DefaultBlockSize * e.codec.SampleSize() * e.info.Channels
+	offset := 0
+	for len(e.pending)-offset >= blockBytes {
+		if err := e.encodeBlock(e.pending[offset : offset+blockBytes]); err != nil {
+			return 0, err
+		}
+		offset += blockBytes
+	}
+	e.pending = e.pending[:copy(e.pending, e.pending[offset:])]
+
+	return len(p), nil
+}
+
+// Close encodes the remaining samples as the last, possibly shorter, block.
+func (e *Encoder) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+
+	frameBytes := e.codec.SampleSize() * e.info.Channels
+	if rest := len(e.pending) / frameBytes * frameBytes; rest > 0 {
+		if err := e.encodeBlock(e.pending[:rest]); err != nil {
+			return err
+		}
+	}
+	e.pending = nil
+	copy(e.info.Md5[:], e.md5.Sum(nil))
+
+	ws, ok := e.w.(io.WriteSeeker)
+	if !ok {
+		return nil
+	}
+	end, err := ws.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err = ws.Seek(e.start+8, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err = ws.Write(e.info.Bytes()); err != nil {
+		return err
+	}
+	_, err = ws.Seek(end, io.SeekStart)
+	return err
+}
+
+func (e *Encoder) encodeBlock(pcm []byte) error {
+	channels := e.info.Channels
+	sampleSize := e.codec.SampleSize()
+	blockSize := len(pcm) / sampleSize / channels
+	for ch := range e.samples {
+		if cap(e.samples[ch]) < blockSize {
+			e.samples[ch] = make([]int32, blockSize)
+		}
+		e.samples[ch] = e.samples[ch][:blockSize]
+	}
+
+	e.md5buf = e.md5buf[:0]
+	for i := 0; i < blockSize; i++ {
+		for ch := 0; ch < channels; ch++ {
+			b := pcm[(i*channels+ch)*sampleSize:]
+			var s int32
+			switch sampleSize {
+			case 1:
+				s = int32(b[0]) - 128
+				e.md5buf = append(e.md5buf, byte(s))
+			case 2:
+				s = int32(int16(uint16(b[0]) | uint16(b[1])<<8))
+				e.md5buf = append(e.md5buf, b[0], b[1])
+			default:
+				s = int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+				e.md5buf = append(e.md5buf, b[0], b[1], b[2])
+			}
+			e.samples[ch][i] = s
+		}
+	}
+	e.md5.Write(e.md5buf)
+
+	frame := e.encodeFrame(blockSize)
+	if _, err := e.w.Write(frame); err != nil {
+		return err
+	}
+
+	size := len(frame)
+	if e.info.MinFrameSize == 0 || size < e.info.MinFrameSize {
+		e.info.MinFrameSize = size
+	}
+	e.info.MaxFrameSize = max(e.info.MaxFrameSize, size)
+	e.info.TotalSamples += int64(blockSize)
+	e.frameNumber++
+	return nil
+}
+
+func (e *Encoder) encodeFrame(blockSize int) []byte {
+	bps := uint(e.info.BitsPerSample)
+	channelAssignment := len(e.samples) - 1
+	var subframes []*subframe
+	if len(e.samples) == 2 {
+		left, right := e.samples[0], e.samples[1]
+		mid := make([]int32, blockSize)
+		side := make([]int32, blockSize)
+		for i := range left {
+			mid[i] = (left[i] + right[i]) >> 1
+			side[i] = left[i] - right[i]
+		}
+		l, r := planSubframe(left, bps), planSubframe(right, bps)
+		m, s := planSubframe(mid, bps), planSubframe(side, bps+1)
+		subframes = []*subframe{l, r}
+		best := l.bits + r.bits
+		if l.bits+s.bits < best {
+			best, channelAssignment, subframes = l.bits+s.bits, channelLeftSide, []*subframe{l, s}
+		}
+		if s.bits+r.bits < best {
+			best, channelAssignment, subframes = s.bits+r.bits, channelRightSide, []*subframe{s, r}
+		}
+		if m.bits+s.bits < best {
+			channelAssignment, subframes = channelMidSide, []*subframe{m, s}
+		}
+	} else {
+		for _, samples := range e.samples {
+			subframes = append(subframes, planSubframe(samples, bps))
+		}
+	}
+
+	bw := &e.bw
+	bw.reset()
+	bw.writeBits(0x3FFE, 14)
+	bw.writeBits(0, 2) // reserved bit and fixed block size strategy
+	blockSizeCode, blockSizeBits := blockSizeCode(blockSize)
+	sampleRateCode, sampleRateBits, sampleRateValue := sampleRateCode(e.info.SampleRate)
+	bw.writeBits(uint64(blockSizeCode), 4)
+	bw.writeBits(uint64(sampleRateCode), 4)
+	bw.writeBits(uint64(channelAssignment), 4)
+	bw.writeBits(uint64(sampleSizeCode(int(bps))), 3)
+	bw.writeBits(0, 1)
+	writeUtf8Number(bw, e.frameNumber)
+	if blockSizeBits > 0 {
+		bw.writeBits(uint64(blockSize-1), blockSizeBits)
+	}
+	if sampleRateBits > 0 {
+		bw.writeBits(uint64(sampleRateValue), sampleRateBits)
+	}
+	bw.writeBits(uint64(crc8(0, bw.buf)), 8)
+
+	for _, sf := range subframes {
+		sf.write(bw)
+	}
+
+	bw.align()
+	crc := crc16(0, bw.buf)
+	bw.writeBits(uint64(crc), 16)
+
+	return bytes.Clone(bw.buf)
+}
+
+func blockSizeCode(blockSize int) (code int, extraBits uint) {
+	switch {
+	case blockSize == 192:
+		return 1, 0
+	case blockSize >= 576 && blockSize <= 4608 && blockSize%576 == 0 && bits.OnesCount(uint(blockSize/576)) == 1:
+		return 2 + bits.TrailingZeros(uint(blockSize/576)), 0
+	case blockSize >= 256 && blockSize <= 32768 && bits.OnesCount(uint(blockSize)) == 1:
+		return 8 + bits.TrailingZeros(uint(blockSize/256)), 0
+	case blockSize <= 256:
+		return 6, 8
+	default:
+		return 7, 16
+	}
+}
+
+func sampleRateCode(sampleRate int) (code int, extraBits uint, value int) {
+	for i := 1; i < len(sampleRates); i++ {
+		if sampleRates[i] == sampleRate {
+			return i, 0, 0
+		}
+	}
+	switch {
+	case sampleRate%1000 == 0 && sampleRate/1000 <= 0xFF:
+		return 12, 8, sampleRate / 1000
+	case sampleRate <= 0xFFFF:
+		return 13, 16, sampleRate
+	case sampleRate%10 == 0 && sampleRate/10 <= 0xFFFF:
+		return 14, 16, sampleRate / 10
+	}
+	return 0, 0, 0
+}
+
+func sampleSizeCode(bps int) int {
+	for i, size := range sampleSizes {
+		if size == bps {
+			return i
+		}
+	}
+	return 0
+}
+
+// writeUtf8Number writes the frame number coded like an UTF-8 character extended up to 36 bits
+func writeUtf8Number(bw *bitWriter, v uint64) {
+	if v < 0x80 {
+		bw.writeBits(v, 8)
+		return
+	}
+	n := 2
+	for v >= 1<<(5*n+1) {
+		n++
+	}
+	bw.writeBits(uint64(0xFF<<(8-n)&0xFF)|v>>(6*(n-1)), 8)
+	for i := n - 2; i >= 0; i-- {
+		bw.writeBits(0x80|v>>(6*i)&0x3F, 8)
+	}
+}
+
+const (
+	subframeConstant = iota
+	subframeVerbatim
+	subframeFixed
+	subframeLpc
+)
+
+type subframe struct {
+	kind         int
+	bps          uint
+	samples      []int32
+	coefficients []int32
+	shift        uint
+	residual     []int32
+	rice         riceCoding
+	bits         int
+}
+
+// planSubframe chooses the cheapest of the constant, verbatim, fixed and linear predictor encodings of the samples
+func planSubframe(samples []int32, bps uint) *subframe {
+	n := len(samples)
+	constant := true
+	for _, s := range samples[1:] {
+		if s != samples[0] {
+			constant = false
+			break
+		}
+	}
+	if constant {
+		return &subframe{kind: subframeConstant, bps: bps, samples: samples, bits: 8 + int(bps)}
+	}
+
+	best := &subframe{kind: subframeVerbatim, bps: bps, samples: samples, bits: 8 + n*int(bps)}
+	residual := make([]int32, n)
+	try := func(kind int, coefficients []int32, shift uint, overhead int) {
+		order := len(coefficients)
+		if !lpcResidual(residual, samples, coefficients, shift) {
+			return
+		}
+		rice := planRice(residual[:n-order], n, order)
+		if size := 8 + order*int(bps) + overhead + rice.bits; size < best.bits {
+			best = &subframe{
+				kind:         kind,
+				bps:          bps,
+				samples:      samples,
+				coefficients: coefficients,
+				shift:        shift,
+				residual:     append([]int32(nil), residual[:n-order]...),
+				rice:         rice,
+				bits:         size,
+			}
+		}
+	}
+
+	for order := 0; order <= maxFixedOrder && order < n; order++ {
+		try(subframeFixed, fixedCoefficients[order], 0, 0)
+	}
+	if n > maxEncoderLpc*2 {
+		for _, lpc := range lpcCoefficients(samples, maxEncoderLpc) {
+			coefficients, shift := quantizeLpc(lpc, lpcPrecision)
+			if coefficients != nil {
+				try(subframeLpc, coefficients, shift, 4+5+len(coefficients)*lpcPrecision)
+			}
+		}
+	}
+
+	return best
+}
+
+func (sf *subframe) write(bw *bitWriter) {
+	order := len(sf.coefficients)
+	switch sf.kind {
+	case subframeConstant:
+		bw.writeBits(0, 8)
+		bw.writeSigned(sf.samples[0], sf.bps)
+		return
+	case subframeVerbatim:
+		bw.writeBits(1<<1, 8)
+		for _, s := range sf.samples {
+			bw.writeSigned(s, sf.bps)
+		}
+		return
+	case subframeFixed:
+		bw.writeBits(uint64(8+order)<<1, 8)
+	case subframeLpc:
+		bw.writeBits(uint64(32+order-1)<<1, 8)
+	}
+
+	for _, s := range sf.samples[:order] {
+		bw.writeSigned(s, sf.bps)
+	}
+	if sf.kind == subframeLpc {
+		bw.writeBits(lpcPrecision-1, 4)
+		bw.writeBits(uint64(sf.shift), 5)
+		for _, c := range sf.coefficients {
+			bw.writeSigned(c, lpcPrecision)
+		}
+	}
+	sf.rice.write(bw, sf.residual)
+}
+
+// riceCoding describes the partitioned rice coding of a residual
+type riceCoding struct {
+	partitionOrder int
+	partitionSize  int
+	order          int
+	params         []int
+	paramBits      uint
+	bits           int
+}
+
+// planRice chooses the partition order and the rice parameters using the sums of the zigzag coded residuals
+func planRice(residual []int32, blockSize int, order int) riceCoding {
+	maxOrder := 0
+	for maxOrder < maxPartitionOrder && blockSize%(2<<maxOrder) == 0 && blockSize>>(maxOrder+1) > order {
+		maxOrder++
+	}
+
+	sums := make([]uint64, 1<<maxOrder)
+	partitionSize := blockSize >> maxOrder
+	i := 0
+	for p := range sums {
+		n := partitionSize
+		if p == 0 {
+			n -= order
+		}
+		for end := i + n; i < end; i++ {
+			sums[p] += uint64(zigzag(residual[i]))
+		}
+	}
+
+	var best riceCoding
+	for po := maxOrder; po >= 0; po-- {
+		partitionSize = blockSize >> po
+		coding := riceCoding{
+			partitionOrder: po,
+			partitionSize:  partitionSize,
+			order:          order,
+			params:         make([]int, len(sums)),
+			paramBits:      4,
+		}
+		size := 0
+		for p, sum := range sums {
+			n := partitionSize
+			if p == 0 {
+				n -= order
+			}
+			k, cost := riceParam(sum, n)
+			coding.params[p] = k
+			if k >= riceEscape4 {
+				coding.paramBits = 5
+			}
+			size += cost
+		}
+		coding.bits = 2 + 4 + size + len(sums)*int(coding.paramBits)
+		if po == maxOrder || coding.bits < best.bits {
+			best = coding
+		}
+
+		for p := 0; p < len(sums)/2; p++ {
+			sums[p] = sums[2*p] + sums[2*p+1]
+		}
+		sums = sums[:len(sums)/2]
+	}
+	return best
+}
+
+// riceParam estimates the optimal parameter for n values with the given sum and the size of their coding
+func riceParam(sum uint64, n int) (k int, size int) {
+	if n == 0 {
+		return 0, 0
+	}
+	estimate := 0
+	if mean := sum / uint64(n); mean > 0 {
+		estimate = bits.Len64(mean) - 1
+	}
+	size = -1
+	for candidate := max(estimate-1, 0); candidate <= min(estimate+1, maxRiceParam); candidate++ {
+		cost := n*(candidate+1) + int(sum>>candidate)
+		if size < 0 || cost < size {
+			k, size = candidate, cost
+		}
+	}
+	return k, size
+}
+
+func (rc riceCoding) write(bw *bitWriter, residual []int32) {
+	if rc.paramBits == 5 {
+		bw.writeBits(1, 2)
+	} else {
+		bw.writeBits(0, 2)
+	}
+	bw.writeBits(uint64(rc.partitionOrder), 4)
+
+	i := 0
+	for p, k := range rc.params {
+		n := rc.partitionSize
+		if p == 0 {
+			n -= rc.order
+		}
+		bw.writeBits(uint64(k), rc.paramBits)
+		for end := i + n; i < end; i++ {
+			u := zigzag(residual[i])
+			bw.writeUnary(uint64(u >> k))
+			bw.writeBits(uint64(u), uint(k))
+		}
+	}
+}
+
+// Encode compresses the whole WAV into a FLAC stream.
+func Encode(wav *audiocodec.Wav) ([]byte, error) {
+	var buf bytes.Buffer
+	e, err := NewEncoder(&buf, wav.Codec(), wav.Channels())
+	if err != nil {
+		return nil, err
+	}
+	if _, err = e.Write(wav.Data()); err != nil {
+		return nil, err
+	}
+	if err = e.Close(); err != nil {
+		return nil, err
+	}
+
+	b := buf.Bytes()
+	copy(b[8:8+streamInfoSize], e.info.Bytes())
+	return b, nil
+}
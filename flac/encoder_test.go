@@ -0,0 +1,165 @@
+package flac
+
+import (
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+// testPcm returns n sample frames of noisy tones with a different tone per channel, a silent and a full-scale part
+func testPcm(codec *audiocodec.Codec, channels int, n int) []byte {
+	r := rand.New(rand.NewSource(1))
+	size := codec.SampleSize()
+	full := float64(int64(1)<<(8*size-1) - 1)
+	pcm := make([]byte, 0, size*channels*n)
+	for i := 0; i < n; i++ {
+		for ch := 0; ch < channels; ch++ {
+			var v float64
+			switch {
+			case i < n/4:
+				v = 0.5*math.Sin(float64(i)*0.01*float64(ch+1)) + 0.01*r.NormFloat64()
+			case i < n/2:
+				v = 0
+			case i < 3*n/4:
+				v = float64(r.Intn(3) - 1)
+			default:
+				v = r.Float64()*2 - 1
+			}
+			s := int64(max(min(v*full, full), -full-1))
+			if size == 1 {
+				// 8-bit PCM is unsigned
+				s += 128
+			}
+			for b := 0; b < size; b++ {
+				pcm = append(pcm, byte(s>>(8*b)))
+			}
+		}
+	}
+	return pcm
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, bits := range []int{8, 16, 24} {
+		for _, channels := range []int{1, 2, 6} {
+			codec := audiocodec.NewPcmCodec(48_000, bits)
+			// the last block is shorter
+			pcm := testPcm(codec, channels, 3*DefaultBlockSize+100)
+			wav := audiocodec.NewWavWithChannels(codec, channels)
+			wav.Write(pcm)
+
+			stream, err := Encode(wav)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(stream) >= len(pcm) {
+				t.Errorf("%d bits, %d channels: %d bytes of %d are not compressed", bits, channels, len(stream), len(pcm))
+			}
+			decoded, err := Decode(bytes.NewReader(stream))
+			if err != nil {
+				t.Fatalf("%d bits, %d channels: %v", bits, channels, err)
+			}
+			if !decoded.Codec().IsEqual(codec) || decoded.Channels() != channels || !bytes.Equal(decoded.Data(), pcm) {
+				t.Errorf("%d bits, %d channels: decoded %s of %d channels differs", bits, channels, decoded.Codec().Preset(), decoded.Channels())
+			}
+		}
+	}
+}
+
+func TestStreamInfo(t *testing.T) {
+	codec := audiocodec.NewPcmCodec(44_100, 16)
+	pcm := testPcm(codec, 2, 10_000)
+	wav := audiocodec.NewWavWithChannels(codec, 2)
+	wav.Write(pcm)
+	stream, err := Encode(wav)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := NewDecoder(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := d.StreamInfo()
+	if info.SampleRate != 44_100 || info.Channels != 2 || info.BitsPerSample != 16 || info.TotalSamples != 10_000 {
+		t.Errorf("stream info = %+v", info)
+	}
+	// the MD5 signature of 16-bit samples is the one of the little-endian PCM
+	if info.Md5 != md5.Sum(pcm) {
+		t.Errorf("MD5 = %x, want %x", info.Md5, md5.Sum(pcm))
+	}
+	if info.Duration().Milliseconds() != 226 {
+		t.Errorf("duration = %s", info.Duration())
+	}
+}
+
+func TestEncoderWrite(t *testing.T) {
+	codec := audiocodec.NewPcmCodec(8_000, 16)
+	pcm := testPcm(codec, 1, 5_000)
+	wav := audiocodec.NewWav(codec)
+	wav.Write(pcm)
+	want, err := Encode(wav)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a stream written in parts by an encoder of a seekable writer is the same
+	var f memFile
+	e, err := NewEncoder(&f, codec, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, part := range [][]byte{pcm[:3], pcm[3:5_000], pcm[5_000:]} {
+		if _, err = e.Write(part); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(f.b, want) {
+		t.Errorf("stream written in parts differs")
+	}
+	if _, err = e.Write(pcm); !errors.Is(err, EncoderIsClosed) {
+		t.Errorf("Write after Close: %v, want %v", err, EncoderIsClosed)
+	}
+
+	if _, err = NewEncoder(&f, audiocodec.PcmA8kHz8bCodec, 1); !errors.Is(err, NotSupportedCodec) {
+		t.Errorf("A-law: %v, want %v", err, NotSupportedCodec)
+	}
+	if _, err = NewEncoder(&f, codec, 9); !errors.Is(err, NotSupportedChannels) {
+		t.Errorf("9 channels: %v, want %v", err, NotSupportedChannels)
+	}
+}
+
+// memFile is an in-memory io.WriteSeeker
+type memFile struct {
+	b   []byte
+	pos int
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	if end := f.pos + len(p); end > len(f.b) {
+		f.b = append(f.b, make([]byte, end-len(f.b))...)
+	}
+	n := copy(f.b[f.pos:], p)
+	f.pos += n
+	return n, nil
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		f.pos = int(offset)
+	case io.SeekCurrent:
+		f.pos += int(offset)
+	case io.SeekEnd:
+		f.pos = len(f.b) + int(offset)
+	}
+	return int64(f.pos), nil
+}
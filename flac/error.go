@@ -0,0 +1,14 @@
+package flac
+
+import "errors"
+
+var (
+	InvalidStream        = errors.New("invalid FLAC stream")
+	InvalidStreamInfo    = errors.New("invalid STREAMINFO block")
+	InvalidFrame         = errors.New("invalid FLAC frame")
+	InvalidSubframe      = errors.New("invalid FLAC subframe")
+	InvalidChecksum      = errors.New("invalid FLAC checksum")
+	NotSupportedCodec    = errors.New("not supported codec")
+	NotSupportedChannels = errors.New("not supported number of channels")
+	EncoderIsClosed      = errors.New("encoder is closed")
+)
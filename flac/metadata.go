@@ -0,0 +1,97 @@
+package flac
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+const (
+	BlockStreamInfo    byte = 0
+	BlockPadding       byte = 1
+	BlockApplication   byte = 2
+	BlockSeekTable     byte = 3
+	BlockVorbisComment byte = 4
+	BlockCueSheet      byte = 5
+	BlockPicture       byte = 6
+
+	streamInfoSize = 34
+)
+
+// StreamInfo is the mandatory first metadata block of a FLAC stream:
+//
+//	Размер (бит)	Описание
+//	16				Minimum block size in samples
+//	16				Maximum block size in samples
+//	24				Minimum frame size in bytes, 0 if unknown
+//	24				Maximum frame size in bytes, 0 if unknown
+//	20				Sample rate
+//	3				Number of channels - 1
+//	5				Bits per sample - 1
+//	36				Total samples per channel, 0 if unknown
+//	128				MD5 of the unencoded audio data
+type StreamInfo struct {
+	MinBlockSize  int
+	MaxBlockSize  int
+	MinFrameSize  int
+	MaxFrameSize  int
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+	TotalSamples  int64
+	Md5           [16]byte
+}
+
+func ParseStreamInfo(b []byte) (*StreamInfo, error) {
+	if len(b) < streamInfoSize {
+		return nil, InvalidStreamInfo
+	}
+
+	v := binary.BigEndian.Uint64(b[10:18])
+	si := &StreamInfo{
+		MinBlockSize:  int(binary.BigEndian.Uint16(b[0:2])),
+		MaxBlockSize:  int(binary.BigEndian.Uint16(b[2:4])),
+		MinFrameSize:  int(b[4])<<16 | int(b[5])<<8 | int(b[6]),
+		MaxFrameSize:  int(b[7])<<16 | int(b[8])<<8 | int(b[9]),
+		SampleRate:    int(v >> 44),
+		Channels:      int(v>>41&0x07) + 1,
+		BitsPerSample: int(v>>36&0x1F) + 1,
+		TotalSamples:  int64(v & (1<<36 - 1)),
+	}
+	copy(si.Md5[:], b[18:34])
+
+	if si.SampleRate == 0 || si.MaxBlockSize < 16 || si.BitsPerSample < 4 {
+		return nil, InvalidStreamInfo
+	}
+	return si, nil
+}
+
+func (si *StreamInfo) Bytes() []byte {
+	b := make([]byte, streamInfoSize)
+	binary.BigEndian.PutUint16(b[0:2], uint16(si.MinBlockSize))
+	binary.BigEndian.PutUint16(b[2:4], uint16(si.MaxBlockSize))
+	b[4], b[5], b[6] = byte(si.MinFrameSize>>16), byte(si.MinFrameSize>>8), byte(si.MinFrameSize)
+	b[7], b[8], b[9] = byte(si.MaxFrameSize>>16), byte(si.MaxFrameSize>>8), byte(si.MaxFrameSize)
+	v := uint64(si.SampleRate)<<44 | uint64(si.Channels-1)<<41 | uint64(si.BitsPerSample-1)<<36 | uint64(si.TotalSamples)&(1<<36-1)
+	binary.BigEndian.PutUint64(b[10:18], v)
+	copy(b[18:34], si.Md5[:])
+	return b
+}
+
+// Codec returns the PCM codec of the decoded samples, the sample size is rounded up to whole bytes.
+func (si *StreamInfo) Codec() *audiocodec.Codec {
+	return audiocodec.NewPcmCodec(si.SampleRate, (si.BitsPerSample+7)/8*8)
+}
+
+func (si *StreamInfo) Duration() time.Duration {
+	return time.Duration(si.TotalSamples) * time.Second / time.Duration(si.SampleRate)
+}
+
+// appendBlockHeader appends the 4-byte metadata block header: last-block flag, block type and 24-bit length
+func appendBlockHeader(b []byte, blockType byte, last bool, size int) []byte {
+	if last {
+		blockType |= 0x80
+	}
+	return append(b, blockType, byte(size>>16), byte(size>>8), byte(size))
+}
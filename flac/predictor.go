@@ -0,0 +1,129 @@
+package flac
+
+import "math"
+
+const (
+	maxFixedOrder = 4
+	maxLpcOrder   = 32
+	maxRiceParam  = 30
+	// riceEscape4 and riceEscape5 mark a partition of unencoded residuals for 4- and 5-bit rice parameters
+	riceEscape4 = 15
+	riceEscape5 = 31
+)
+
+var fixedCoefficients = [maxFixedOrder + 1][]int32{
+	{},
+	{1},
+	{2, -1},
+	{3, -3, 1},
+	{4, -6, 4, -1},
+}
+
+// restoreLpc reconstructs the samples in place from the warm-up samples followed by residuals
+func restoreLpc(samples []int32, coefficients []int32, shift uint) {
+	order := len(coefficients)
+	for i := order; i < len(samples); i++ {
+		var sum int64
+		for j, c := range coefficients {
+			sum += int64(c) * int64(samples[i-j-1])
+		}
+		samples[i] += int32(sum >> shift)
+	}
+}
+
+// lpcResidual computes the prediction error of the samples, ok is false if a residual does not fit into 32 bits
+func lpcResidual(dst []int32, samples []int32, coefficients []int32, shift uint) (ok bool) {
+	order := len(coefficients)
+	for i := order; i < len(samples); i++ {
+		var sum int64
+		for j, c := range coefficients {
+			sum += int64(c) * int64(samples[i-j-1])
+		}
+		r := int64(samples[i]) - sum>>shift
+		if r > math.MaxInt32 || r <= math.MinInt32 {
+			return false
+		}
+		dst[i-order] = int32(r)
+	}
+	return true
+}
+
+// lpcCoefficients estimates predictor coefficients of all orders up to maxOrder using a Welch window and the Levinson-Durbin recursion
+func lpcCoefficients(samples []int32, maxOrder int) [][]float64 {
+	n := len(samples)
+	windowed := make([]float64, n)
+	for i, s := range samples {
+		x := 2*float64(i)/float64(n-1) - 1
+		windowed[i] = float64(s) * (1 - x*x)
+	}
+
+	autocorrelation := make([]float64, maxOrder+1)
+	for lag := 0; lag <= maxOrder; lag++ {
+		var sum float64
+		for i := lag; i < n; i++ {
+			sum += windowed[i] * windowed[i-lag]
+		}
+		autocorrelation[lag] = sum
+	}
+	if autocorrelation[0] == 0 {
+		return nil
+	}
+
+	result := make([][]float64, 0, maxOrder)
+	lpc := make([]float64, maxOrder)
+	tmp := make([]float64, maxOrder)
+	err := autocorrelation[0]
+	for i := 0; i < maxOrder; i++ {
+		k := autocorrelation[i+1]
+		for j := 0; j < i; j++ {
+			k -= lpc[j] * autocorrelation[i-j]
+		}
+		k /= err
+		copy(tmp, lpc[:i])
+		for j := 0; j < i; j++ {
+			lpc[j] = tmp[j] - k*tmp[i-j-1]
+		}
+		lpc[i] = k
+		err *= 1 - k*k
+		result = append(result, append([]float64(nil), lpc[:i+1]...))
+		if err <= 0 {
+			break
+		}
+	}
+	return result
+}
+
+// quantizeLpc converts the coefficients to integers of the given precision returning them with the shift
+func quantizeLpc(lpc []float64, precision uint) ([]int32, uint) {
+	var cmax float64
+	for _, c := range lpc {
+		cmax = max(cmax, math.Abs(c))
+	}
+	if cmax == 0 {
+		return nil, 0
+	}
+
+	_, exp := math.Frexp(cmax)
+	shift := int(precision) - 1 - exp
+	shift = min(max(shift, 0), 15)
+
+	limit := int32(1)<<(precision-1) - 1
+	q := make([]int32, len(lpc))
+	var e float64
+	for i, c := range lpc {
+		e += c * float64(int(1)<<shift)
+		v := int32(math.Round(e))
+		v = min(max(v, -limit-1), limit)
+		q[i] = v
+		e -= float64(v)
+	}
+	return q, uint(shift)
+}
+
+func zigzag(v int32) uint32 {
+	return uint32(v<<1) ^ uint32(v>>31)
+}
+
+func unzigzag(u uint32) int32 {
+	return int32(u>>1) ^ -int32(u&1)
+}
@@ -0,0 +1,158 @@
+package g711
+
+const (
+	alawMask = 0x55
+	ulawBias = 0x84
+	ulawClip = 8159
+)
+
+var (
+	alawSegmentEnd = [8]int{0x1F, 0x3F, 0x7F, 0xFF, 0x1FF, 0x3FF, 0x7FF, 0xFFF}
+	ulawSegmentEnd = [8]int{0x3F, 0x7F, 0xFF, 0x1FF, 0x3FF, 0x7FF, 0xFFF, 0x1FFF}
+
+	alawToLinear [256]int16
+	ulawToLinear [256]int16
+)
+
+func init() {
+	for i := 0; i < 256; i++ {
+		alawToLinear[i] = decodeAlaw(byte(i))
+		ulawToLinear[i] = decodeUlaw(byte(i))
+	}
+}
+
+func EncodeAlaw(sample int16) byte {
+	var mask byte
+	v := int(sample) >> 3
+	if v >= 0 {
+		mask = 0xD5
+	} else {
+		mask = alawMask
+		v = -v - 1
+	}
+
+	seg := segment(v, &alawSegmentEnd)
+	if seg >= 8 {
+		return 0x7F ^ mask
+	}
+
+	a := byte(seg << 4)
+	if seg < 2 {
+		a |= byte(v>>1) & 0x0F
+	} else {
+		a |= byte(v>>seg) & 0x0F
+	}
+	return a ^ mask
+}
+
+func DecodeAlaw(a byte) int16 {
+	return alawToLinear[a]
+}
+
+func EncodeUlaw(sample int16) byte {
+	var mask byte
+	v := int(sample) >> 2
+	if v < 0 {
+		v = -v
+		mask = 0x7F
+	} else {
+		mask = 0xFF
+	}
+	if v > ulawClip {
+		v = ulawClip
+	}
+	v += ulawBias >> 2
+
+	seg := segment(v, &ulawSegmentEnd)
+	if seg >= 8 {
+		return 0x7F ^ mask
+	}
+
+	u := byte(seg<<4) | byte(v>>(seg+1))&0x0F
+	return u ^ mask
+}
+
+func DecodeUlaw(u byte) int16 {
+	return ulawToLinear[u]
+}
+
+// AlawToUlaw transcodes an A-law sample to μ-law without going through the linear representation twice.
+func AlawToUlaw(a byte) byte {
+	return EncodeUlaw(alawToLinear[a])
+}
+
+func UlawToAlaw(u byte) byte {
+	return EncodeAlaw(ulawToLinear[u])
+}
+
+// EncodeAlawFrame encodes 16-bit little-endian PCM into A-law, appending the result to dst.
+func EncodeAlawFrame(dst []byte, pcm []byte) []byte {
+	for i := 0; i+1 < len(pcm); i += 2 {
+		dst = append(dst, EncodeAlaw(int16(pcm[i])|int16(pcm[i+1])<<8))
+	}
+	return dst
+}
+
+// DecodeAlawFrame decodes A-law into 16-bit little-endian PCM, appending the result to dst.
+func DecodeAlawFrame(dst []byte, frame []byte) []byte {
+	for _, a := range frame {
+		v := alawToLinear[a]
+		dst = append(dst, byte(v), byte(v>>8))
+	}
+	return dst
+}
+
+// EncodeUlawFrame encodes 16-bit little-endian PCM into μ-law, appending the result to dst.
+func EncodeUlawFrame(dst []byte, pcm []byte) []byte {
+	for i := 0; i+1 < len(pcm); i += 2 {
+		dst = append(dst, EncodeUlaw(int16(pcm[i])|int16(pcm[i+1])<<8))
+	}
+	return dst
+}
+
+// DecodeUlawFrame decodes μ-law into 16-bit little-endian PCM, appending the result to dst.
+func DecodeUlawFrame(dst []byte, frame []byte) []byte {
+	for _, u := range frame {
+		v := ulawToLinear[u]
+		dst = append(dst, byte(v), byte(v>>8))
+	}
+	return dst
+}
+
+func decodeAlaw(a byte) int16 {
+	a ^= alawMask
+	t := int(a&0x0F) << 4
+	seg := int(a&0x70) >> 4
+	switch seg {
+	case 0:
+		t += 8
+	case 1:
+		t += 0x108
+	default:
+		t += 0x108
+		t <<= seg - 1
+	}
+	if a&0x80 != 0 {
+		return int16(t)
+	}
+	return int16(-t)
+}
+
+func decodeUlaw(u byte) int16 {
+	u = ^u
+	t := (int(u&0x0F) << 3) + ulawBias
+	t <<= (u & 0x70) >> 4
+	if u&0x80 != 0 {
+		return int16(ulawBias - t)
+	}
+	return int16(t - ulawBias)
+}
+
+func segment(v int, table *[8]int) int {
+	for i, end := range table {
+		if v <= end {
+			return i
+		}
+	}
+	return len(table)
+}
@@ -0,0 +1,118 @@
+package g711
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+// alawDecoded and ulawDecoded are the values of all codes decoded by the audioop module of Python
+var alawDecoded = [256]int16{
+	-5504, -5248, -6016, -5760, -4480, -4224, -4992, -4736, -7552, -7296, -8064, -7808, -6528, -6272, -7040, -6784,
+	-2752, -2624, -3008, -2880, -2240, -2112, -2496, -2368, -3776, -3648, -4032, -3904, -3264, -3136, -3520, -3392,
+	-22016, -20992, -24064, -23040, -17920, -16896, -19968, -18944, -30208, -29184, -32256, -31232, -26112, -25088, -28160, -27136,
+	-11008, -10496, -12032, -11520, -8960, -8448, -9984, -9472, -15104, -14592, -16128, -15616, -13056, -12544, -14080, -13568,
+	-344, -328, -376, -360, -280, -264, -312, -296, -472, -456, -504, -488, -408, -392, -440, -424,
+	-88, -72, -120, -104, -24, -8, -56, -40, -216, -200, -248, -232, -152, -136, -184, -168,
+	-1376, -1312, -1504, -1440, -1120, -1056, -1248, -1184, -1888, -1824, -2016, -1952, -1632, -1568, -1760, -1696,
+	-688, -656, -752, -720, -560, -528, -624, -592, -944, -912, -1008, -976, -816, -784, -880, -848,
+	5504, 5248, 6016, 5760, 4480, 4224, 4992, 4736, 7552, 7296, 8064, 7808, 6528, 6272, 7040, 6784,
+	2752, 2624, 3008, 2880, 2240, 2112, 2496, 2368, 3776, 3648, 4032, 3904, 3264, 3136, 3520, 3392,
+	22016, 20992, 24064, 23040, 17920, 16896, 19968, 18944, 30208, 29184, 32256, 31232, 26112, 25088, 28160, 27136,
+	11008, 10496, 12032, 11520, 8960, 8448, 9984, 9472, 15104, 14592, 16128, 15616, 13056, 12544, 14080, 13568,
+	344, 328, 376, 360, 280, 264, 312, 296, 472, 456, 504, 488, 408, 392, 440, 424,
+	88, 72, 120, 104, 24, 8, 56, 40, 216, 200, 248, 232, 152, 136, 184, 168,
+	1376, 1312, 1504, 1440, 1120, 1056, 1248, 1184, 1888, 1824, 2016, 1952, 1632, 1568, 1760, 1696,
+	688, 656, 752, 720, 560, 528, 624, 592, 944, 912, 1008, 976, 816, 784, 880, 848,
+}
+
+var ulawDecoded = [256]int16{
+	-32124, -31100, -30076, -29052, -28028, -27004, -25980, -24956, -23932, -22908, -21884, -20860, -19836, -18812, -17788, -16764,
+	-15996, -15484, -14972, -14460, -13948, -13436, -12924, -12412, -11900, -11388, -10876, -10364, -9852, -9340, -8828, -8316,
+	-7932, -7676, -7420, -7164, -6908, -6652, -6396, -6140, -5884, -5628, -5372, -5116, -4860, -4604, -4348, -4092,
+	-3900, -3772, -3644, -3516, -3388, -3260, -3132, -3004, -2876, -2748, -2620, -2492, -2364, -2236, -2108, -1980,
+	-1884, -1820, -1756, -1692, -1628, -1564, -1500, -1436, -1372, -1308, -1244, -1180, -1116, -1052, -988, -924,
+	-876, -844, -812, -780, -748, -716, -684, -652, -620, -588, -556, -524, -492, -460, -428, -396,
+	-372, -356, -340, -324, -308, -292, -276, -260, -244, -228, -212, -196, -180, -164, -148, -132,
+	-120, -112, -104, -96, -88, -80, -72, -64, -56, -48, -40, -32, -24, -16, -8, 0,
+	32124, 31100, 30076, 29052, 28028, 27004, 25980, 24956, 23932, 22908, 21884, 20860, 19836, 18812, 17788, 16764,
+	15996, 15484, 14972, 14460, 13948, 13436, 12924, 12412, 11900, 11388, 10876, 10364, 9852, 9340, 8828, 8316,
+	7932, 7676, 7420, 7164, 6908, 6652, 6396, 6140, 5884, 5628, 5372, 5116, 4860, 4604, 4348, 4092,
+	3900, 3772, 3644, 3516, 3388, 3260, 3132, 3004, 2876, 2748, 2620, 2492, 2364, 2236, 2108, 1980,
+	1884, 1820, 1756, 1692, 1628, 1564, 1500, 1436, 1372, 1308, 1244, 1180, 1116, 1052, 988, 924,
+	876, 844, 812, 780, 748, 716, 684, 652, 620, 588, 556, 524, 492, 460, 428, 396,
+	372, 356, 340, 324, 308, 292, 276, 260, 244, 228, 212, 196, 180, 164, 148, 132,
+	120, 112, 104, 96, 88, 80, 72, 64, 56, 48, 40, 32, 24, 16, 8, 0,
+}
+
+// digests of A-law and μ-law of all 16-bit values from -32768 up to 32767 encoded by the audioop module of Python
+const (
+	alawDigest = "38488f6fd710f4686360edc4d38639f96c491595ef93f8eb8d62d5e07ca6ce7b"
+	ulawDigest = "81d633c9e6972a18c74a58720b96cb8ca0bdd096d4060b646dd708c3b846019a"
+)
+
+func TestDecode(t *testing.T) {
+	for code := range 256 {
+		if v := DecodeAlaw(byte(code)); v != alawDecoded[code] {
+			t.Errorf("DecodeAlaw(%#02x) = %d, want %d", code, v, alawDecoded[code])
+		}
+		if v := DecodeUlaw(byte(code)); v != ulawDecoded[code] {
+			t.Errorf("DecodeUlaw(%#02x) = %d, want %d", code, v, ulawDecoded[code])
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	samples := []int16{-32768, -32767, -4096, -1000, -9, -8, -1, 0, 1, 7, 8, 100, 1000, 4095, 4096, 32767}
+	alaw := []byte{0x2a, 0x2a, 0x1a, 0x7a, 0x55, 0x55, 0x55, 0xd5, 0xd5, 0xd5, 0xd5, 0xd3, 0xfa, 0x9a, 0x85, 0xaa}
+	ulaw := []byte{0x00, 0x00, 0x2f, 0x4e, 0x7d, 0x7e, 0x7e, 0xff, 0xff, 0xfe, 0xfe, 0xf2, 0xce, 0xaf, 0xaf, 0x80}
+	for i, s := range samples {
+		if a := EncodeAlaw(s); a != alaw[i] {
+			t.Errorf("EncodeAlaw(%d) = %#02x, want %#02x", s, a, alaw[i])
+		}
+		if u := EncodeUlaw(s); u != ulaw[i] {
+			t.Errorf("EncodeUlaw(%d) = %#02x, want %#02x", s, u, ulaw[i])
+		}
+	}
+
+	pcm := make([]byte, 0, 2*65536)
+	for v := -32768; v < 32768; v++ {
+		pcm = append(pcm, byte(v), byte(v>>8))
+	}
+	if digest := sha256.Sum256(EncodeAlawFrame(nil, pcm)); hex.EncodeToString(digest[:]) != alawDigest {
+		t.Errorf("A-law digest = %x, want %s", digest, alawDigest)
+	}
+	if digest := sha256.Sum256(EncodeUlawFrame(nil, pcm)); hex.EncodeToString(digest[:]) != ulawDigest {
+		t.Errorf("μ-law digest = %x, want %s", digest, ulawDigest)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for code := range 256 {
+		a := byte(code)
+		if got := EncodeAlaw(DecodeAlaw(a)); got != a {
+			t.Errorf("A-law %#02x encoded back as %#02x", a, got)
+		}
+		// the negative zero of μ-law is encoded as the positive one
+		if u := byte(code); u != 0x7f {
+			if got := EncodeUlaw(DecodeUlaw(u)); got != u {
+				t.Errorf("μ-law %#02x encoded back as %#02x", u, got)
+			}
+		}
+		if AlawToUlaw(a) != EncodeUlaw(DecodeAlaw(a)) || UlawToAlaw(a) != EncodeAlaw(DecodeUlaw(a)) {
+			t.Errorf("transcoding of %#02x differs", a)
+		}
+	}
+
+	frame := []byte{0x00, 0x55, 0xd5, 0xff}
+	pcm := DecodeAlawFrame(nil, frame)
+	if len(pcm) != 8 || int16(pcm[0])|int16(pcm[1])<<8 != alawDecoded[0] {
+		t.Errorf("decoded frame = %x", pcm)
+	}
+	if encoded := EncodeAlawFrame(nil, pcm); string(encoded) != string(frame) {
+		t.Errorf("A-law frame = %x, want %x", encoded, frame)
+	}
+	if encoded := EncodeUlawFrame(nil, DecodeUlawFrame(nil, frame)); string(encoded) != string(frame) {
+		t.Errorf("μ-law frame = %x, want %x", encoded, frame)
+	}
+}
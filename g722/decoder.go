@@ -0,0 +1,76 @@
+package g722
+
+import "github.com/URALINNOVATSIYA/audiocodec"
+
+// Decoder decompresses G.722 at 64 kbit/s into 16 kHz 16-bit PCM, every byte produces two samples.
+type Decoder struct {
+	bands [2]band
+	x     [24]int32
+}
+
+func NewDecoder() *Decoder {
+	return &Decoder{
+		bands: newBands(),
+	}
+}
+
+func (d *Decoder) Codec() *audiocodec.Codec {
+	return audiocodec.G722Codec
+}
+
+func (d *Decoder) PcmCodec() *audiocodec.Codec {
+	return audiocodec.Pcm16kHz16bCodec
+}
+
+func (d *Decoder) Reset() {
+	*d = Decoder{bands: newBands()}
+}
+
+// Decode appends the samples of the codes to dst.
+func (d *Decoder) Decode(dst []int16, data []byte) []int16 {
+	for _, code := range data {
+		s0, s1 := d.decode(code)
+		dst = append(dst, s0, s1)
+	}
+	return dst
+}
+
+// DecodeFrame appends the samples of the codes to dst as little-endian 16-bit PCM.
+func (d *Decoder) DecodeFrame(dst []byte, frame []byte) []byte {
+	for _, code := range frame {
+		s0, s1 := d.decode(code)
+		dst = append(dst, byte(s0), byte(uint16(s0)>>8), byte(s1), byte(uint16(s1)>>8))
+	}
+	return dst
+}
+
+func (d *Decoder) decode(code byte) (int16, int16) {
+	ilow := int32(code & 0x3F)
+	ihigh := int32(code >> 6)
+
+	// lower band: INVQBL, RECONS, LIMIT, INVQAL
+	low := &d.bands[0]
+	rlow := min(max(low.s+low.det*qm6[ilow]>>15, -16384), 16383)
+	ril := ilow >> 2
+	dlow := low.det * qm4[ril] >> 15
+	low.updateLow(ril)
+	low.adapt(dlow)
+
+	// upper band: INVQAH, RECONS, LIMIT
+	high := &d.bands[1]
+	dhigh := high.det * qm2[ihigh] >> 15
+	rhigh := min(max(dhigh+high.s, -16384), 16383)
+	high.updateHigh(ihigh)
+	high.adapt(dhigh)
+
+	// receive QMF
+	copy(d.x[:22], d.x[2:])
+	d.x[22] = rlow + rhigh
+	d.x[23] = rlow - rhigh
+	var out0, out1 int32
+	for i := 0; i < 12; i++ {
+		out1 += d.x[2*i] * qmfCoefficients[i]
+		out0 += d.x[2*i+1] * qmfCoefficients[11-i]
+	}
+	return int16(saturate(out0 >> 11)), int16(saturate(out1 >> 11))
+}
@@ -0,0 +1,112 @@
+package g722
+
+import "github.com/URALINNOVATSIYA/audiocodec"
+
+// Encoder compresses 16 kHz 16-bit PCM into G.722 at 64 kbit/s, every pair of samples produces one byte.
+type Encoder struct {
+	bands [2]band
+	x     [24]int32
+	odd   []byte // the last sample of an odd-sized frame
+}
+
+func NewEncoder() *Encoder {
+	return &Encoder{
+		bands: newBands(),
+	}
+}
+
+func (e *Encoder) Codec() *audiocodec.Codec {
+	return audiocodec.G722Codec
+}
+
+func (e *Encoder) PcmCodec() *audiocodec.Codec {
+	return audiocodec.Pcm16kHz16bCodec
+}
+
+func (e *Encoder) Reset() {
+	*e = Encoder{bands: newBands()}
+}
+
+// Encode appends the codes of sample pairs to dst, the last sample of an odd count is dropped.
+func (e *Encoder) Encode(dst []byte, samples []int16) []byte {
+	for i := 0; i+1 < len(samples); i += 2 {
+		dst = append(dst, e.encode(int32(samples[i]), int32(samples[i+1])))
+	}
+	return dst
+}
+
+// EncodeFrame appends the codes of little-endian 16-bit PCM to dst,
+// an odd sample is kept until the next frame so frames of any size can be passed.
+func (e *Encoder) EncodeFrame(dst []byte, pcm []byte) []byte {
+	if len(e.odd) > 0 {
+		pcm = append(e.odd, pcm...)
+		e.odd = nil
+	}
+	n := len(pcm) / 4 * 4
+	for i := 0; i < n; i += 4 {
+		s0 := int32(int16(uint16(pcm[i]) | uint16(pcm[i+1])<<8))
+		s1 := int32(int16(uint16(pcm[i+2]) | uint16(pcm[i+3])<<8))
+		dst = append(dst, e.encode(s0, s1))
+	}
+	if n+2 <= len(pcm) {
+		e.odd = append([]byte(nil), pcm[n:n+2]...)
+	}
+	return dst
+}
+
+func (e *Encoder) encode(s0 int32, s1 int32) byte {
+	// transmit QMF, every other output is discarded
+	copy(e.x[:22], e.x[2:])
+	e.x[22] = s0
+	e.x[23] = s1
+	var sumEven, sumOdd int32
+	for i := 0; i < 12; i++ {
+		sumOdd += e.x[2*i] * qmfCoefficients[i]
+		sumEven += e.x[2*i+1] * qmfCoefficients[11-i]
+	}
+	xlow := (sumEven + sumOdd) >> 14
+	xhigh := (sumEven - sumOdd) >> 14
+
+	// lower band: SUBTRA, QUANTL, INVQAL
+	low := &e.bands[0]
+	el := saturate(xlow - low.s)
+	wd := el
+	if el < 0 {
+		wd = -(el + 1)
+	}
+	i := 1
+	for ; i < 30; i++ {
+		if wd < q6[i]*low.det>>12 {
+			break
+		}
+	}
+	ilow := ilp[i]
+	if el < 0 {
+		ilow = iln[i]
+	}
+	ril := ilow >> 2
+	dlow := low.det * qm4[ril] >> 15
+	low.updateLow(ril)
+	low.adapt(dlow)
+
+	// upper band: SUBTRA, QUANTH, INVQAH
+	high := &e.bands[1]
+	eh := saturate(xhigh - high.s)
+	wd = eh
+	if eh < 0 {
+		wd = -(eh + 1)
+	}
+	mih := 1
+	if wd >= 564*high.det>>12 {
+		mih = 2
+	}
+	ihigh := ihp[mih]
+	if eh < 0 {
+		ihigh = ihn[mih]
+	}
+	dhigh := high.det * qm2[ihigh] >> 15
+	high.updateHigh(ihigh)
+	high.adapt(dhigh)
+
+	return byte(ihigh<<6 | ilow)
+}
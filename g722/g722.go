@@ -0,0 +1,162 @@
+package g722
+
+// The implementation follows ITU-T G.722 (SB-ADPCM) in the 64 kbit/s mode: the 16 kHz signal is split by
+// the QMF into two 8 kHz subbands, the lower band is coded with 6 bits and the upper band with 2 bits per sample.
+
+var (
+	q6   = [32]int32{0, 35, 72, 110, 150, 190, 233, 276, 323, 370, 422, 473, 530, 587, 650, 714, 786, 858, 940, 1023, 1121, 1219, 1339, 1458, 1612, 1765, 1980, 2195, 2557, 2919, 0, 0}
+	iln  = [32]int32{0, 63, 62, 31, 30, 29, 28, 27, 26, 25, 24, 23, 22, 21, 20, 19, 18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 0}
+	ilp  = [32]int32{0, 61, 60, 59, 58, 57, 56, 55, 54, 53, 52, 51, 50, 49, 48, 47, 46, 45, 44, 43, 42, 41, 40, 39, 38, 37, 36, 35, 34, 33, 32, 0}
+	wl   = [8]int32{-60, -30, 58, 172, 334, 538, 1198, 3042}
+	rl42 = [16]int32{0, 7, 6, 5, 4, 3, 2, 1, 7, 6, 5, 4, 3, 2, 1, 0}
+	ilb  = [32]int32{2048, 2093, 2139, 2186, 2233, 2282, 2332, 2383, 2435, 2489, 2543, 2599, 2656, 2714, 2774, 2834, 2896, 2960, 3025, 3091, 3158, 3228, 3298, 3371, 3444, 3520, 3597, 3676, 3756, 3838, 3922, 4008}
+	qm4  = [16]int32{0, -20456, -12896, -8968, -6288, -4240, -2584, -1200, 20456, 12896, 8968, 6288, 4240, 2584, 1200, 0}
+	qm6  = [64]int32{
+		-136, -136, -136, -136, -24808, -21904, -19008, -16704, -14984, -13512, -12280, -11192, -10232, -9360, -8576, -7856,
+		-7192, -6576, -6000, -5456, -4944, -4464, -4008, -3576, -3168, -2776, -2400, -2032, -1688, -1360, -1040, -728,
+		24808, 21904, 19008, 16704, 14984, 13512, 12280, 11192, 10232, 9360, 8576, 7856, 7192, 6576, 6000, 5456,
+		4944, 4464, 4008, 3576, 3168, 2776, 2400, 2032, 1688, 1360, 1040, 728, 432, 136, -432, -136,
+	}
+	qm2 = [4]int32{-7408, -1616, 7408, 1616}
+	ihn = [3]int32{0, 1, 0}
+	ihp = [3]int32{0, 3, 2}
+	wh  = [3]int32{0, -214, 798}
+	rh2 = [4]int32{2, 1, 2, 1}
+
+	qmfCoefficients = [12]int32{3, -11, 12, 32, -210, 951, 3876, -805, 362, -156, 53, -11}
+)
+
+// band is the adaptive predictor state of a subband
+type band struct {
+	s   int32
+	sp  int32
+	sz  int32
+	r   [3]int32
+	a   [3]int32
+	ap  [3]int32
+	p   [3]int32
+	d   [7]int32
+	b   [7]int32
+	bp  [7]int32
+	sg  [7]int32
+	nb  int32
+	det int32
+}
+
+func newBands() [2]band {
+	var bands [2]band
+	bands[0].det = 32
+	bands[1].det = 8
+	return bands
+}
+
+// updateLow adapts the lower band scale factor to the 4 most significant bits of the code (blocks 3L LOGSCL and SCALEL)
+func (b *band) updateLow(ril int32) {
+	nb := b.nb*127>>7 + wl[rl42[ril]]
+	b.nb = min(max(nb, 0), 18432)
+	b.det = scale(b.nb, 8)
+}
+
+// updateHigh adapts the upper band scale factor to the code (blocks 3H LOGSCH and SCALEH)
+func (b *band) updateHigh(ihigh int32) {
+	nb := b.nb*127>>7 + wh[rh2[ihigh]]
+	b.nb = min(max(nb, 0), 22528)
+	b.det = scale(b.nb, 10)
+}
+
+func scale(nb int32, shift int32) int32 {
+	wd1 := nb >> 6 & 31
+	wd2 := shift - nb>>11
+	if wd2 < 0 {
+		return ilb[wd1] << -wd2 << 2
+	}
+	return ilb[wd1] >> wd2 << 2
+}
+
+// adapt updates the predictor with the quantized difference signal (block 4)
+func (b *band) adapt(d int32) {
+	// RECONS, PARREC
+	b.d[0] = d
+	b.r[0] = saturate(b.s + d)
+	b.p[0] = saturate(b.sz + d)
+
+	// UPPOL2
+	for i := 0; i < 3; i++ {
+		b.sg[i] = b.p[i] >> 15
+	}
+	wd1 := saturate(b.a[1] << 2)
+	wd2 := wd1
+	if b.sg[0] == b.sg[1] {
+		wd2 = -wd1
+	}
+	wd2 = min(wd2, 32767)
+	wd3 := wd2 >> 7
+	if b.sg[0] == b.sg[2] {
+		wd3 += 128
+	} else {
+		wd3 -= 128
+	}
+	wd3 += b.a[2] * 32512 >> 15
+	b.ap[2] = min(max(wd3, -12288), 12288)
+
+	// UPPOL1
+	b.sg[0] = b.p[0] >> 15
+	b.sg[1] = b.p[1] >> 15
+	wd1 = -192
+	if b.sg[0] == b.sg[1] {
+		wd1 = 192
+	}
+	wd2 = b.a[1] * 32640 >> 15
+	b.ap[1] = saturate(wd1 + wd2)
+	wd3 = saturate(15360 - b.ap[2])
+	b.ap[1] = min(max(b.ap[1], -wd3), wd3)
+
+	// UPZERO
+	wd1 = 128
+	if d == 0 {
+		wd1 = 0
+	}
+	b.sg[0] = d >> 15
+	for i := 1; i < 7; i++ {
+		b.sg[i] = b.d[i] >> 15
+		wd2 = -wd1
+		if b.sg[i] == b.sg[0] {
+			wd2 = wd1
+		}
+		wd3 = b.b[i] * 32640 >> 15
+		b.bp[i] = saturate(wd2 + wd3)
+	}
+
+	// DELAYA
+	for i := 6; i > 0; i-- {
+		b.d[i] = b.d[i-1]
+		b.b[i] = b.bp[i]
+	}
+	for i := 2; i > 0; i-- {
+		b.r[i] = b.r[i-1]
+		b.p[i] = b.p[i-1]
+		b.a[i] = b.ap[i]
+	}
+
+	// FILTEP
+	wd1 = saturate(b.r[1] + b.r[1])
+	wd1 = b.a[1] * wd1 >> 15
+	wd2 = saturate(b.r[2] + b.r[2])
+	wd2 = b.a[2] * wd2 >> 15
+	b.sp = saturate(wd1 + wd2)
+
+	// FILTEZ
+	b.sz = 0
+	for i := 6; i > 0; i-- {
+		wd1 = saturate(b.d[i] + b.d[i])
+		b.sz += b.b[i] * wd1 >> 15
+	}
+	b.sz = saturate(b.sz)
+
+	// PREDIC
+	b.s = saturate(b.sp + b.sz)
+}
+
+func saturate(v int32) int32 {
+	return min(max(v, -32768), 32767)
+}
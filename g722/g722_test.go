@@ -0,0 +1,97 @@
+package g722
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+// sine returns n samples of a tone of the frequency at 16 kHz
+func sine(frequency float64, n int) []int16 {
+	samples := make([]int16, n)
+	for i := range samples {
+		samples[i] = int16(10_000 * math.Sin(2*math.Pi*frequency*float64(i)/16_000))
+	}
+	return samples
+}
+
+// snr returns the signal to noise ratio in dB of the decoded samples delayed by the codec
+func snr(samples []int16, decoded []int16, delay int) float64 {
+	var signal, noise float64
+	for i := delay; i < len(decoded); i++ {
+		s := float64(samples[i-delay])
+		d := float64(decoded[i]) - s
+		signal += s * s
+		noise += d * d
+	}
+	return 10 * math.Log10(signal/noise)
+}
+
+// bestSnr returns the SNR at the delay of the QMF filters, which is searched rather than hardcoded
+func bestSnr(samples []int16, decoded []int16) float64 {
+	best := math.Inf(-1)
+	for delay := 0; delay < 64; delay++ {
+		best = max(best, snr(samples, decoded, delay))
+	}
+	return best
+}
+
+func TestRoundTrip(t *testing.T) {
+	// both sub-bands: 1 kHz in the lower one coded with 6 bits and 5 kHz in the higher one coded with 2 bits
+	tests := []struct {
+		frequency float64
+		minSnr    float64
+	}{
+		{1_000, 40},
+		{5_000, 20},
+	}
+	for _, test := range tests {
+		samples := sine(test.frequency, 3200)
+		codes := NewEncoder().Encode(nil, samples)
+		if len(codes) != len(samples)/2 {
+			t.Fatalf("%d codes of %d samples", len(codes), len(samples))
+		}
+		decoded := NewDecoder().Decode(nil, codes)
+		// skip the adaptation of the first 20 ms
+		if s := bestSnr(samples[320:], decoded[320:]); s < test.minSnr {
+			t.Errorf("%.0f Hz: SNR = %.1f dB, want at least %.0f dB", test.frequency, s, test.minSnr)
+		}
+	}
+}
+
+func TestEncodeFrame(t *testing.T) {
+	samples := sine(1_000, 321)
+	pcm := make([]byte, 0, 2*len(samples))
+	for _, s := range samples {
+		pcm = append(pcm, byte(s), byte(uint16(s)>>8))
+	}
+
+	want := NewEncoder().Encode(nil, samples)
+	// frames of odd sizes keep the last sample until the next frame
+	e := NewEncoder()
+	var codes []byte
+	for _, frame := range [][]byte{pcm[:6], pcm[6:300], pcm[300:]} {
+		codes = e.EncodeFrame(codes, frame)
+	}
+	if !bytes.Equal(codes, want) {
+		t.Errorf("codes of frames differ from codes of samples")
+	}
+
+	decoded := NewDecoder().DecodeFrame(nil, codes)
+	d := NewDecoder().Decode(nil, codes)
+	for i, s := range d {
+		if decoded[2*i] != byte(s) || decoded[2*i+1] != byte(uint16(s)>>8) {
+			t.Fatalf("sample %d: PCM % x, want %d", i, decoded[2*i:2*i+2], s)
+		}
+	}
+}
+
+func TestReset(t *testing.T) {
+	samples := sine(1_000, 320)
+	e := NewEncoder()
+	first := e.Encode(nil, samples)
+	e.Reset()
+	if second := e.Encode(nil, samples); !bytes.Equal(first, second) {
+		t.Errorf("codes after Reset differ")
+	}
+}
@@ -0,0 +1,184 @@
+package g726
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+var NotSupportedBitRate = errors.New("G.726 supports 16, 24, 32 and 40 kbit/s")
+
+type Packing int
+
+// Rtp - RFC 3551 packing used in RTP: the first code occupies the least significant bits.
+// Aal2 - ITU-T I.366.2 packing used in AAL2 and WAV files: the first code occupies the most significant bits.
+const (
+	Rtp Packing = iota
+	Aal2
+)
+
+// BitsByBitRate returns the number of bits per sample of the bit rate in bit/s.
+func BitsByBitRate(bitRate int) (int, error) {
+	bits := bitRate / 8_000
+	if _, ok := rates[bits]; !ok || bitRate%8_000 != 0 {
+		return 0, fmt.Errorf("%w: %d", NotSupportedBitRate, bitRate)
+	}
+	return bits, nil
+}
+
+func rateByBits(bits int) (*rate, error) {
+	r, ok := rates[bits]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d bits per sample", NotSupportedBitRate, bits)
+	}
+	return r, nil
+}
+
+// Encoder compresses 8 kHz 16-bit PCM into packed G.726 codes.
+type Encoder struct {
+	state   state
+	packing Packing
+	bits    uint32 // bits not yet forming a whole byte
+	n       int
+}
+
+// NewEncoder creates an encoder of codes of the given number of bits (2 - 5), i.e. 16 - 40 kbit/s.
+func NewEncoder(bits int, packing Packing) (*Encoder, error) {
+	r, err := rateByBits(bits)
+	if err != nil {
+		return nil, err
+	}
+	return &Encoder{
+		state:   newState(r),
+		packing: packing,
+	}, nil
+}
+
+func (e *Encoder) Codec() *audiocodec.Codec {
+	return audiocodec.G726Codec
+}
+
+// Bits returns the number of bits per code.
+func (e *Encoder) Bits() int {
+	return e.state.rate.bits
+}
+
+func (e *Encoder) PcmCodec() *audiocodec.Codec {
+	return audiocodec.Pcm8kHz16bCodec
+}
+
+func (e *Encoder) Reset() {
+	e.state = newState(e.state.rate)
+	e.bits = 0
+	e.n = 0
+}
+
+// EncodeSample returns the code of the sample.
+func (e *Encoder) EncodeSample(sample int16) byte {
+	sl := int32(sample) >> 2 // 14-bit dynamic range
+	se, sez := e.state.predict()
+	y := e.state.stepSize()
+	code := e.state.quantize(int32(int16(sl-se)), y)
+	e.state.process(code, y, se, sez)
+	return byte(code)
+}
+
+// EncodeFrame appends the packed codes of little-endian 16-bit PCM to dst. Codes not filling a whole byte
+// are kept until the next frame, frames of 8 samples always produce whole bytes.
+func (e *Encoder) EncodeFrame(dst []byte, pcm []byte) []byte {
+	bits := e.state.rate.bits
+	for i := 0; i+1 < len(pcm); i += 2 {
+		code := uint32(e.EncodeSample(int16(uint16(pcm[i]) | uint16(pcm[i+1])<<8)))
+		if e.packing == Rtp {
+			e.bits |= code << e.n
+		} else {
+			e.bits = e.bits<<bits | code
+		}
+		e.n += bits
+		for e.n >= 8 {
+			e.n -= 8
+			if e.packing == Rtp {
+				dst = append(dst, byte(e.bits))
+				e.bits >>= 8
+			} else {
+				dst = append(dst, byte(e.bits>>e.n))
+			}
+		}
+	}
+	return dst
+}
+
+// Decoder decompresses packed G.726 codes into 8 kHz 16-bit PCM.
+type Decoder struct {
+	state   state
+	packing Packing
+}
+
+// NewDecoder creates a decoder of codes of the given number of bits (2 - 5), i.e. 16 - 40 kbit/s.
+func NewDecoder(bits int, packing Packing) (*Decoder, error) {
+	r, err := rateByBits(bits)
+	if err != nil {
+		return nil, err
+	}
+	return &Decoder{
+		state:   newState(r),
+		packing: packing,
+	}, nil
+}
+
+func (d *Decoder) Codec() *audiocodec.Codec {
+	return audiocodec.G726Codec
+}
+
+// Bits returns the number of bits per code.
+func (d *Decoder) Bits() int {
+	return d.state.rate.bits
+}
+
+func (d *Decoder) PcmCodec() *audiocodec.Codec {
+	return audiocodec.Pcm8kHz16bCodec
+}
+
+func (d *Decoder) Reset() {
+	d.state = newState(d.state.rate)
+}
+
+// DecodeSample returns the sample of the code.
+func (d *Decoder) DecodeSample(code byte) int16 {
+	c := int32(code) & (1<<d.state.rate.bits - 1)
+	se, sez := d.state.predict()
+	y := d.state.stepSize()
+	sr := d.state.process(c, y, se, sez)
+	return int16(sr << 2)
+}
+
+// DecodeFrame appends the samples of the packed codes to dst as little-endian 16-bit PCM,
+// trailing bits not forming a whole code are ignored.
+func (d *Decoder) DecodeFrame(dst []byte, frame []byte) []byte {
+	bits := d.state.rate.bits
+	mask := uint32(1)<<bits - 1
+	var buffer uint32
+	n := 0
+	for _, b := range frame {
+		if d.packing == Rtp {
+			buffer |= uint32(b) << n
+		} else {
+			buffer = buffer<<8 | uint32(b)
+		}
+		n += 8
+		for n >= bits {
+			n -= bits
+			var code uint32
+			if d.packing == Rtp {
+				code = buffer & mask
+				buffer >>= bits
+			} else {
+				code = buffer >> n & mask
+			}
+			s := d.DecodeSample(byte(code))
+			dst = append(dst, byte(s), byte(uint16(s)>>8))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,111 @@
+package g726
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// pcm returns n samples of a 1 kHz tone at 8 kHz as little-endian 16-bit PCM
+func pcm(n int) []byte {
+	b := make([]byte, 0, 2*n)
+	for i := 0; i < n; i++ {
+		s := int16(10_000 * math.Sin(2*math.Pi*1_000*float64(i)/8_000))
+		b = append(b, byte(s), byte(uint16(s)>>8))
+	}
+	return b
+}
+
+// snr returns the signal to noise ratio in dB of the decoded PCM
+func snr(pcm []byte, decoded []byte) float64 {
+	var signal, noise float64
+	for i := 0; i+1 < len(decoded); i += 2 {
+		s := float64(int16(uint16(pcm[i]) | uint16(pcm[i+1])<<8))
+		d := float64(int16(uint16(decoded[i])|uint16(decoded[i+1])<<8)) - s
+		signal += s * s
+		noise += d * d
+	}
+	return 10 * math.Log10(signal/noise)
+}
+
+func TestRoundTrip(t *testing.T) {
+	minSnr := map[int]float64{2: 15, 3: 20, 4: 30, 5: 35}
+	for bits := 2; bits <= 5; bits++ {
+		for _, packing := range []Packing{Rtp, Aal2} {
+			e, err := NewEncoder(bits, packing)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d, err := NewDecoder(bits, packing)
+			if err != nil {
+				t.Fatal(err)
+			}
+			in := pcm(8_000)
+			frame := e.EncodeFrame(nil, in)
+			if len(frame) != 8_000*bits/8 {
+				t.Fatalf("%d bits: frame of %d bytes", bits, len(frame))
+			}
+			out := d.DecodeFrame(nil, frame)
+			if len(out) != len(in) {
+				t.Fatalf("%d bits: %d bytes decoded", bits, len(out))
+			}
+			// skip the adaptation of the first 100 ms
+			if s := snr(in[1_600:], out[1_600:]); s < minSnr[bits] {
+				t.Errorf("%d bits, packing %d: SNR = %.1f dB, want at least %.0f dB", bits, packing, s, minSnr[bits])
+			}
+		}
+	}
+}
+
+func TestPacking(t *testing.T) {
+	in := pcm(8)
+	var codes [8]byte
+	e, _ := NewEncoder(4, Rtp)
+	for i := range codes {
+		codes[i] = e.EncodeSample(int16(uint16(in[2*i]) | uint16(in[2*i+1])<<8))
+	}
+
+	// RFC 3551 puts the first code into the least significant bits, AAL2 into the most significant ones
+	e, _ = NewEncoder(4, Rtp)
+	rtp := e.EncodeFrame(nil, in)
+	e, _ = NewEncoder(4, Aal2)
+	aal2 := e.EncodeFrame(nil, in)
+	for i := 0; i < 4; i++ {
+		if want := codes[2*i] | codes[2*i+1]<<4; rtp[i] != want {
+			t.Errorf("RTP byte %d = %#x, want %#x", i, rtp[i], want)
+		}
+		if want := codes[2*i]<<4 | codes[2*i+1]; aal2[i] != want {
+			t.Errorf("AAL2 byte %d = %#x, want %#x", i, aal2[i], want)
+		}
+	}
+}
+
+func TestEncodeFrame(t *testing.T) {
+	in := pcm(160)
+	e, _ := NewEncoder(3, Rtp)
+	want := e.EncodeFrame(nil, in)
+
+	// codes not filling a whole byte are kept until the next frame
+	e.Reset()
+	var frame []byte
+	for _, part := range [][]byte{in[:2], in[2:34], in[34:]} {
+		frame = e.EncodeFrame(frame, part)
+	}
+	if string(frame) != string(want) {
+		t.Errorf("codes of parts differ from codes of the whole frame")
+	}
+}
+
+func TestBitsByBitRate(t *testing.T) {
+	for bitRate, want := range map[int]int{16_000: 2, 24_000: 3, 32_000: 4, 40_000: 5} {
+		if bits, err := BitsByBitRate(bitRate); err != nil || bits != want {
+			t.Errorf("BitsByBitRate(%d) = %d, %v, want %d", bitRate, bits, err, want)
+		}
+	}
+	if _, err := BitsByBitRate(48_000); !errors.Is(err, NotSupportedBitRate) {
+		t.Errorf("BitsByBitRate(48000): %v, want %v", err, NotSupportedBitRate)
+	}
+	if _, err := NewEncoder(6, Rtp); !errors.Is(err, NotSupportedBitRate) {
+		t.Errorf("NewEncoder(6): %v, want %v", err, NotSupportedBitRate)
+	}
+}
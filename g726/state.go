@@ -0,0 +1,358 @@
+package g726
+
+// The implementation follows the CCITT reference code released by Sun Microsystems (g72x.c) extended with
+// the 16 kbit/s rate of ITU-T G.726 Annex A.
+
+var power2 = [15]int32{1, 2, 4, 8, 0x10, 0x20, 0x40, 0x80, 0x100, 0x200, 0x400, 0x800, 0x1000, 0x2000, 0x4000}
+
+// rate describes the quantizer of a bit rate
+type rate struct {
+	bits          int
+	quantizer     []int32
+	dqln          []int32 // reconstructed scale factor normalized log magnitudes by code
+	wi            []int32 // logarithms of the scale factor multiplier by code
+	fi            []int32 // values averaged by the adaptation speed control by code
+	quantizerSize int     // number of states of the quantizer
+}
+
+var rates = map[int]*rate{
+	2: {
+		bits:          2,
+		quantizer:     []int32{261},
+		dqln:          []int32{116, 365, 365, 116},
+		wi:            []int32{-704, 14048, 14048, -704},
+		fi:            []int32{0, 0xE00, 0xE00, 0},
+		quantizerSize: 4,
+	},
+	3: {
+		bits:          3,
+		quantizer:     []int32{8, 218, 331},
+		dqln:          []int32{-2048, 135, 273, 373, 373, 273, 135, -2048},
+		wi:            []int32{-128, 960, 4384, 18624, 18624, 4384, 960, -128},
+		fi:            []int32{0, 0x200, 0x400, 0xE00, 0xE00, 0x400, 0x200, 0},
+		quantizerSize: 7,
+	},
+	4: {
+		bits:      4,
+		quantizer: []int32{-124, 80, 178, 246, 300, 349, 400},
+		dqln:      []int32{-2048, 4, 135, 213, 273, 323, 373, 425, 425, 373, 323, 273, 213, 135, 4, -2048},
+		wi: []int32{-384, 576, 1312, 2048, 3584, 6336, 11360, 35904, 35904, 11360, 6336, 3584, 2048, 1312, 576,
+			-384},
+		fi:            []int32{0, 0, 0, 0x200, 0x200, 0x200, 0x600, 0xE00, 0xE00, 0x600, 0x200, 0x200, 0x200, 0, 0, 0},
+		quantizerSize: 15,
+	},
+	5: {
+		bits:      5,
+		quantizer: []int32{-122, -16, 68, 139, 198, 250, 298, 339, 378, 413, 445, 475, 502, 528, 553},
+		dqln: []int32{-2048, -66, 28, 104, 169, 224, 274, 318, 358, 395, 429, 459, 488, 514, 539, 566, 566, 539, 514,
+			488, 459, 429, 395, 358, 318, 274, 224, 169, 104, 28, -66, -2048},
+		wi: []int32{448, 448, 768, 1248, 1280, 1312, 1856, 3200, 4512, 5728, 7008, 8960, 11456, 14080, 16928, 22272,
+			22272, 16928, 14080, 11456, 8960, 7008, 5728, 4512, 3200, 1856, 1312, 1280, 1248, 768, 448, 448},
+		fi: []int32{0, 0, 0, 0, 0, 0x200, 0x200, 0x200, 0x200, 0x200, 0x400, 0x600, 0x800, 0xA00, 0xC00, 0xC00, 0xC00,
+			0xC00, 0xA00, 0x800, 0x600, 0x400, 0x200, 0x200, 0x200, 0x200, 0x200, 0, 0, 0, 0, 0},
+		quantizerSize: 31,
+	},
+}
+
+// state is the adaptive predictor and quantizer state shared by the encoder and the decoder
+type state struct {
+	rate *rate
+	yl   int32    // locked or steady state step size multiplier
+	yu   int32    // unlocked or non-steady state step size multiplier
+	dms  int32    // short term energy estimate
+	dml  int32    // long term energy estimate
+	ap   int32    // linear weighting coefficient of yl and yu
+	a    [2]int32 // coefficients of the pole portion of the prediction filter
+	b    [6]int32 // coefficients of the zero portion of the prediction filter
+	pk   [2]int32 // signs of the previous two samples of the partially reconstructed signal
+	dq   [6]int32 // previous quantized difference signal samples in the internal floating point format
+	sr   [2]int32 // previous reconstructed signal samples in the internal floating point format
+	td   bool     // delayed tone detect
+}
+
+func newState(r *rate) state {
+	s := state{
+		rate: r,
+		yl:   34816,
+		yu:   544,
+	}
+	for i := range s.dq {
+		s.dq[i] = 32
+	}
+	s.sr = [2]int32{32, 32}
+	return s
+}
+
+// predict returns the signal estimate and the estimate of the zero section
+func (s *state) predict() (se int32, sez int32) {
+	var sezi int32
+	for i := 0; i < 6; i++ {
+		sezi += fmult(s.b[i]>>2, s.dq[i])
+	}
+	sei := sezi + fmult(s.a[1]>>2, s.sr[1]) + fmult(s.a[0]>>2, s.sr[0])
+	return sei >> 1, sezi >> 1
+}
+
+func (s *state) stepSize() int32 {
+	if s.ap >= 256 {
+		return s.yu
+	}
+	y := s.yl >> 6
+	dif := s.yu - y
+	al := s.ap >> 2
+	if dif > 0 {
+		y += dif * al >> 6
+	} else if dif < 0 {
+		y += (dif*al + 0x3F) >> 6
+	}
+	return y
+}
+
+// quantize returns the code of the difference signal d with the step size y
+func (s *state) quantize(d int32, y int32) int32 {
+	dqm := abs(d)
+	exp := quan(dqm>>1, power2[:])
+	mant := (dqm << 7 >> exp) & 0x7F
+	dl := exp<<7 + mant
+	dln := dl - y>>2
+
+	size := int32(s.rate.quantizerSize-1) >> 1
+	i := quan(dln, s.rate.quantizer[:size])
+	if d < 0 {
+		return size<<1 + 1 - i
+	}
+	if i == 0 && s.rate.quantizerSize&1 != 0 {
+		// zero is valid only for an even number of states
+		return int32(s.rate.quantizerSize)
+	}
+	return i
+}
+
+// reconstruct returns the quantized difference signal of the code
+func (s *state) reconstruct(code int32, y int32) int32 {
+	sign := code&(1<<(s.rate.bits-1)) != 0
+	dql := s.rate.dqln[code] + y>>2
+	if dql < 0 {
+		if sign {
+			return -0x8000
+		}
+		return 0
+	}
+	dex := dql >> 7 & 15
+	dqt := 128 + dql&127
+	dq := dqt << 7 >> (14 - dex)
+	if sign {
+		return dq - 0x8000
+	}
+	return dq
+}
+
+// process reconstructs the signal of the code and adapts the state, the 14-bit signal is returned
+func (s *state) process(code int32, y int32, se int32, sez int32) int32 {
+	dq := s.reconstruct(code, y)
+	// the reference code keeps both values in 16 bits
+	var sr int32
+	if dq < 0 {
+		sr = int32(int16(se - dq&0x3FFF))
+	} else {
+		sr = int32(int16(se + dq))
+	}
+	dqsez := int32(int16(sr + sez - se))
+
+	wi := s.rate.wi[code]
+	fi := s.rate.fi[code]
+	s.update(y, wi, fi, dq, sr, dqsez)
+	return sr
+}
+
+func (s *state) update(y int32, wi int32, fi int32, dq int32, sr int32, dqsez int32) {
+	var pk0 int32
+	if dqsez < 0 {
+		pk0 = 1
+	}
+	mag := dq & 0x7FFF
+
+	// TRANS
+	ylint := s.yl >> 15
+	ylfrac := s.yl >> 10 & 0x1F
+	thr1 := (32 + ylfrac) << ylint
+	thr2 := thr1
+	if ylint > 9 {
+		thr2 = 31 << 10
+	}
+	dqthr := (thr2 + thr2>>1) >> 1
+	tr := s.td && mag > dqthr
+
+	// quantizer scale factor adaptation: FUNCTW, FILTD, DELAY, LIMB, FILTE
+	s.yu = min(max(y+(wi-y)>>5, 544), 5120)
+	s.yl += s.yu + (-s.yl)>>6
+
+	// adaptive predictor coefficients
+	var a2p int32
+	if tr {
+		s.a = [2]int32{}
+		s.b = [6]int32{}
+	} else {
+		pks1 := pk0 ^ s.pk[0]
+
+		// UPA2
+		a2p = s.a[1] - s.a[1]>>7
+		if dqsez != 0 {
+			fa1 := -s.a[0]
+			if pks1 != 0 {
+				fa1 = s.a[0]
+			}
+			if fa1 < -8191 {
+				a2p -= 0x100
+			} else if fa1 > 8191 {
+				a2p += 0xFF
+			} else {
+				a2p += fa1 >> 5
+			}
+
+			// LIMC
+			if pk0^s.pk[1] != 0 {
+				if a2p <= -12160 {
+					a2p = -12288
+				} else if a2p >= 12416 {
+					a2p = 12288
+				} else {
+					a2p -= 0x80
+				}
+			} else if a2p <= -12416 {
+				a2p = -12288
+			} else if a2p >= 12160 {
+				a2p = 12288
+			} else {
+				a2p += 0x80
+			}
+		}
+		s.a[1] = a2p
+
+		// UPA1, LIMD
+		s.a[0] -= s.a[0] >> 8
+		if dqsez != 0 {
+			if pks1 == 0 {
+				s.a[0] += 192
+			} else {
+				s.a[0] -= 192
+			}
+		}
+		a1ul := 15360 - a2p
+		s.a[0] = min(max(s.a[0], -a1ul), a1ul)
+
+		// UPB
+		for i := range s.b {
+			if s.rate.bits == 5 {
+				s.b[i] -= s.b[i] >> 9
+			} else {
+				s.b[i] -= s.b[i] >> 8
+			}
+			if dq&0x7FFF != 0 {
+				if int16(dq)^int16(s.dq[i]) >= 0 {
+					s.b[i] += 128
+				} else {
+					s.b[i] -= 128
+				}
+			}
+		}
+	}
+
+	// FLOAT A: dq to 4-bit exponent and 6-bit mantissa
+	copy(s.dq[1:], s.dq[:5])
+	if mag == 0 {
+		if dq >= 0 {
+			s.dq[0] = 0x20
+		} else {
+			s.dq[0] = 0x20 - 0x400
+		}
+	} else {
+		exp := quan(mag, power2[:])
+		s.dq[0] = exp<<6 + mag<<6>>exp
+		if dq < 0 {
+			s.dq[0] -= 0x400
+		}
+	}
+
+	// FLOAT B: sr to 4-bit exponent and 6-bit mantissa
+	s.sr[1] = s.sr[0]
+	switch {
+	case sr == 0:
+		s.sr[0] = 0x20
+	case sr > 0:
+		exp := quan(sr, power2[:])
+		s.sr[0] = exp<<6 + sr<<6>>exp
+	case sr > -32768:
+		m := -sr
+		exp := quan(m, power2[:])
+		s.sr[0] = exp<<6 + m<<6>>exp - 0x400
+	default:
+		s.sr[0] = 0x20 - 0x400
+	}
+
+	// DELAY A
+	s.pk[1] = s.pk[0]
+	s.pk[0] = pk0
+
+	// TONE
+	s.td = !tr && a2p < -11776
+
+	// adaptation speed control: FILTA, FILTB, SUBTC
+	s.dms += (fi - s.dms) >> 5
+	s.dml += (fi<<2 - s.dml) >> 7
+	switch {
+	case tr:
+		s.ap = 256
+	case y < 1536, s.td, abs(s.dms<<2-s.dml) >= s.dml>>3:
+		s.ap += (0x200 - s.ap) >> 4
+	default:
+		s.ap += (-s.ap) >> 4
+	}
+}
+
+// fmult multiplies the predictor coefficient by the signal in the floating point format
+func fmult(an int32, srn int32) int32 {
+	anmag := an
+	if an <= 0 {
+		anmag = -an & 0x1FFF
+	}
+	anexp := quan(anmag, power2[:]) - 6
+	var anmant int32
+	switch {
+	case anmag == 0:
+		anmant = 32
+	case anexp >= 0:
+		anmant = anmag >> anexp
+	default:
+		anmant = anmag << -anexp
+	}
+	wanexp := anexp + srn>>6&0xF - 13
+	wanmant := (anmant*(srn&0x3F) + 0x30) >> 4
+	var retval int32
+	if wanexp >= 0 {
+		retval = wanmant << wanexp & 0x7FFF
+	} else {
+		retval = wanmant >> -wanexp
+	}
+	if int16(an)^int16(srn) < 0 {
+		return -retval
+	}
+	return retval
+}
+
+// quan returns the index of the first table value greater than v
+func quan(v int32, table []int32) int32 {
+	for i, t := range table {
+		if v < t {
+			return int32(i)
+		}
+	}
+	return int32(len(table))
+}
+
+func abs(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,42 @@
+package g726
+
+import (
+	"fmt"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+// DecodeWav decodes a mono WAV of G.726 into 16-bit PCM.
+func DecodeWav(wav *audiocodec.Wav) (*audiocodec.Wav, error) {
+	if wav.Codec().Name != audiocodec.G726 || wav.Channels() != 1 {
+		return nil, fmt.Errorf("%w: %s", audiocodec.UnsupportedFormat, wav.Codec().Name)
+	}
+	// a block of a mono WAV is 8 codes
+	d, err := NewDecoder(wav.BlockAlign(), Aal2)
+	if err != nil {
+		return nil, err
+	}
+
+	result := audiocodec.NewWav(d.PcmCodec())
+	if _, err = result.Write(d.DecodeFrame(nil, wav.Data())); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// EncodeWav encodes a mono WAV of 8 kHz 16-bit PCM into G.726 with the given number of bits per sample.
+func EncodeWav(wav *audiocodec.Wav, bits int) (*audiocodec.Wav, error) {
+	e, err := NewEncoder(bits, Aal2)
+	if err != nil {
+		return nil, err
+	}
+	if !wav.Codec().IsEqual(e.PcmCodec()) || wav.Channels() != 1 {
+		return nil, fmt.Errorf("%w: %s", audiocodec.UnsupportedFormat, wav.Codec().Preset())
+	}
+
+	result := audiocodec.NewWavWithBlockAlign(e.Codec(), 1, bits)
+	if _, err = result.Write(e.EncodeFrame(nil, wav.Data())); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
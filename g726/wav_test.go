@@ -0,0 +1,47 @@
+package g726
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+func TestWavRoundTrip(t *testing.T) {
+	wav := audiocodec.NewWav(audiocodec.Pcm8kHz16bCodec)
+	wav.Write(pcm(8_000))
+	for bits := 2; bits <= 5; bits++ {
+		encoded, err := EncodeWav(wav, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var file bytes.Buffer
+		if _, err = encoded.WriteTo(&file); err != nil {
+			t.Fatal(err)
+		}
+		// fmt chunk: byte rate, block align and bits per sample
+		fmtChunk := file.Bytes()[20:]
+		if byteRate := binary.LittleEndian.Uint32(fmtChunk[8:12]); byteRate != uint32(1_000*bits) {
+			t.Errorf("%d bits: byte rate = %d", bits, byteRate)
+		}
+		if blockAlign, bitsPerSample := binary.LittleEndian.Uint16(fmtChunk[12:14]), binary.LittleEndian.Uint16(fmtChunk[14:16]); blockAlign != uint16(bits) || bitsPerSample != uint16(bits) {
+			t.Errorf("%d bits: block align = %d, bits per sample = %d", bits, blockAlign, bitsPerSample)
+		}
+
+		parsed, err := audiocodec.NewWavFromBytes(file.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !parsed.Codec().IsEqual(audiocodec.G726Codec) || parsed.SampleCount() != 8_000 {
+			t.Errorf("%d bits: parsed %s of %d samples", bits, parsed.Codec().Preset(), parsed.SampleCount())
+		}
+		decoded, err := DecodeWav(parsed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !decoded.Codec().IsEqual(audiocodec.Pcm8kHz16bCodec) || decoded.SampleCount() != 8_000 {
+			t.Errorf("%d bits: decoded %s of %d samples", bits, decoded.Codec().Preset(), decoded.SampleCount())
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package g729
+
+/*
+#cgo pkg-config: libbcg729
+
+#include <bcg729/decoder.h>
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+// Decoder decompresses G.729 (including G.729A and Annex B) frames into 8 kHz 16-bit PCM.
+type Decoder struct {
+	decoder *C.bcg729DecoderChannelContextStruct
+	samples [FrameSampleCount]C.int16_t
+}
+
+func NewDecoder() (*Decoder, error) {
+	d := &Decoder{}
+	if err := d.init(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *Decoder) init() error {
+	if d.decoder = C.initBcg729DecoderChannel(); d.decoder == nil {
+		return errors.New("failed to create G.729 decoder")
+	}
+	return nil
+}
+
+// Decode returns PCM data of a speech or SID frame. An empty frame (not transmitted during silence or lost)
+// continues the comfort noise after a SID frame and is concealed after speech.
+func (d *Decoder) Decode(frame []byte) ([]byte, error) {
+	switch len(frame) {
+	case 0:
+		return d.Conceal(), nil
+	case SidFrameSize:
+		return d.decode(frame, false, true), nil
+	case FrameSize:
+		return d.decode(frame, false, false), nil
+	}
+	return nil, fmt.Errorf("%w: frame size %d", InvalidPayload, len(frame))
+}
+
+// DecodePayload returns PCM data of all frames of the RTP payload.
+func (d *Decoder) DecodePayload(payload []byte) ([]byte, error) {
+	frames, err := SplitPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var pcm []byte
+	for _, frame := range frames {
+		data, err := d.Decode(frame)
+		if err != nil {
+			return nil, err
+		}
+		pcm = append(pcm, data...)
+	}
+	return pcm, nil
+}
+
+// Conceal synthesizes the audio of a lost frame, the decoder chooses between concealment and comfort noise
+// by the previous frame.
+func (d *Decoder) Conceal() []byte {
+	return d.decode(nil, true, false)
+}
+
+func (d *Decoder) decode(frame []byte, erasure bool, sid bool) []byte {
+	var bitStream *C.uint8_t
+	if len(frame) > 0 {
+		bitStream = (*C.uint8_t)(unsafe.Pointer(&frame[0]))
+	}
+
+	C.bcg729Decoder(d.decoder, bitStream, C.uint8_t(len(frame)), boolValue(erasure), boolValue(sid), 0, &d.samples[0])
+
+	pcm := make([]byte, 2*FrameSampleCount)
+	for i, sample := range d.samples {
+		pcm[2*i] = byte(sample)
+		pcm[2*i+1] = byte(uint16(sample) >> 8)
+	}
+	return pcm
+}
+
+func (d *Decoder) Reset() error {
+	d.Free()
+	return d.init()
+}
+
+func (d *Decoder) Free() {
+	if d.decoder != nil {
+		C.closeBcg729DecoderChannel(d.decoder)
+		d.decoder = nil
+	}
+}
+
+func (d *Decoder) Codec() *audiocodec.Codec {
+	return audiocodec.G729Codec
+}
+
+func (d *Decoder) PcmCodec() *audiocodec.Codec {
+	return audiocodec.Pcm8kHz16bCodec
+}
+
+func boolValue(v bool) C.uint8_t {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,106 @@
+package g729
+
+/*
+#cgo pkg-config: libbcg729
+
+#include <bcg729/encoder.h>
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+// Encoder compresses 8 kHz 16-bit PCM into G.729A frames of 10 ms.
+type Encoder struct {
+	encoder *C.bcg729EncoderChannelContextStruct
+	vad     bool
+	buffer  []byte
+	samples [FrameSampleCount]C.int16_t
+	frame   [FrameSize]byte
+}
+
+// NewEncoder creates an encoder, with VAD enabled (Annex B) silence is coded with SID frames or not transmitted.
+func NewEncoder(vad bool) (*Encoder, error) {
+	e := &Encoder{vad: vad}
+	if err := e.init(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *Encoder) init() error {
+	var enableVad C.uint8_t
+	if e.vad {
+		enableVad = 1
+	}
+	if e.encoder = C.initBcg729EncoderChannel(enableVad); e.encoder == nil {
+		return errors.New("failed to create G.729 encoder")
+	}
+	return nil
+}
+
+// Encode buffers PCM data and returns the frames of all completed 10 ms blocks.
+// With VAD enabled frames of silence may be SID frames or empty ones which should not be sent.
+func (e *Encoder) Encode(data []byte) [][]byte {
+	e.buffer = append(e.buffer, data...)
+
+	frameBytes := e.PcmCodec().SizeBySampleCount(FrameSampleCount)
+	var frames [][]byte
+	for len(e.buffer) >= frameBytes {
+		frames = append(frames, e.encode(e.buffer[:frameBytes]))
+		e.buffer = e.buffer[frameBytes:]
+	}
+	e.buffer = append(e.buffer[:0:0], e.buffer...)
+
+	return frames
+}
+
+// Flush encodes the buffered incomplete block padded with silence.
+func (e *Encoder) Flush() []byte {
+	if len(e.buffer) == 0 {
+		return nil
+	}
+
+	block := make([]byte, e.PcmCodec().SizeBySampleCount(FrameSampleCount))
+	copy(block, e.buffer)
+	e.buffer = e.buffer[:0]
+
+	return e.encode(block)
+}
+
+func (e *Encoder) encode(block []byte) []byte {
+	for i := range e.samples {
+		e.samples[i] = C.int16_t(int16(block[2*i]) | int16(block[2*i+1])<<8)
+	}
+
+	var n C.uint8_t
+	C.bcg729Encoder(e.encoder, &e.samples[0], (*C.uint8_t)(unsafe.Pointer(&e.frame[0])), &n)
+
+	frame := make([]byte, int(n))
+	copy(frame, e.frame[:])
+	return frame
+}
+
+func (e *Encoder) Reset() error {
+	e.buffer = e.buffer[:0]
+	e.Free()
+	return e.init()
+}
+
+func (e *Encoder) Free() {
+	if e.encoder != nil {
+		C.closeBcg729EncoderChannel(e.encoder)
+		e.encoder = nil
+	}
+}
+
+func (e *Encoder) Codec() *audiocodec.Codec {
+	return audiocodec.G729Codec
+}
+
+func (e *Encoder) PcmCodec() *audiocodec.Codec {
+	return audiocodec.Pcm8kHz16bCodec
+}
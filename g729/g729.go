@@ -0,0 +1,43 @@
+package g729
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// FrameDuration is the duration of a frame
+	FrameDuration = 10 * time.Millisecond
+	// FrameSampleCount is the number of samples in a frame
+	FrameSampleCount = 80
+	// FrameSize is the size of a speech frame
+	FrameSize = 10
+	// SidFrameSize is the size of a SID frame of the Annex B discontinuous transmission
+	SidFrameSize = 2
+)
+
+var InvalidPayload = errors.New("invalid G.729 payload")
+
+// IsSid reports whether the frame is an Annex B SID frame carrying comfort noise parameters.
+func IsSid(frame []byte) bool {
+	return len(frame) == SidFrameSize
+}
+
+// SplitPayload splits the RTP payload into frames: zero or more speech frames optionally followed
+// by a single SID frame (RFC 3551 section 4.5.6). The frames refer to the payload.
+func SplitPayload(payload []byte) ([][]byte, error) {
+	var frames [][]byte
+	for len(payload) >= FrameSize {
+		frames = append(frames, payload[:FrameSize:FrameSize])
+		payload = payload[FrameSize:]
+	}
+	switch len(payload) {
+	case 0:
+	case SidFrameSize:
+		frames = append(frames, payload)
+	default:
+		return nil, fmt.Errorf("%w: %d trailing bytes", InvalidPayload, len(payload))
+	}
+	return frames, nil
+}
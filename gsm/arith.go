@@ -0,0 +1,109 @@
+package gsm
+
+import "math/bits"
+
+// Fixed point arithmetic of GSM 06.10 section 5.1: 16-bit words and 32-bit long words with saturation.
+
+const (
+	minWord = -32768
+	maxWord = 32767
+)
+
+func saturate(v int32) int16 {
+	return int16(min(max(v, minWord), maxWord))
+}
+
+func add(a int16, b int16) int16 {
+	return saturate(int32(a) + int32(b))
+}
+
+func sub(a int16, b int16) int16 {
+	return saturate(int32(a) - int32(b))
+}
+
+func mult(a int16, b int16) int16 {
+	if a == minWord && b == minWord {
+		return maxWord
+	}
+	return int16(int32(a) * int32(b) >> 15)
+}
+
+func multR(a int16, b int16) int16 {
+	if a == minWord && b == minWord {
+		return maxWord
+	}
+	return int16((int32(a)*int32(b) + 16384) >> 15)
+}
+
+func abs(a int16) int16 {
+	switch {
+	case a == minWord:
+		return maxWord
+	case a < 0:
+		return -a
+	}
+	return a
+}
+
+func lAdd(a int32, b int32) int32 {
+	return int32(min(max(int64(a)+int64(b), -1<<31), 1<<31-1))
+}
+
+// norm returns the number of left shifts needed to normalize a non-zero long word
+func norm(a int32) int {
+	if a < 0 {
+		if a <= -1073741824 {
+			return 0
+		}
+		a = ^a
+	}
+	return bits.LeadingZeros32(uint32(a)) - 1
+}
+
+func asl(a int16, n int) int16 {
+	switch {
+	case n >= 16:
+		return 0
+	case n <= -16:
+		if a < 0 {
+			return -1
+		}
+		return 0
+	case n < 0:
+		return asr(a, -n)
+	}
+	return a << n
+}
+
+func asr(a int16, n int) int16 {
+	switch {
+	case n >= 16:
+		if a < 0 {
+			return -1
+		}
+		return 0
+	case n <= -16:
+		return 0
+	case n < 0:
+		return a << -n
+	}
+	return a >> n
+}
+
+// div returns num/denum in Q15, 0 <= num <= denum
+func div(num int16, denum int16) int16 {
+	if num == 0 {
+		return 0
+	}
+	lNum, lDenum := int32(num), int32(denum)
+	var result int16
+	for k := 0; k < 15; k++ {
+		result <<= 1
+		lNum <<= 1
+		if lNum >= lDenum {
+			lNum -= lDenum
+			result++
+		}
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package gsm
+
+import (
+	"fmt"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+// Decoder decompresses GSM 06.10 frames into 8 kHz 16-bit PCM.
+type Decoder struct {
+	state   state
+	packing Packing
+}
+
+func NewDecoder(packing Packing) *Decoder {
+	return &Decoder{
+		state:   newState(),
+		packing: packing,
+	}
+}
+
+func (d *Decoder) Codec() *audiocodec.Codec {
+	return audiocodec.GsmCodec
+}
+
+func (d *Decoder) PcmCodec() *audiocodec.Codec {
+	return audiocodec.Pcm8kHz16bCodec
+}
+
+func (d *Decoder) Reset() {
+	*d = *NewDecoder(d.packing)
+}
+
+// FrameSize returns the size of the smallest unit of coded data: a frame or a pair of frames for WAV49.
+func (d *Decoder) FrameSize() int {
+	if d.packing == Wav49 {
+		return Wav49FrameSize
+	}
+	return FrameSize
+}
+
+// DecodeFrame appends the samples of the frames to dst as little-endian 16-bit PCM,
+// the size of data must be a multiple of FrameSize.
+func (d *Decoder) DecodeFrame(dst []byte, data []byte) ([]byte, error) {
+	frameSize := d.FrameSize()
+	if len(data)%frameSize != 0 {
+		return nil, fmt.Errorf("%w: %d", InvalidFrameSize, len(data))
+	}
+
+	for ; len(data) > 0; data = data[frameSize:] {
+		r := bitReader{data: data[:frameSize], lsbFirst: d.packing == Wav49}
+		frames := 2
+		if d.packing == Raw {
+			if r.read(4) != magic {
+				return nil, fmt.Errorf("%w: no signature", InvalidFrame)
+			}
+			frames = 1
+		}
+		for range frames {
+			var p parameters
+			p.fields(func(v *int16, bits int) {
+				*v = int16(r.read(bits))
+			})
+			dst = d.decode(dst, &p)
+		}
+	}
+	return dst, nil
+}
+
+func (d *Decoder) decode(dst []byte, p *parameters) []byte {
+	s := &d.state
+	var erp [40]int16
+	var wt [FrameSampleCount]int16
+	drp := s.dp0[:160]
+	for k := 0; k < 4; k++ {
+		rpeDecode(p.xmaxc[k], p.mc[k], &p.xmc[k], erp[:])
+		s.longTermSynthesis(p.nc[k], p.bc[k], erp[:], drp)
+		copy(wt[k*40:], drp[80:120])
+	}
+
+	var samples [FrameSampleCount]int16
+	s.shortTermSynthesis(&p.larc, wt[:], samples[:])
+
+	// de-emphasis filter, upscaling and truncation to 13 bits
+	msr := s.msr
+	for _, v := range samples {
+		msr = add(v, multR(msr, 28180))
+		sample := uint16(add(msr, msr)) & 0xFFF8
+		dst = append(dst, byte(sample), byte(sample>>8))
+	}
+	s.msr = msr
+	return dst
+}
@@ -0,0 +1,107 @@
+package gsm
+
+import "github.com/URALINNOVATSIYA/audiocodec"
+
+// Encoder compresses 8 kHz 16-bit PCM into GSM 06.10 frames.
+type Encoder struct {
+	state   state
+	packing Packing
+	buffer  []byte
+	// odd is the first frame of a WAV49 pair
+	odd *parameters
+}
+
+func NewEncoder(packing Packing) *Encoder {
+	return &Encoder{
+		state:   newState(),
+		packing: packing,
+	}
+}
+
+func (e *Encoder) Codec() *audiocodec.Codec {
+	return audiocodec.GsmCodec
+}
+
+func (e *Encoder) PcmCodec() *audiocodec.Codec {
+	return audiocodec.Pcm8kHz16bCodec
+}
+
+func (e *Encoder) Reset() {
+	*e = *NewEncoder(e.packing)
+}
+
+// encode returns the parameters of a frame of 160 samples
+func (e *Encoder) encode(samples []int16) *parameters {
+	var p parameters
+	var so [FrameSampleCount]int16
+	s := &e.state
+	s.preprocess(samples, so[:])
+	lpcAnalysis(so[:], &p.larc)
+	s.shortTermAnalysis(&p.larc, so[:])
+
+	var residual [50]int16
+	for k := 0; k < 4; k++ {
+		dp := s.dp0[k*40 : k*40+160]
+		p.nc[k], p.bc[k] = longTermAnalysis(so[k*40:k*40+40], dp, residual[5:45])
+		rpeEncode(residual[:], &p.xmaxc[k], &p.mc[k], &p.xmc[k])
+		for i := 0; i < 40; i++ {
+			dp[120+i] = add(residual[5+i], dp[120+i])
+		}
+	}
+	copy(s.dp0[:120], s.dp0[160:])
+	return &p
+}
+
+// EncodeFrame appends the frames of little-endian 16-bit PCM to dst. Samples not filling a whole frame
+// (or a pair of frames with WAV49 packing) are kept until the next call.
+func (e *Encoder) EncodeFrame(dst []byte, pcm []byte) []byte {
+	e.buffer = append(e.buffer, pcm...)
+
+	frameBytes := 2 * FrameSampleCount
+	var samples [FrameSampleCount]int16
+	n := 0
+	for ; n+frameBytes <= len(e.buffer); n += frameBytes {
+		for i := range samples {
+			samples[i] = int16(uint16(e.buffer[n+2*i]) | uint16(e.buffer[n+2*i+1])<<8)
+		}
+		dst = e.pack(dst, e.encode(samples[:]))
+	}
+	e.buffer = append(e.buffer[:0:0], e.buffer[n:]...)
+	return dst
+}
+
+// Flush encodes the buffered samples padded with silence up to a whole frame or a pair of frames.
+func (e *Encoder) Flush(dst []byte) []byte {
+	frameBytes := 2 * FrameSampleCount
+	if n := len(e.buffer) % frameBytes; n > 0 {
+		dst = e.EncodeFrame(dst, make([]byte, frameBytes-n))
+	}
+	if e.odd != nil {
+		dst = e.EncodeFrame(dst, make([]byte, frameBytes))
+	}
+	return dst
+}
+
+func (e *Encoder) pack(dst []byte, p *parameters) []byte {
+	if e.packing == Raw {
+		w := bitWriter{buf: dst}
+		w.write(magic, 4)
+		p.fields(func(v *int16, bits int) {
+			w.write(uint32(*v), bits)
+		})
+		return w.buf
+	}
+
+	if e.odd == nil {
+		e.odd = p
+		return dst
+	}
+	w := bitWriter{buf: dst, lsbFirst: true}
+	for _, frame := range []*parameters{e.odd, p} {
+		frame.fields(func(v *int16, bits int) {
+			w.write(uint32(*v), bits)
+		})
+	}
+	e.odd = nil
+	return w.buf
+}
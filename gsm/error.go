@@ -0,0 +1,8 @@
+package gsm
+
+import "errors"
+
+var (
+	InvalidFrame     = errors.New("invalid GSM frame")
+	InvalidFrameSize = errors.New("invalid GSM frame size")
+)
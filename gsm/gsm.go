@@ -0,0 +1,123 @@
+package gsm
+
+// The implementation follows the fixed-point arithmetic of ETSI GSM 06.10 full rate speech transcoding (RPE-LTP):
+// every 20 ms frame of 160 samples at 8 kHz is coded into 260 bits.
+
+const (
+	// FrameSampleCount is the number of samples in a frame
+	FrameSampleCount = 160
+	// FrameSize is the size of a frame packed as in RTP and .gsm files
+	FrameSize = 33
+	// Wav49FrameSize is the size of a pair of frames packed as in WAV files (WAV49)
+	Wav49FrameSize = 65
+
+	magic = 0xD
+)
+
+type Packing int
+
+// Raw - 33-byte frames starting with the 0xD signature, used in RTP (RFC 3551) and .gsm files.
+// Wav49 - Microsoft GSM 6.10 packing of two frames into 65 bytes without the signature, used in WAV files.
+const (
+	Raw Packing = iota
+	Wav49
+)
+
+// parameters are the coded parameters of a frame
+type parameters struct {
+	larc  [8]int16
+	nc    [4]int16
+	bc    [4]int16
+	mc    [4]int16
+	xmaxc [4]int16
+	xmc   [4][13]int16
+}
+
+var larBits = [8]int{6, 6, 5, 5, 4, 4, 3, 3}
+
+// fields visits the parameters in the order of the bit stream
+func (p *parameters) fields(visit func(v *int16, bits int)) {
+	for i := range p.larc {
+		visit(&p.larc[i], larBits[i])
+	}
+	for k := 0; k < 4; k++ {
+		visit(&p.nc[k], 7)
+		visit(&p.bc[k], 2)
+		visit(&p.mc[k], 2)
+		visit(&p.xmaxc[k], 6)
+		for i := range p.xmc[k] {
+			visit(&p.xmc[k][i], 3)
+		}
+	}
+}
+
+// bitWriter packs values either MSB-first (Raw) or LSB-first (Wav49)
+type bitWriter struct {
+	buf      []byte
+	bits     uint32
+	n        int
+	lsbFirst bool
+}
+
+func (w *bitWriter) write(v uint32, n int) {
+	v &= 1<<n - 1
+	if w.lsbFirst {
+		w.bits |= v << w.n
+	} else {
+		w.bits = w.bits<<n | v
+	}
+	w.n += n
+	for w.n >= 8 {
+		w.n -= 8
+		if w.lsbFirst {
+			w.buf = append(w.buf, byte(w.bits))
+			w.bits >>= 8
+		} else {
+			w.buf = append(w.buf, byte(w.bits>>w.n))
+		}
+	}
+}
+
+type bitReader struct {
+	data     []byte
+	bits     uint32
+	n        int
+	lsbFirst bool
+}
+
+func (r *bitReader) read(n int) uint32 {
+	for r.n < n {
+		if r.lsbFirst {
+			r.bits |= uint32(r.data[0]) << r.n
+		} else {
+			r.bits = r.bits<<8 | uint32(r.data[0])
+		}
+		r.data = r.data[1:]
+		r.n += 8
+	}
+	r.n -= n
+	if r.lsbFirst {
+		v := r.bits & (1<<n - 1)
+		r.bits >>= n
+		return v
+	}
+	return r.bits >> r.n & (1<<n - 1)
+}
+
+// state is the state of the encoder or the decoder
+type state struct {
+	dp0   [280]int16
+	z1    int16
+	lz2   int32
+	mp    int16
+	u     [8]int16
+	v     [9]int16
+	larpp [2][8]int16
+	j     int
+	nrp   int16
+	msr   int16
+}
+
+func newState() state {
+	return state{nrp: 40}
+}
@@ -0,0 +1,95 @@
+package gsm
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+)
+
+// silenceFrame is the frame of 160 zero samples coded by libgsm, Asterisk pads .gsm files with it
+var silenceFrame = []byte{
+	0xd8, 0x20, 0xa2, 0xe1, 0x5a, 0x50, 0x00, 0x49, 0x24, 0x92, 0x49, 0x24, 0x50, 0x00, 0x49, 0x24, 0x92, 0x49, 0x24,
+	0x50, 0x00, 0x49, 0x24, 0x92, 0x49, 0x24, 0x50, 0x00, 0x49, 0x24, 0x92, 0x49, 0x24,
+}
+
+// pcm returns n samples of a 500 Hz tone at 8 kHz as little-endian 16-bit PCM
+func pcm(n int) []byte {
+	b := make([]byte, 0, 2*n)
+	for i := 0; i < n; i++ {
+		s := int16(8_000 * math.Sin(2*math.Pi*500*float64(i)/8_000))
+		b = append(b, byte(s), byte(uint16(s)>>8))
+	}
+	return b
+}
+
+// snr returns the signal to noise ratio in dB of the decoded PCM
+func snr(pcm []byte, decoded []byte) float64 {
+	var signal, noise float64
+	for i := 0; i+1 < len(decoded); i += 2 {
+		s := float64(int16(uint16(pcm[i]) | uint16(pcm[i+1])<<8))
+		d := float64(int16(uint16(decoded[i])|uint16(decoded[i+1])<<8)) - s
+		signal += s * s
+		noise += d * d
+	}
+	return 10 * math.Log10(signal/noise)
+}
+
+func TestSilenceFrame(t *testing.T) {
+	frames := NewEncoder(Raw).EncodeFrame(nil, make([]byte, 4*FrameSampleCount))
+	if want := append(append([]byte(nil), silenceFrame...), silenceFrame...); !bytes.Equal(frames, want) {
+		t.Errorf("frames of silence = % x, want % x", frames, want)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := pcm(50 * FrameSampleCount)
+	// 50 frames or 25 pairs of frames
+	sizes := map[Packing]int{Raw: 50 * FrameSize, Wav49: 25 * Wav49FrameSize}
+	var decoded [][]byte
+	for _, packing := range []Packing{Raw, Wav49} {
+		frames := NewEncoder(packing).EncodeFrame(nil, in)
+		d := NewDecoder(packing)
+		if len(frames) != sizes[packing] {
+			t.Fatalf("packing %d: %d bytes of frames", packing, len(frames))
+		}
+		out, err := d.DecodeFrame(nil, frames)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(out) != len(in) {
+			t.Fatalf("packing %d: %d bytes decoded", packing, len(out))
+		}
+		// skip the adaptation of the first 40 ms
+		if s := snr(in[640:], out[640:]); s < 20 {
+			t.Errorf("packing %d: SNR = %.1f dB", packing, s)
+		}
+		decoded = append(decoded, out)
+	}
+	// the packings differ in the order of bits only
+	if !bytes.Equal(decoded[0], decoded[1]) {
+		t.Errorf("samples of Raw and Wav49 frames differ")
+	}
+}
+
+func TestFlush(t *testing.T) {
+	for _, packing := range []Packing{Raw, Wav49} {
+		e := NewEncoder(packing)
+		frames := e.Flush(e.EncodeFrame(nil, pcm(100)))
+		// the samples are padded up to a frame or a pair of frames
+		if size := NewDecoder(packing).FrameSize(); len(frames) != size {
+			t.Errorf("packing %d: %d bytes flushed, want %d", packing, len(frames), size)
+		}
+	}
+}
+
+func TestDecodeInvalidFrame(t *testing.T) {
+	d := NewDecoder(Raw)
+	if _, err := d.DecodeFrame(nil, silenceFrame[:32]); !errors.Is(err, InvalidFrameSize) {
+		t.Errorf("frame of 32 bytes: %v, want %v", err, InvalidFrameSize)
+	}
+	frame := append([]byte{0xC0}, silenceFrame[1:]...)
+	if _, err := d.DecodeFrame(nil, frame); !errors.Is(err, InvalidFrame) {
+		t.Errorf("frame without signature: %v, want %v", err, InvalidFrame)
+	}
+}
@@ -0,0 +1,93 @@
+package gsm
+
+var (
+	// decision levels and quantization levels of the LTP gain
+	dlb = [4]int16{6554, 16384, 26214, 32767}
+	qlb = [4]int16{3277, 11469, 21299, 32767}
+)
+
+// ltpParameters returns the LTP lag and the coded gain of the subframe d, dp is the reconstructed
+// short term residual where dp[120:] corresponds to d (section 4.2.11)
+func ltpParameters(d []int16, dp []int16) (nc int16, bc int16) {
+	var dmax int16
+	for _, v := range d[:40] {
+		dmax = max(dmax, abs(v))
+	}
+	temp := 0
+	if dmax != 0 {
+		temp = norm(int32(dmax) << 16)
+	}
+	scale := 0
+	if temp <= 6 {
+		scale = 6 - temp
+	}
+
+	var wt [40]int16
+	for k := range wt {
+		wt[k] = d[k] >> scale
+	}
+
+	// search of the maximum cross-correlation
+	var lmax int32
+	nc = 40
+	for lambda := 40; lambda <= 120; lambda++ {
+		var result int32
+		for k := range wt {
+			result += int32(wt[k]) * int32(dp[120+k-lambda])
+		}
+		if result > lmax {
+			nc = int16(lambda)
+			lmax = result
+		}
+	}
+	lmax <<= 1
+	lmax >>= 6 - scale
+
+	var power int32
+	for k := 0; k < 40; k++ {
+		v := int32(dp[120+k-int(nc)] >> 3)
+		power += v * v
+	}
+	power <<= 1
+
+	if lmax <= 0 {
+		return nc, 0
+	}
+	if lmax >= power {
+		return nc, 3
+	}
+	shift := norm(power)
+	r := int16(lmax << shift >> 16)
+	s := int16(power << shift >> 16)
+	for bc = 0; bc <= 2; bc++ {
+		if r <= mult(s, dlb[bc]) {
+			break
+		}
+	}
+	return nc, bc
+}
+
+// longTermAnalysis computes the LTP parameters, the estimate dpp and the long term residual e of the subframe
+// (section 4.2.11 - 4.2.12), dp[120:160] receives dpp
+func longTermAnalysis(d []int16, dp []int16, e []int16) (nc int16, bc int16) {
+	nc, bc = ltpParameters(d, dp)
+	for k := 0; k < 40; k++ {
+		dp[120+k] = multR(qlb[bc], dp[120+k-int(nc)])
+		e[k] = sub(d[k], dp[120+k])
+	}
+	return nc, bc
+}
+
+// longTermSynthesis reconstructs the short term residual of the subframe into drp[120:160] and shifts
+// drp by a subframe (section 4.3.2)
+func (s *state) longTermSynthesis(nc int16, bc int16, erp []int16, drp []int16) {
+	if nc < 40 || nc > 120 {
+		nc = s.nrp
+	}
+	s.nrp = nc
+	brp := qlb[bc]
+	for k := 0; k < 40; k++ {
+		drp[120+k] = add(erp[k], multR(brp, drp[120+k-int(nc)]))
+	}
+	copy(drp[:120], drp[40:160])
+}
@@ -0,0 +1,149 @@
+package gsm
+
+// preprocess downscales the input, removes the offset and applies the pre-emphasis filter (section 4.2.1 - 4.2.3)
+func (s *state) preprocess(samples []int16, so []int16) {
+	z1, lz2, mp := s.z1, s.lz2, s.mp
+	for k, sample := range samples {
+		so0 := sample >> 3 << 2
+
+		s1 := so0 - z1
+		z1 = so0
+		ls2 := int32(s1) << 15
+		msp := int16(lz2 >> 15)
+		lsp := int16(lz2 - int32(msp)<<15)
+		ls2 += int32(multR(lsp, 32735))
+		lz2 = lAdd(int32(msp)*32735, ls2)
+
+		temp := lAdd(lz2, 16384)
+		msp = multR(mp, -28180)
+		mp = int16(temp >> 15)
+		so[k] = add(mp, msp)
+	}
+	s.z1, s.lz2, s.mp = z1, lz2, mp
+}
+
+// lpcAnalysis computes the coded log area ratios of the frame (section 4.2.4 - 4.2.7)
+func lpcAnalysis(so []int16, larc *[8]int16) {
+	var acf [9]int32
+	autocorrelation(so, &acf)
+	reflectionCoefficients(&acf, larc)
+	toLogAreaRatios(larc)
+	quantizeLogAreaRatios(larc)
+}
+
+func autocorrelation(s []int16, acf *[9]int32) {
+	var smax int16
+	for _, v := range s[:160] {
+		smax = max(smax, abs(v))
+	}
+	scale := 0
+	if smax != 0 {
+		scale = 4 - norm(int32(smax)<<16)
+	}
+	if scale > 0 {
+		factor := int16(16384 >> (scale - 1))
+		for k := range s[:160] {
+			s[k] = multR(s[k], factor)
+		}
+	}
+
+	for i := 0; i < 160; i++ {
+		for k := 0; k <= min(i, 8); k++ {
+			acf[k] += int32(s[i]) * int32(s[i-k])
+		}
+	}
+	for k := range acf {
+		acf[k] <<= 1
+	}
+
+	if scale > 0 {
+		for k := range s[:160] {
+			s[k] <<= scale
+		}
+	}
+}
+
+// reflectionCoefficients uses the Schur recursion
+func reflectionCoefficients(lacf *[9]int32, r *[8]int16) {
+	if lacf[0] == 0 {
+		*r = [8]int16{}
+		return
+	}
+
+	var acf, p, k [9]int16
+	shift := norm(lacf[0])
+	for i := range acf {
+		acf[i] = int16(lacf[i] << shift >> 16)
+	}
+	copy(k[1:8], acf[1:8])
+	p = acf
+
+	for n := 1; n <= 8; n++ {
+		temp := abs(p[1])
+		if p[0] < temp {
+			for i := n - 1; i < 8; i++ {
+				r[i] = 0
+			}
+			return
+		}
+		rn := div(temp, p[0])
+		if p[1] > 0 {
+			rn = -rn
+		}
+		r[n-1] = rn
+		if n == 8 {
+			return
+		}
+
+		p[0] = add(p[0], multR(p[1], rn))
+		for m := 1; m <= 8-n; m++ {
+			p[m] = add(p[m+1], multR(k[m], rn))
+			k[m] = add(k[m], multR(p[m+1], rn))
+		}
+	}
+}
+
+func toLogAreaRatios(r *[8]int16) {
+	for i, v := range r {
+		temp := abs(v)
+		switch {
+		case temp < 22118:
+			temp >>= 1
+		case temp < 31130:
+			temp -= 11059
+		default:
+			temp = (temp - 26112) << 2
+		}
+		if v < 0 {
+			temp = -temp
+		}
+		r[i] = temp
+	}
+}
+
+var larQuantization = [8]struct {
+	a, b, mac, mic int16
+}{
+	{20480, 0, 31, -32},
+	{20480, 0, 31, -32},
+	{20480, 2048, 15, -16},
+	{20480, -2560, 15, -16},
+	{13964, 94, 7, -8},
+	{15360, -1792, 7, -8},
+	{8534, -341, 3, -4},
+	{9036, -1144, 3, -4},
+}
+
+func quantizeLogAreaRatios(lar *[8]int16) {
+	for i, q := range larQuantization {
+		temp := add(add(mult(q.a, lar[i]), q.b), 256) >> 9
+		switch {
+		case temp > q.mac:
+			lar[i] = q.mac - q.mic
+		case temp < q.mic:
+			lar[i] = 0
+		default:
+			lar[i] = temp - q.mic
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package gsm
+
+var (
+	// impulse response of the weighting filter
+	weightingFilter = [11]int32{-134, -374, 0, 2054, 5741, 8192, 5741, 2054, 0, -374, -134}
+	// normalized inverse mantissa and mantissa of the block maximum
+	nrfac = [8]int16{29128, 26215, 23832, 21846, 20165, 18725, 17476, 16384}
+	fac   = [8]int16{18431, 20479, 22527, 24575, 26623, 28671, 30719, 32767}
+)
+
+// rpeEncode codes the long term residual e[5:45] of the subframe, e[0:5] and e[45:50] must be zero.
+// The residual is replaced by its quantized version (section 4.2.13 - 4.2.18).
+func rpeEncode(e []int16, xmaxc *int16, mc *int16, xmc *[13]int16) {
+	var x [40]int16
+	for k := range x {
+		result := int32(4096)
+		for i, h := range weightingFilter {
+			result += int32(e[k+i]) * h
+		}
+		x[k] = saturate(result >> 13)
+	}
+
+	// grid selection
+	var em int32
+	*mc = 0
+	for m := 0; m < 4; m++ {
+		var result int32
+		for i := 0; m+3*i < 40; i++ {
+			v := int32(x[m+3*i] >> 2)
+			result += v * v
+		}
+		result <<= 1
+		if m == 0 || result > em {
+			*mc = int16(m)
+			em = result
+		}
+	}
+	var xm [13]int16
+	for i := range xm {
+		xm[i] = x[int(*mc)+3*i]
+	}
+
+	// APCM quantization of the block maximum and the samples
+	var xmax int16
+	for _, v := range xm {
+		xmax = max(xmax, abs(v))
+	}
+	exp := int16(0)
+	temp := xmax >> 9
+	itest := false
+	for i := 0; i <= 5; i++ {
+		itest = itest || temp <= 0
+		temp >>= 1
+		if !itest {
+			exp++
+		}
+	}
+	*xmaxc = add(xmax>>(exp+5), exp<<3)
+
+	exp, mant := expMant(*xmaxc)
+	shift := 6 - exp
+	for i, v := range xm {
+		xmc[i] = mult(v<<shift, nrfac[mant])>>12 + 4
+	}
+
+	var xmp [13]int16
+	dequantize(xmc, mant, exp, &xmp)
+	gridPosition(*mc, &xmp, e[5:45])
+}
+
+// rpeDecode restores the long term residual erp of the subframe (section 4.3.1)
+func rpeDecode(xmaxc int16, mc int16, xmc *[13]int16, erp []int16) {
+	exp, mant := expMant(xmaxc)
+	var xmp [13]int16
+	dequantize(xmc, mant, exp, &xmp)
+	gridPosition(mc, &xmp, erp)
+}
+
+// expMant returns the exponent and the mantissa of the coded block maximum
+func expMant(xmaxc int16) (exp int16, mant int16) {
+	if xmaxc > 15 {
+		exp = xmaxc>>3 - 1
+	}
+	mant = xmaxc - exp<<3
+	if mant == 0 {
+		return -4, 7
+	}
+	for mant <= 7 {
+		mant = mant<<1 | 1
+		exp--
+	}
+	return exp, mant - 8
+}
+
+func dequantize(xmc *[13]int16, mant int16, exp int16, xmp *[13]int16) {
+	temp1 := fac[mant]
+	temp2 := sub(6, exp)
+	temp3 := asl(1, int(sub(temp2, 1)))
+	for i, v := range xmc {
+		temp := (v<<1 - 7) << 12
+		temp = add(multR(temp1, temp), temp3)
+		xmp[i] = asr(temp, int(temp2))
+	}
+}
+
+func gridPosition(mc int16, xmp *[13]int16, ep []int16) {
+	clear(ep[:40])
+	for i, v := range xmp {
+		ep[int(mc)+3*i] = v
+	}
+}
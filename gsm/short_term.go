@@ -0,0 +1,109 @@
+package gsm
+
+var larDecoding = [8]struct {
+	b, mic, inva int16
+}{
+	{0, -32, 13107},
+	{0, -32, 13107},
+	{2048, -16, 13107},
+	{-2560, -16, 13107},
+	{94, -8, 19223},
+	{-1792, -8, 17476},
+	{-341, -4, 31454},
+	{-1144, -4, 29708},
+}
+
+func decodeLogAreaRatios(larc *[8]int16, larpp *[8]int16) {
+	for i, d := range larDecoding {
+		temp := add(larc[i], d.mic) << 10
+		temp = sub(temp, d.b<<1)
+		temp = multR(d.inva, temp)
+		larpp[i] = add(temp, temp)
+	}
+}
+
+// interpolate computes the log area ratios of a segment of the frame from the previous and current ones
+func interpolate(segment int, prev *[8]int16, cur *[8]int16, larp *[8]int16) {
+	for i := range larp {
+		switch segment {
+		case 0:
+			larp[i] = add(add(prev[i]>>2, cur[i]>>2), prev[i]>>1)
+		case 1:
+			larp[i] = add(prev[i]>>1, cur[i]>>1)
+		case 2:
+			larp[i] = add(add(prev[i]>>2, cur[i]>>2), cur[i]>>1)
+		default:
+			larp[i] = cur[i]
+		}
+	}
+}
+
+// larpToRp converts the log area ratios to the reflection coefficients
+func larpToRp(larp *[8]int16) {
+	for i, v := range larp {
+		temp := abs(v)
+		switch {
+		case temp < 11059:
+			temp <<= 1
+		case temp < 20070:
+			temp += 11059
+		default:
+			temp = add(temp>>2, 26112)
+		}
+		if v < 0 {
+			temp = -temp
+		}
+		larp[i] = temp
+	}
+}
+
+// segments of the frame with different interpolation of coefficients
+var segments = [4][2]int{{0, 13}, {13, 27}, {27, 40}, {40, 160}}
+
+// shortTermAnalysis replaces the preprocessed samples with the short term residual (section 4.2.8 - 4.2.10)
+func (s *state) shortTermAnalysis(larc *[8]int16, samples []int16) {
+	prev := &s.larpp[s.j]
+	s.j ^= 1
+	cur := &s.larpp[s.j]
+	decodeLogAreaRatios(larc, cur)
+
+	var rp [8]int16
+	for i, segment := range segments {
+		interpolate(i, prev, cur, &rp)
+		larpToRp(&rp)
+		for k := segment[0]; k < segment[1]; k++ {
+			di := samples[k]
+			sav := di
+			for j := range rp {
+				ui := s.u[j]
+				s.u[j] = sav
+				sav = add(ui, multR(rp[j], di))
+				di = add(di, multR(rp[j], ui))
+			}
+			samples[k] = di
+		}
+	}
+}
+
+// shortTermSynthesis restores the signal from the short term residual wt (section 4.3.2 - 4.3.4)
+func (s *state) shortTermSynthesis(larc *[8]int16, wt []int16, samples []int16) {
+	prev := &s.larpp[s.j]
+	s.j ^= 1
+	cur := &s.larpp[s.j]
+	decodeLogAreaRatios(larc, cur)
+
+	var rrp [8]int16
+	for i, segment := range segments {
+		interpolate(i, prev, cur, &rrp)
+		larpToRp(&rrp)
+		for k := segment[0]; k < segment[1]; k++ {
+			sri := wt[k]
+			for j := 7; j >= 0; j-- {
+				sri = sub(sri, multR(rrp[j], s.v[j]))
+				s.v[j+1] = add(s.v[j], multR(rrp[j], sri))
+			}
+			s.v[0] = sri
+			samples[k] = sri
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package gsm
+
+import (
+	"fmt"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+// DecodeWav decodes a mono WAV of GSM 06.10 (WAV49) into 16-bit PCM.
+func DecodeWav(wav *audiocodec.Wav) (*audiocodec.Wav, error) {
+	if wav.Codec().Name != audiocodec.Gsm {
+		return nil, fmt.Errorf("%w: %s", audiocodec.UnsupportedFormat, wav.Codec().Name)
+	}
+
+	d := NewDecoder(Wav49)
+	data := wav.Data()
+	// a truncated last block is dropped
+	pcm, err := d.DecodeFrame(nil, data[:len(data)/Wav49FrameSize*Wav49FrameSize])
+	if err != nil {
+		return nil, err
+	}
+
+	result := audiocodec.NewWav(d.PcmCodec())
+	if _, err = result.Write(pcm); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// EncodeWav encodes a mono WAV of 8 kHz 16-bit PCM into GSM 06.10 (WAV49).
+func EncodeWav(wav *audiocodec.Wav) (*audiocodec.Wav, error) {
+	e := NewEncoder(Wav49)
+	if !wav.Codec().IsEqual(e.PcmCodec()) || wav.Channels() != 1 {
+		return nil, fmt.Errorf("%w: %s", audiocodec.UnsupportedFormat, wav.Codec().Preset())
+	}
+
+	result := audiocodec.NewWavWithBlockAlign(e.Codec(), 1, Wav49FrameSize)
+	if _, err := result.Write(e.Flush(e.EncodeFrame(nil, wav.Data()))); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,37 @@
+package gsm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+func TestWavRoundTrip(t *testing.T) {
+	wav := audiocodec.NewWav(audiocodec.Pcm8kHz16bCodec)
+	// the last pair of frames is padded with silence
+	wav.Write(pcm(1_000))
+	encoded, err := EncodeWav(wav)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file bytes.Buffer
+	if _, err = encoded.WriteTo(&file); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := audiocodec.NewWavFromBytes(file.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.Codec().IsEqual(audiocodec.GsmCodec) || parsed.BlockAlign() != Wav49FrameSize || parsed.SampleCount() != 1_280 {
+		t.Errorf("parsed %s, block align %d, %d samples", parsed.Codec().Preset(), parsed.BlockAlign(), parsed.SampleCount())
+	}
+	decoded, err := DecodeWav(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Codec().IsEqual(audiocodec.Pcm8kHz16bCodec) || decoded.SampleCount() != 1_280 {
+		t.Errorf("decoded %s of %d samples", decoded.Codec().Preset(), decoded.SampleCount())
+	}
+}
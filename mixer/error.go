@@ -0,0 +1,8 @@
+package mixer
+
+import "errors"
+
+var (
+	ParticipantExists   = errors.New("participant already exists")
+	ParticipantNotFound = errors.New("participant not found")
+)
@@ -0,0 +1,35 @@
+package mixer
+
+import "math"
+
+// Limiter keeps the sum of the participants within the full scale.
+type Limiter func(sample float32) float32
+
+// Saturate clips the sum at the full scale.
+func Saturate(sample float32) float32 {
+	if sample > 1 {
+		return 1
+	}
+	if sample < -1 {
+		return -1
+	}
+	return sample
+}
+
+// softKnee is the level above which SoftLimit starts to compress the signal
+const softKnee = 0.7
+
+// SoftLimit passes quiet signals unchanged and smoothly compresses everything above the knee,
+// so loud simultaneous talkers never reach the full scale.
+func SoftLimit(sample float32) float32 {
+	v := math.Abs(float64(sample))
+	if v <= softKnee {
+		return sample
+	}
+
+	v = softKnee + (1-softKnee)*math.Tanh((v-softKnee)/(1-softKnee))
+	if sample < 0 {
+		return float32(-v)
+	}
+	return float32(v)
+}
@@ -0,0 +1,39 @@
+package mixer
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSaturate(t *testing.T) {
+	for _, tt := range []struct{ in, out float32 }{{0.5, 0.5}, {-0.5, -0.5}, {1.5, 1}, {-2, -1}} {
+		if got := Saturate(tt.in); got != tt.out {
+			t.Errorf("Saturate(%v) = %v, want %v", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestSoftLimit(t *testing.T) {
+	// unchanged below the knee
+	for _, v := range []float32{0, 0.3, -0.7, softKnee} {
+		if got := SoftLimit(v); got != v {
+			t.Errorf("SoftLimit(%v) = %v", v, got)
+		}
+	}
+
+	// monotonic, odd and within the full scale above the knee
+	previous := SoftLimit(softKnee)
+	for v := float32(softKnee + 0.01); v < 4; v += 0.01 {
+		got := SoftLimit(v)
+		if got < previous || got > 1 || SoftLimit(-v) != -got {
+			t.Fatalf("SoftLimit(%v) = %v, SoftLimit(%v) = %v", v, got, -v, SoftLimit(-v))
+		}
+		previous = got
+	}
+
+	// the slope is continuous at the knee
+	const h = 1e-4
+	if slope := float64(SoftLimit(softKnee+h)-SoftLimit(softKnee)) / h; math.Abs(slope-1) > 0.01 {
+		t.Errorf("slope above the knee = %v, want 1", slope)
+	}
+}
@@ -0,0 +1,246 @@
+package mixer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/pcm"
+	"github.com/URALINNOVATSIYA/audiocodec/resample"
+)
+
+// maxQueuedFrames bounds the audio buffered for a participant, older samples are dropped when a burst of late
+// frames arrives so the latency does not grow
+const maxQueuedFrames = 5
+
+type participant struct {
+	codec    *audiocodec.Codec
+	incoming *resample.Converter // participant codec to the mixer codec
+	outgoing *resample.Converter // mixer codec to the participant codec
+
+	queue     []float32 // samples in the mixer codec waiting to be mixed
+	current   []float32 // samples mixed in the current frame
+	output    []byte    // converted mix-minus waiting to fill a whole frame
+	frameSize int       // output frame size in bytes
+	silence   []byte    // a single silent sample in the participant codec
+}
+
+// Mixer bridges N participants: every participant hears the sum of all the others (mix-minus).
+// Frames are pushed by participants in their own codecs at any moment, Mix is called once per frame duration
+// and returns a frame of the same duration for every participant in its codec.
+// A participant without enough audio for the frame (late or lost packets) is mixed with silence.
+type Mixer struct {
+	mu            *sync.Mutex
+	codec         *audiocodec.Codec
+	frameDuration time.Duration
+	frameSize     int // samples
+	factory       resample.Factory
+	limiter       Limiter
+	participants  map[string]*participant
+
+	decoder func(sample []byte) float32
+	encoder func(sample float32, buf []byte)
+	mix     []float32
+}
+
+// NewMixer creates a mixer summing participants in the codec, which has to be 16 or 32-bit PCM.
+// The factory is used for participants with other sample rates and may be nil if there are none.
+func NewMixer(codec *audiocodec.Codec, frameDuration time.Duration, factory resample.Factory) (*Mixer, error) {
+	if !codec.IsPcm() {
+		return nil, audiocodec.NotPcm
+	}
+
+	decoder, err := pcm.Decoder(codec)
+	if err != nil {
+		return nil, err
+	}
+	encoder, err := pcm.Encoder(codec)
+	if err != nil {
+		return nil, err
+	}
+
+	frameSize := codec.SampleCountByDuration(frameDuration)
+	return &Mixer{
+		mu:            &sync.Mutex{},
+		codec:         codec,
+		frameDuration: frameDuration,
+		frameSize:     frameSize,
+		factory:       factory,
+		limiter:       SoftLimit,
+		participants:  make(map[string]*participant),
+		decoder:       decoder,
+		encoder:       encoder,
+		mix:           make([]float32, frameSize),
+	}, nil
+}
+
+// SetLimiter replaces the default SoftLimit.
+func (m *Mixer) SetLimiter(limiter Limiter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.limiter = limiter
+}
+
+func (m *Mixer) Add(id string, codec *audiocodec.Codec) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.participants[id]; exists {
+		return fmt.Errorf("%w: %s", ParticipantExists, id)
+	}
+
+	incoming, err := resample.NewConverter(codec, m.codec, m.factory)
+	if err != nil {
+		return err
+	}
+	outgoing, err := resample.NewConverter(m.codec, codec, m.factory)
+	if err != nil {
+		_ = incoming.Free()
+		return err
+	}
+
+	silence, err := pcm.Encode(codec, nil, []float32{0})
+	if err != nil {
+		_ = incoming.Free()
+		_ = outgoing.Free()
+		return err
+	}
+
+	m.participants[id] = &participant{
+		codec:     codec,
+		incoming:  incoming,
+		outgoing:  outgoing,
+		current:   make([]float32, m.frameSize),
+		frameSize: codec.Size(m.frameDuration),
+		silence:   silence,
+	}
+
+	return nil
+}
+
+func (m *Mixer) Remove(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, exists := m.participants[id]
+	if !exists {
+		return fmt.Errorf("%w: %s", ParticipantNotFound, id)
+	}
+	delete(m.participants, id)
+
+	if err := p.incoming.Free(); err != nil {
+		return err
+	}
+	return p.outgoing.Free()
+}
+
+// Push queues a frame received from the participant.
+func (m *Mixer) Push(id string, frame []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, exists := m.participants[id]
+	if !exists {
+		return fmt.Errorf("%w: %s", ParticipantNotFound, id)
+	}
+
+	converted, err := p.incoming.Convert(frame)
+	if err != nil {
+		return err
+	}
+
+	sampleSize := m.codec.SampleSize()
+	for i := 0; i+sampleSize <= len(converted); i += sampleSize {
+		p.queue = append(p.queue, m.decoder(converted[i:i+sampleSize]))
+	}
+	if excess := len(p.queue) - maxQueuedFrames*m.frameSize; excess > 0 {
+		p.queue = append(p.queue[:0], p.queue[excess:]...)
+	}
+
+	return nil
+}
+
+// Mix produces the next frame for every participant.
+func (m *Mixer) Mix() (map[string][]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clear(m.mix)
+	for _, p := range m.participants {
+		n := copy(p.current, p.queue)
+		clear(p.current[n:])
+		p.queue = append(p.queue[:0], p.queue[n:]...)
+
+		for i, sample := range p.current {
+			m.mix[i] += sample
+		}
+	}
+
+	sampleSize := m.codec.SampleSize()
+	frame := make([]byte, m.frameSize*sampleSize)
+	outputs := make(map[string][]byte, len(m.participants))
+	for id, p := range m.participants {
+		for i, sample := range p.current {
+			m.encoder(m.limiter(m.mix[i]-sample), frame[i*sampleSize:(i+1)*sampleSize])
+		}
+
+		converted, err := p.outgoing.Convert(frame)
+		if err != nil {
+			return nil, err
+		}
+		outputs[id] = p.frame(converted)
+	}
+
+	return outputs, nil
+}
+
+// Participants returns the number of participants.
+func (m *Mixer) Participants() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.participants)
+}
+
+// Free releases the resamplers of all participants.
+func (m *Mixer) Free() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, p := range m.participants {
+		delete(m.participants, id)
+		if err := p.incoming.Free(); err != nil {
+			return err
+		}
+		if err := p.outgoing.Free(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Mixer) Codec() *audiocodec.Codec {
+	return m.codec
+}
+
+// frame cuts a whole output frame, a resampler delay at the beginning of the stream is filled with silence
+func (p *participant) frame(converted []byte) []byte {
+	p.output = append(p.output, converted...)
+
+	frame := make([]byte, p.frameSize)
+	if len(p.output) >= p.frameSize {
+		copy(frame, p.output)
+		p.output = append(p.output[:0], p.output[p.frameSize:]...)
+		return frame
+	}
+
+	padding := p.frameSize - len(p.output)
+	for i := 0; i < padding; i += len(p.silence) {
+		copy(frame[i:], p.silence)
+	}
+	copy(frame[padding:], p.output)
+	p.output = p.output[:0]
+	return frame
+}
@@ -0,0 +1,205 @@
+package mixer
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/pcm"
+	"github.com/URALINNOVATSIYA/audiocodec/resample"
+)
+
+const frameDuration = 20 * time.Millisecond
+
+// constant returns a frame of the codec where every sample has the value
+func constant(t *testing.T, codec *audiocodec.Codec, value float32) []byte {
+	t.Helper()
+	samples := make([]float32, codec.SampleCountByDuration(frameDuration))
+	for i := range samples {
+		samples[i] = value
+	}
+	frame, err := pcm.Encode(codec, nil, samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return frame
+}
+
+// checkConstant checks that every sample of the frame is close to the value
+func checkConstant(t *testing.T, id string, codec *audiocodec.Codec, frame []byte, value float32, tolerance float64) {
+	t.Helper()
+	if want := codec.Size(frameDuration); len(frame) != want {
+		t.Errorf("%s: frame size = %d, want %d", id, len(frame), want)
+		return
+	}
+	samples, err := pcm.Decode(codec, nil, frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range samples {
+		if math.Abs(float64(s-value)) > tolerance {
+			t.Errorf("%s: sample %d = %v, want %v", id, i, s, value)
+			return
+		}
+	}
+}
+
+func TestMixMinus(t *testing.T) {
+	m, err := NewMixer(audiocodec.Pcm8kHz16bCodec, frameDuration, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Free()
+
+	participants := map[string]struct {
+		codec *audiocodec.Codec
+		value float32
+	}{
+		"a": {audiocodec.Pcm8kHz16bCodec, 0.1},
+		"b": {audiocodec.PcmA8kHz8bCodec, 0.2},
+		"c": {audiocodec.PcmU8kHz8bCodec, 0.3},
+	}
+	for id, p := range participants {
+		if err = m.Add(id, p.codec); err != nil {
+			t.Fatal(err)
+		}
+		if err = m.Push(id, constant(t, p.codec, p.value)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if m.Participants() != 3 {
+		t.Errorf("participants = %d, want 3", m.Participants())
+	}
+
+	// everybody hears the others, G.711 quantization is up to 1/32 in this range
+	outputs, err := m.Mix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, p := range participants {
+		checkConstant(t, id, p.codec, outputs[id], 0.6-p.value, 0.02)
+	}
+
+	// nothing is pushed for the next frame, so everyone is mixed with silence
+	if outputs, err = m.Mix(); err != nil {
+		t.Fatal(err)
+	}
+	for id, p := range participants {
+		checkConstant(t, id, p.codec, outputs[id], 0, 0.001)
+	}
+}
+
+func TestMixLateFrames(t *testing.T) {
+	codec := audiocodec.Pcm8kHz16bCodec
+	m, err := NewMixer(codec, frameDuration, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Free()
+	for _, id := range []string{"a", "b"} {
+		if err = m.Add(id, codec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a burst of late frames is limited to the newest ones
+	for i := 1; i <= maxQueuedFrames+2; i++ {
+		if err = m.Push("a", constant(t, codec, float32(i)/100)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 3; i <= maxQueuedFrames+2; i++ {
+		outputs, err := m.Mix()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkConstant(t, "b", codec, outputs["b"], float32(i)/100, 0.0001)
+		checkConstant(t, "a", codec, outputs["a"], 0, 0)
+	}
+}
+
+func TestMixResampled(t *testing.T) {
+	// the resampler repeats or drops samples, so a constant frame stays constant
+	factory := func(incomingCodec *audiocodec.Codec, outgoingCodec *audiocodec.Codec) (resample.Resampler, error) {
+		return &nearest{incoming: incomingCodec, outgoing: outgoingCodec}, nil
+	}
+	m, err := NewMixer(audiocodec.Pcm16kHz16bCodec, frameDuration, factory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Free()
+
+	if err = m.Add("wideband", audiocodec.Pcm16kHz16bCodec); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Add("narrowband", audiocodec.PcmA8kHz8bCodec); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Push("wideband", constant(t, audiocodec.Pcm16kHz16bCodec, 0.25)); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Push("narrowband", constant(t, audiocodec.PcmA8kHz8bCodec, 0.5)); err != nil {
+		t.Fatal(err)
+	}
+
+	outputs, err := m.Mix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the narrowband participant is heard through A-law
+	checkConstant(t, "wideband", audiocodec.Pcm16kHz16bCodec, outputs["wideband"], 0.5, 0.02)
+	checkConstant(t, "narrowband", audiocodec.PcmA8kHz8bCodec, outputs["narrowband"], 0.25, 0.01)
+
+	m.factory = nil
+	if err = m.Add("no factory", audiocodec.Pcm8kHz16bCodec); !errors.Is(err, resample.ResamplerRequired) {
+		t.Errorf("Add = %v, want %v", err, resample.ResamplerRequired)
+	}
+}
+
+func TestMixerErrors(t *testing.T) {
+	if _, err := NewMixer(audiocodec.PcmA8kHz8bCodec, frameDuration, nil); !errors.Is(err, audiocodec.NotPcm) {
+		t.Errorf("NewMixer = %v, want %v", err, audiocodec.NotPcm)
+	}
+
+	m, err := NewMixer(audiocodec.Pcm8kHz16bCodec, frameDuration, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Add("a", audiocodec.Pcm8kHz16bCodec); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Add("a", audiocodec.Pcm8kHz16bCodec); !errors.Is(err, ParticipantExists) {
+		t.Errorf("Add = %v, want %v", err, ParticipantExists)
+	}
+	if err = m.Push("b", nil); !errors.Is(err, ParticipantNotFound) {
+		t.Errorf("Push = %v, want %v", err, ParticipantNotFound)
+	}
+	if err = m.Remove("b"); !errors.Is(err, ParticipantNotFound) {
+		t.Errorf("Remove = %v, want %v", err, ParticipantNotFound)
+	}
+	if err = m.Remove("a"); err != nil || m.Participants() != 0 {
+		t.Errorf("Remove = %v, participants %d", err, m.Participants())
+	}
+}
+
+// nearest is a resampler picking the nearest preceding sample
+type nearest struct {
+	incoming, outgoing *audiocodec.Codec
+}
+
+func (r *nearest) Resample(incomingData []byte) ([]byte, error) {
+	size := r.incoming.SampleSize()
+	count := len(incomingData) / size * r.outgoing.SampleRate / r.incoming.SampleRate
+	out := make([]byte, 0, count*size)
+	for i := 0; i < count; i++ {
+		j := i * r.incoming.SampleRate / r.outgoing.SampleRate * size
+		out = append(out, incomingData[j:j+size]...)
+	}
+	return out, nil
+}
+
+func (r *nearest) Flush() ([]byte, error) {
+	return nil, nil
+}
@@ -0,0 +1,9 @@
+package mp3
+
+import "errors"
+
+var (
+	InvalidFrameHeader = errors.New("invalid MPEG audio frame header")
+	FreeFormatBitRate  = errors.New("free format bit rate is not supported")
+	FrameNotFound      = errors.New("MPEG audio frame not found")
+)
@@ -0,0 +1,170 @@
+package mp3
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+type Version int
+
+const (
+	Mpeg25 Version = 0
+	Mpeg2  Version = 2
+	Mpeg1  Version = 3
+)
+
+type ChannelMode int
+
+const (
+	Stereo      ChannelMode = 0
+	JointStereo ChannelMode = 1
+	DualChannel ChannelMode = 2
+	Mono        ChannelMode = 3
+)
+
+const HeaderSize = 4
+
+// bitRates are in kbit/s by version (MPEG-1 or MPEG-2/2.5), layer and bit rate index
+var bitRates = [2][3][15]int{
+	{
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	},
+	{
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	},
+}
+
+var sampleRates = [4][3]int{
+	Mpeg25: {11_025, 12_000, 8_000},
+	Mpeg2:  {22_050, 24_000, 16_000},
+	Mpeg1:  {44_100, 48_000, 32_000},
+}
+
+// FrameHeader is the 4-byte header of an MPEG audio frame:
+//
+//	Размер (бит)	Описание
+//	11				Frame sync (all bits set)
+//	2				Version: 00 - MPEG-2.5, 10 - MPEG-2, 11 - MPEG-1
+//	2				Layer: 01 - III, 10 - II, 11 - I
+//	1				Protection bit, 0 if a 16-bit CRC follows the header
+//	4				Bit rate index
+//	2				Sample rate index
+//	1				Padding bit
+//	1				Private bit
+//	2				Channel mode
+//	2				Mode extension
+//	1				Copyright
+//	1				Original
+//	2				Emphasis
+type FrameHeader struct {
+	Version     Version
+	Layer       int
+	Protected   bool
+	BitRate     int // kbit/s
+	SampleRate  int
+	Padding     bool
+	ChannelMode ChannelMode
+}
+
+func ParseFrameHeader(b []byte) (*FrameHeader, error) {
+	if len(b) < HeaderSize {
+		return nil, InvalidFrameHeader
+	}
+
+	v := binary.BigEndian.Uint32(b)
+	version := Version(v >> 19 & 0x03)
+	layer := 4 - int(v>>17&0x03)
+	bitRateIndex := v >> 12 & 0x0F
+	sampleRateIndex := v >> 10 & 0x03
+	if v>>21 != 0x7FF || version == 1 || layer == 4 || bitRateIndex == 0x0F || sampleRateIndex == 0x03 || v&0x03 == 2 {
+		return nil, InvalidFrameHeader
+	}
+	if bitRateIndex == 0 {
+		return nil, FreeFormatBitRate
+	}
+
+	h := &FrameHeader{
+		Version:     version,
+		Layer:       layer,
+		Protected:   v>>16&0x01 == 0,
+		SampleRate:  sampleRates[version][sampleRateIndex],
+		Padding:     v>>9&0x01 == 1,
+		ChannelMode: ChannelMode(v >> 6 & 0x03),
+	}
+	if version == Mpeg1 {
+		h.BitRate = bitRates[0][layer-1][bitRateIndex]
+	} else {
+		h.BitRate = bitRates[1][layer-1][bitRateIndex]
+	}
+	return h, nil
+}
+
+func (h *FrameHeader) Channels() int {
+	if h.ChannelMode == Mono {
+		return 1
+	}
+	return 2
+}
+
+// SampleCount returns the number of samples per channel in the frame.
+func (h *FrameHeader) SampleCount() int {
+	switch {
+	case h.Layer == 1:
+		return 384
+	case h.Layer == 3 && h.Version != Mpeg1:
+		return 576
+	default:
+		return 1152
+	}
+}
+
+// FrameSize returns the size of the whole frame including the header.
+func (h *FrameHeader) FrameSize() int {
+	padding := 0
+	if h.Padding {
+		padding = 1
+	}
+	if h.Layer == 1 {
+		return (12*h.BitRate*1000/h.SampleRate + padding) * 4
+	}
+	return h.SampleCount()/8*h.BitRate*1000/h.SampleRate + padding
+}
+
+func (h *FrameHeader) Duration() time.Duration {
+	return time.Duration(h.SampleCount()) * time.Second / time.Duration(h.SampleRate)
+}
+
+// Codec describes the stream, BitRate is the sample size of the decoded PCM as for other compressed codecs.
+func (h *FrameHeader) Codec() *audiocodec.Codec {
+	return audiocodec.NewCodec(audiocodec.Mp3, h.SampleRate, 16)
+}
+
+// PcmCodec returns the codec of the decoded samples.
+func (h *FrameHeader) PcmCodec() *audiocodec.Codec {
+	return audiocodec.NewPcmCodec(h.SampleRate, 16)
+}
+
+// sideInfoSize returns the size of the Layer III side information following the header and the optional CRC
+func (h *FrameHeader) sideInfoSize() int {
+	if h.Version == Mpeg1 {
+		if h.ChannelMode == Mono {
+			return 17
+		}
+		return 32
+	}
+	if h.ChannelMode == Mono {
+		return 9
+	}
+	return 17
+}
+
+// isCompatible reports whether the frame may belong to the same stream
+func (h *FrameHeader) isCompatible(h2 *FrameHeader) bool {
+	return h.Version == h2.Version && h.Layer == h2.Layer && h.SampleRate == h2.SampleRate
+}
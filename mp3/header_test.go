@@ -0,0 +1,63 @@
+package mp3
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseFrameHeader(t *testing.T) {
+	tests := []struct {
+		b           []byte
+		version     Version
+		layer       int
+		bitRate     int
+		sampleRate  int
+		channels    int
+		sampleCount int
+		frameSize   int
+	}{
+		{[]byte{0xff, 0xfb, 0x90, 0x64}, Mpeg1, 3, 128, 44_100, 2, 1152, 417},
+		{[]byte{0xff, 0xfb, 0x92, 0xc4}, Mpeg1, 3, 128, 44_100, 1, 1152, 418},
+		{[]byte{0xff, 0xf3, 0x80, 0xc4}, Mpeg2, 3, 64, 22_050, 1, 576, 208},
+		{[]byte{0xff, 0xe3, 0x48, 0xc4}, Mpeg25, 3, 32, 8_000, 1, 576, 288},
+		{[]byte{0xff, 0xfd, 0xa0, 0x04}, Mpeg1, 2, 192, 44_100, 2, 1152, 626},
+		{[]byte{0xff, 0xff, 0x90, 0x84}, Mpeg1, 1, 288, 44_100, 2, 384, 312},
+	}
+	for _, test := range tests {
+		h, err := ParseFrameHeader(test.b)
+		if err != nil {
+			t.Errorf("ParseFrameHeader(% x): %v", test.b, err)
+			continue
+		}
+		if h.Version != test.version || h.Layer != test.layer || h.BitRate != test.bitRate || h.SampleRate != test.sampleRate ||
+			h.Channels() != test.channels || h.SampleCount() != test.sampleCount || h.FrameSize() != test.frameSize {
+			t.Errorf("ParseFrameHeader(% x) = %+v, channels=%d, samples=%d, size=%d", test.b, h, h.Channels(), h.SampleCount(), h.FrameSize())
+		}
+	}
+
+	h, _ := ParseFrameHeader([]byte{0xff, 0xfb, 0x90, 0x64})
+	if h.Duration() != 1152*time.Second/44_100 || h.Codec().Name != "MP3" || h.PcmCodec().BitRate != 16 {
+		t.Errorf("duration=%s, codec=%s, PCM codec=%s", h.Duration(), h.Codec().Preset(), h.PcmCodec().Preset())
+	}
+}
+
+func TestParseFrameHeaderErrors(t *testing.T) {
+	invalid := [][]byte{
+		{0xff, 0xfb, 0x90},       // short
+		{0xfe, 0xfb, 0x90, 0x64}, // no sync
+		{0xff, 0xeb, 0x90, 0x64}, // reserved version
+		{0xff, 0xf9, 0x90, 0x64}, // reserved layer
+		{0xff, 0xfb, 0xf0, 0x64}, // bad bit rate
+		{0xff, 0xfb, 0x9c, 0x64}, // reserved sample rate
+		{0xff, 0xfb, 0x90, 0x66}, // reserved emphasis
+	}
+	for _, b := range invalid {
+		if _, err := ParseFrameHeader(b); !errors.Is(err, InvalidFrameHeader) {
+			t.Errorf("ParseFrameHeader(% x): %v, want %v", b, err, InvalidFrameHeader)
+		}
+	}
+	if _, err := ParseFrameHeader([]byte{0xff, 0xfb, 0x00, 0x64}); !errors.Is(err, FreeFormatBitRate) {
+		t.Errorf("free format: %v, want %v", err, FreeFormatBitRate)
+	}
+}
@@ -0,0 +1,284 @@
+package lame
+
+/*
+#cgo LDFLAGS: -lmp3lame
+
+#include <lame/lame.h>
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"unsafe"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/mp3"
+	"github.com/URALINNOVATSIYA/audiocodec/pcm"
+)
+
+var NotSupportedChannels = errors.New("only mono and stereo are supported")
+
+type Mode int
+
+// Cbr - constant bit rate.
+// Vbr - variable bit rate controlled by VbrQuality.
+// Abr - variable bit rate keeping the average BitRate.
+const (
+	Cbr Mode = iota
+	Vbr
+	Abr
+)
+
+// Tags are written as ID3v1 and ID3v2 tags, empty fields are omitted.
+type Tags struct {
+	Title   string
+	Artist  string
+	Album   string
+	Year    string
+	Comment string
+	Genre   string
+	// Custom are ID3v2 frames in the "ID=value" form, e.g. "TXXX=CallId=123"
+	Custom []string
+}
+
+type EncoderOptions struct {
+	Mode Mode
+	// BitRate in kbit/s for CBR and ABR
+	BitRate int
+	// VbrQuality from 0 (best) to 9
+	VbrQuality float64
+	// Quality of the algorithm from 0 (best, slowest) to 9 (worst, fastest)
+	Quality int
+	// SampleRate of the MP3 stream, zero lets the encoder choose it by the input sample rate and the bit rate
+	SampleRate int
+	Tags       *Tags
+}
+
+func DefaultEncoderOptions() EncoderOptions {
+	return EncoderOptions{
+		Mode:    Cbr,
+		BitRate: 64,
+		Quality: 5,
+	}
+}
+
+// Encoder compresses PCM of any codec supported by the pcm package into MPEG Layer III.
+type Encoder struct {
+	lame *C.lame_global_flags
+
+	pcmCodec *audiocodec.Codec
+	codec    *audiocodec.Codec
+	channels int
+	buffer   []byte
+	samples  []float32
+	left     []C.float
+	right    []C.float
+	mp3      []byte
+}
+
+func NewEncoder(pcmCodec *audiocodec.Codec, channels int, options EncoderOptions) (*Encoder, error) {
+	if _, err := pcm.Decoder(pcmCodec); err != nil {
+		return nil, err
+	}
+	if channels != 1 && channels != 2 {
+		return nil, fmt.Errorf("%w: %d", NotSupportedChannels, channels)
+	}
+
+	e := &Encoder{
+		lame:     C.lame_init(),
+		pcmCodec: pcmCodec,
+		channels: channels,
+	}
+	if e.lame == nil {
+		return nil, errors.New("lame initialization failed")
+	}
+
+	C.lame_set_in_samplerate(e.lame, C.int(pcmCodec.SampleRate))
+	C.lame_set_num_channels(e.lame, C.int(channels))
+	if channels == 1 {
+		C.lame_set_mode(e.lame, C.MONO)
+	}
+	if options.SampleRate > 0 {
+		C.lame_set_out_samplerate(e.lame, C.int(options.SampleRate))
+	}
+	C.lame_set_quality(e.lame, C.int(options.Quality))
+	switch options.Mode {
+	case Cbr:
+		C.lame_set_VBR(e.lame, C.vbr_off)
+		C.lame_set_brate(e.lame, C.int(options.BitRate))
+	case Vbr:
+		C.lame_set_VBR(e.lame, C.vbr_default)
+		C.lame_set_VBR_quality(e.lame, C.float(options.VbrQuality))
+	case Abr:
+		C.lame_set_VBR(e.lame, C.vbr_abr)
+		C.lame_set_VBR_mean_bitrate_kbps(e.lame, C.int(options.BitRate))
+	}
+	if options.Tags != nil {
+		e.setTags(options.Tags)
+	}
+
+	if errCode := C.lame_init_params(e.lame); errCode < 0 {
+		e.Free()
+		return nil, lameError(errCode)
+	}
+	e.codec = audiocodec.NewCodec(audiocodec.Mp3, int(C.lame_get_out_samplerate(e.lame)), 16)
+
+	return e, nil
+}
+
+// Encode returns the MP3 frames completed so far, the first chunk starts with the ID3v2 tag if any.
+func (e *Encoder) Encode(data []byte) ([]byte, error) {
+	e.buffer = append(e.buffer, data...)
+	frameBytes := e.pcmCodec.SampleSize() * e.channels
+	size := len(e.buffer) / frameBytes * frameBytes
+	if size == 0 {
+		return nil, nil
+	}
+
+	var err error
+	if e.samples, err = pcm.Decode(e.pcmCodec, e.samples[:0], e.buffer[:size]); err != nil {
+		return nil, err
+	}
+	e.buffer = append(e.buffer[:0], e.buffer[size:]...)
+
+	n := len(e.samples) / e.channels
+	e.left = e.left[:0]
+	e.right = e.right[:0]
+	for i := 0; i < n; i++ {
+		e.left = append(e.left, C.float(e.samples[i*e.channels]))
+		if e.channels == 2 {
+			e.right = append(e.right, C.float(e.samples[i*2+1]))
+		}
+	}
+	right := e.left
+	if e.channels == 2 {
+		right = e.right
+	}
+
+	// the buffer size recommended by LAME
+	e.grow(n*5/4 + 7200)
+	written := C.lame_encode_buffer_ieee_float(e.lame, &e.left[0], &right[0], C.int(n), (*C.uchar)(unsafe.Pointer(&e.mp3[0])), C.int(len(e.mp3)))
+	if written < 0 {
+		return nil, lameError(written)
+	}
+	return append([]byte(nil), e.mp3[:written]...), nil
+}
+
+// Flush encodes the buffered samples and returns the last frames followed by the ID3v1 tag if any.
+func (e *Encoder) Flush() ([]byte, error) {
+	e.buffer = e.buffer[:0]
+	e.grow(7200)
+	written := C.lame_encode_flush(e.lame, (*C.uchar)(unsafe.Pointer(&e.mp3[0])), C.int(len(e.mp3)))
+	if written < 0 {
+		return nil, lameError(written)
+	}
+	return append([]byte(nil), e.mp3[:written]...), nil
+}
+
+// VbrFrame returns the Xing/LAME tag frame to overwrite the first frame of the stream after Flush,
+// it lets players compute the duration and seek in VBR files.
+func (e *Encoder) VbrFrame() []byte {
+	size := C.lame_get_lametag_frame(e.lame, nil, 0)
+	if size == 0 {
+		return nil
+	}
+	frame := make([]byte, int(size))
+	C.lame_get_lametag_frame(e.lame, (*C.uchar)(unsafe.Pointer(&frame[0])), size)
+	return frame
+}
+
+// Codec returns the codec of the produced stream.
+func (e *Encoder) Codec() *audiocodec.Codec {
+	return e.codec
+}
+
+func (e *Encoder) PcmCodec() *audiocodec.Codec {
+	return e.pcmCodec
+}
+
+func (e *Encoder) Free() {
+	if e.lame != nil {
+		C.lame_close(e.lame)
+		e.lame = nil
+	}
+}
+
+func (e *Encoder) grow(size int) {
+	if len(e.mp3) < size {
+		e.mp3 = make([]byte, size)
+	}
+}
+
+func (e *Encoder) setTags(tags *Tags) {
+	C.id3tag_init(e.lame)
+	C.id3tag_add_v2(e.lame)
+
+	set := func(value string, setter func(value *C.char)) {
+		if value == "" {
+			return
+		}
+		v := C.CString(value)
+		setter(v)
+		C.free(unsafe.Pointer(v))
+	}
+
+	set(tags.Title, func(v *C.char) { C.id3tag_set_title(e.lame, v) })
+	set(tags.Artist, func(v *C.char) { C.id3tag_set_artist(e.lame, v) })
+	set(tags.Album, func(v *C.char) { C.id3tag_set_album(e.lame, v) })
+	set(tags.Year, func(v *C.char) { C.id3tag_set_year(e.lame, v) })
+	set(tags.Comment, func(v *C.char) { C.id3tag_set_comment(e.lame, v) })
+	set(tags.Genre, func(v *C.char) { C.id3tag_set_genre(e.lame, v) })
+	for _, custom := range tags.Custom {
+		set(custom, func(v *C.char) { C.id3tag_set_fieldvalue(e.lame, v) })
+	}
+}
+
+// Encode writes the whole WAV as an MP3 stream. If w is an io.WriteSeeker the first frame is replaced
+// with the Xing/LAME tag frame.
+func Encode(w io.Writer, wav *audiocodec.Wav, options EncoderOptions) error {
+	e, err := NewEncoder(wav.Codec(), wav.Channels(), options)
+	if err != nil {
+		return err
+	}
+	defer e.Free()
+
+	var start int64
+	ws, seekable := w.(io.WriteSeeker)
+	if seekable {
+		if start, err = ws.Seek(0, io.SeekCurrent); err != nil {
+			return err
+		}
+	}
+
+	data, err := e.Encode(wav.Data())
+	if err != nil {
+		return err
+	}
+	tail, err := e.Flush()
+	if err != nil {
+		return err
+	}
+	data = append(data, tail...)
+	if _, err = w.Write(data); err != nil {
+		return err
+	}
+
+	frame := e.VbrFrame()
+	if !seekable || frame == nil {
+		return nil
+	}
+	if _, err = ws.Seek(start+int64(mp3.Id3v2Size(data)), io.SeekStart); err != nil {
+		return err
+	}
+	if _, err = ws.Write(frame); err != nil {
+		return err
+	}
+	_, err = ws.Seek(start+int64(len(data)), io.SeekStart)
+	return err
+}
+
+func lameError(errCode C.int) error {
+	return fmt.Errorf("lame error code: %d", int(errCode))
+}
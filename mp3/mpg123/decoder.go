@@ -0,0 +1,194 @@
+package mpg123
+
+/*
+#cgo pkg-config: libmpg123
+
+#include <mpg123.h>
+
+// decode hides the type of the output buffer that differs between libmpg123 versions
+static int decode(mpg123_handle *mh, const void *in, size_t in_size, void *out, size_t out_size, size_t *done) {
+	return mpg123_decode(mh, in, in_size, out, out_size, done);
+}
+*/
+import "C"
+import (
+	"fmt"
+	"io"
+	"unsafe"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/mp3"
+)
+
+func init() {
+	// no-op since libmpg123 1.27, required by older versions
+	C.mpg123_init()
+}
+
+// Decoder decodes MPEG audio frames into 16-bit PCM using the feed mode of libmpg123,
+// so frames can be pushed one by one as they arrive.
+type Decoder struct {
+	handle   *C.mpg123_handle
+	codec    *audiocodec.Codec
+	channels int
+	buffer   []byte
+}
+
+// NewDecoder creates a decoder keeping the number of channels of the stream.
+func NewDecoder() (*Decoder, error) {
+	return newDecoder(C.MPG123_MONO | C.MPG123_STEREO)
+}
+
+// NewMonoDecoder creates a decoder mixing stereo streams down to mono as expected by the resamplers.
+func NewMonoDecoder() (*Decoder, error) {
+	return newDecoder(C.MPG123_MONO)
+}
+
+func newDecoder(channels C.int) (*Decoder, error) {
+	var errCode C.int
+	handle := C.mpg123_new(nil, &errCode)
+	if handle == nil {
+		return nil, mpg123Error(errCode)
+	}
+	d := &Decoder{handle: handle}
+
+	flags := C.long(C.MPG123_QUIET)
+	if channels == C.MPG123_MONO {
+		flags |= C.MPG123_MONO_MIX
+	}
+	if errCode = C.mpg123_param(handle, C.MPG123_ADD_FLAGS, flags, 0); errCode != C.MPG123_OK {
+		d.Free()
+		return nil, mpg123Error(errCode)
+	}
+
+	// the output is always signed 16-bit, the sample rate is the one of the stream
+	C.mpg123_format_none(handle)
+	var rates *C.long
+	var rateCount C.size_t
+	C.mpg123_rates(&rates, &rateCount)
+	for _, rate := range unsafe.Slice(rates, rateCount) {
+		C.mpg123_format(handle, rate, channels, C.MPG123_ENC_SIGNED_16)
+	}
+
+	if errCode = C.mpg123_open_feed(handle); errCode != C.MPG123_OK {
+		d.Free()
+		return nil, mpg123Error(errCode)
+	}
+	d.buffer = make([]byte, int(C.mpg123_outblock(handle)))
+
+	return d, nil
+}
+
+// Decode feeds the frame to the decoder and returns the PCM decoded so far, which may be empty
+// while the decoder needs more data, e.g. for the bit reservoir of the first frames.
+func (d *Decoder) Decode(frame []byte) ([]byte, error) {
+	var pcm []byte
+	var in unsafe.Pointer
+	if len(frame) > 0 {
+		in = unsafe.Pointer(&frame[0])
+	}
+	inSize := C.size_t(len(frame))
+
+	for {
+		var done C.size_t
+		errCode := C.decode(d.handle, in, inSize, unsafe.Pointer(&d.buffer[0]), C.size_t(len(d.buffer)), &done)
+		// the input is copied into the internal buffers on the first call
+		in, inSize = nil, 0
+		pcm = append(pcm, d.buffer[:done]...)
+
+		switch errCode {
+		case C.MPG123_OK:
+			continue
+		case C.MPG123_NEW_FORMAT:
+			if err := d.updateFormat(); err != nil {
+				return nil, err
+			}
+			continue
+		case C.MPG123_NEED_MORE, C.MPG123_DONE:
+			return pcm, nil
+		default:
+			return nil, fmt.Errorf("mpg123 error code: %d; %s", int(errCode), C.GoString(C.mpg123_strerror(d.handle)))
+		}
+	}
+}
+
+// Codec returns the PCM codec of the decoded data or nil before the first frame is decoded.
+func (d *Decoder) Codec() *audiocodec.Codec {
+	return d.codec
+}
+
+func (d *Decoder) Channels() int {
+	return d.channels
+}
+
+func (d *Decoder) Free() {
+	if d.handle != nil {
+		C.mpg123_close(d.handle)
+		C.mpg123_delete(d.handle)
+		d.handle = nil
+	}
+}
+
+func (d *Decoder) updateFormat() error {
+	var rate C.long
+	var channels, encoding C.int
+	if errCode := C.mpg123_getformat(d.handle, &rate, &channels, &encoding); errCode != C.MPG123_OK {
+		return mpg123Error(errCode)
+	}
+	d.codec = audiocodec.NewPcmCodec(int(rate), 16)
+	d.channels = int(channels)
+	if size := int(C.mpg123_outblock(d.handle)); size > len(d.buffer) {
+		d.buffer = make([]byte, size)
+	}
+	return nil
+}
+
+// Decode reads the whole MP3 stream into a WAV, stereo streams are mixed down to mono if mono is set.
+func Decode(r io.Reader, mono bool) (*audiocodec.Wav, error) {
+	var d *Decoder
+	var err error
+	if mono {
+		d, err = NewMonoDecoder()
+	} else {
+		d, err = NewDecoder()
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer d.Free()
+
+	var wav *audiocodec.Wav
+	reader := mp3.NewReader(r)
+	for {
+		frame, err := reader.ReadFrame()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		pcm, err := d.Decode(frame.Data)
+		if err != nil {
+			return nil, err
+		}
+		if len(pcm) == 0 {
+			continue
+		}
+		if wav == nil {
+			wav = audiocodec.NewWavWithChannels(d.Codec(), d.Channels())
+		}
+		if _, err = wav.Write(pcm); err != nil {
+			return nil, err
+		}
+	}
+
+	if wav == nil {
+		return nil, mp3.FrameNotFound
+	}
+	return wav, nil
+}
+
+func mpg123Error(errCode C.int) error {
+	return fmt.Errorf("mpg123 error code: %d; %s", int(errCode), C.GoString(C.mpg123_plain_strerror(errCode)))
+}
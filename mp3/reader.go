@@ -0,0 +1,180 @@
+package mp3
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+// maxFrameSize is the size of the largest possible frame: MPEG-1 Layer II at 384 kbit/s and 32 kHz with padding
+const maxFrameSize = 1729
+
+type Frame struct {
+	Header *FrameHeader
+	// Data is the whole frame including the header
+	Data []byte
+}
+
+// VbrHeader is the Xing/Info or VBRI header stored in the first frame of a stream instead of audio.
+type VbrHeader struct {
+	Frames int
+	Bytes  int
+}
+
+// Reader splits an MPEG audio stream into frames skipping ID3 and APE tags and garbage between frames.
+type Reader struct {
+	r      *bufio.Reader
+	first  *FrameHeader
+	vbr    *VbrHeader
+	frames int
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		r: bufio.NewReaderSize(r, 2*maxFrameSize),
+	}
+}
+
+// ReadFrame returns the next audio frame or io.EOF. The Xing/Info/VBRI frame is not returned, see VbrHeader.
+func (r *Reader) ReadFrame() (*Frame, error) {
+	for {
+		b, err := r.r.Peek(id3v2HeaderSize)
+		if len(b) < HeaderSize {
+			if err == nil || err == io.EOF || errors.Is(err, bufio.ErrBufferFull) {
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+
+		if size := Id3v2Size(b); size > 0 {
+			if err = r.discard(size); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if string(b[0:3]) == "TAG" {
+			if err = r.discard(id3v1Size); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if b, _ = r.r.Peek(apeFooterSize); apeTagSize(b) > 0 {
+			if err = r.discard(apeTagSize(b)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		header, err := ParseFrameHeader(b)
+		if err != nil || r.first != nil && !r.first.isCompatible(header) || !r.isSynchronized(header) {
+			if _, err = r.r.Discard(1); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		data := make([]byte, header.FrameSize())
+		if _, err = io.ReadFull(r.r, data); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				// the last frame is truncated
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+
+		if r.first == nil {
+			r.first = header
+			if r.vbr = parseVbrHeader(header, data); r.vbr != nil {
+				continue
+			}
+		}
+		r.frames++
+		return &Frame{Header: header, Data: data}, nil
+	}
+}
+
+// FirstHeader returns the header of the first frame read or nil.
+func (r *Reader) FirstHeader() *FrameHeader {
+	return r.first
+}
+
+// VbrHeader returns the VBR header found in the first frame, it is available after the first ReadFrame.
+func (r *Reader) VbrHeader() *VbrHeader {
+	return r.vbr
+}
+
+// Duration returns the duration of the stream from the VBR header or of the frames read so far if there is none.
+func (r *Reader) Duration() time.Duration {
+	if r.first == nil {
+		return 0
+	}
+	frames := r.frames
+	if r.vbr != nil && r.vbr.Frames > 0 {
+		frames = r.vbr.Frames
+	}
+	return time.Duration(frames) * r.first.Duration()
+}
+
+// isSynchronized checks that the first frame found is followed by another frame header,
+// so a random sync pattern in the tag garbage is not taken for the stream start
+func (r *Reader) isSynchronized(header *FrameHeader) bool {
+	if r.first != nil {
+		return true
+	}
+	size := header.FrameSize()
+	b, _ := r.r.Peek(size + HeaderSize)
+	if len(b) < size+HeaderSize {
+		// the stream consists of a single frame
+		return len(b) >= size
+	}
+	next, err := ParseFrameHeader(b[size:])
+	return err == nil && header.isCompatible(next)
+}
+
+func (r *Reader) discard(n int) error {
+	if _, err := r.r.Discard(n); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return nil
+}
+
+// parseVbrHeader looks for the Xing/Info tag after the side information or the VBRI tag at the fixed offset
+func parseVbrHeader(header *FrameHeader, frame []byte) *VbrHeader {
+	if header.Layer != 3 {
+		return nil
+	}
+
+	offset := HeaderSize + header.sideInfoSize()
+	if header.Protected {
+		offset += 2
+	}
+	if len(frame) >= offset+8 {
+		if tag := string(frame[offset : offset+4]); tag == "Xing" || tag == "Info" {
+			flags := binary.BigEndian.Uint32(frame[offset+4:])
+			vbr := &VbrHeader{}
+			offset += 8
+			if flags&0x01 != 0 && len(frame) >= offset+4 {
+				vbr.Frames = int(binary.BigEndian.Uint32(frame[offset:]))
+				offset += 4
+			}
+			if flags&0x02 != 0 && len(frame) >= offset+4 {
+				vbr.Bytes = int(binary.BigEndian.Uint32(frame[offset:]))
+			}
+			return vbr
+		}
+	}
+
+	const vbriOffset = HeaderSize + 32
+	if len(frame) >= vbriOffset+18 && string(frame[vbriOffset:vbriOffset+4]) == "VBRI" {
+		return &VbrHeader{
+			Bytes:  int(binary.BigEndian.Uint32(frame[vbriOffset+10:])),
+			Frames: int(binary.BigEndian.Uint32(frame[vbriOffset+14:])),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package mp3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+)
+
+// frame returns an MPEG-1 Layer III frame of 128 kbit/s at 44.1 kHz in joint stereo
+func frame(seed byte) []byte {
+	f := make([]byte, 417)
+	copy(f, []byte{0xff, 0xfb, 0x90, 0x64})
+	for i := HeaderSize; i < len(f); i++ {
+		f[i] = seed
+	}
+	return f
+}
+
+// xingFrame returns the Xing frame of the frame count and the byte count
+func xingFrame(frames int, size int) []byte {
+	f := make([]byte, 417)
+	copy(f, []byte{0xff, 0xfb, 0x90, 0x64})
+	// the side information of MPEG-1 stereo is 32 bytes
+	copy(f[HeaderSize+32:], "Xing")
+	binary.BigEndian.PutUint32(f[HeaderSize+36:], 0x03)
+	binary.BigEndian.PutUint32(f[HeaderSize+40:], uint32(frames))
+	binary.BigEndian.PutUint32(f[HeaderSize+44:], uint32(size))
+	return f
+}
+
+func readFrames(t *testing.T, r *Reader) [][]byte {
+	t.Helper()
+	var frames [][]byte
+	for {
+		f, err := r.ReadFrame()
+		if err == io.EOF {
+			return frames
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames = append(frames, f.Data)
+	}
+}
+
+func TestReader(t *testing.T) {
+	// ID3v2 tag, garbage with a false sync pattern, frames, APEv2 tag, a frame and ID3v1 tag
+	var file []byte
+	file = append(file, "ID3\x04\x00\x00\x00\x00\x00\x02\x00\x00"...)
+	file = append(file, 0x00, 0xff, 0xfb, 0x90, 0x64, 0x01)
+	for i := range 3 {
+		file = append(file, frame(byte(i+1))...)
+	}
+	ape := append([]byte("APETAGEX"), make([]byte, 24)...)
+	binary.LittleEndian.PutUint32(ape[12:], 32)
+	file = append(file, ape...)
+	file = append(file, ape...)
+	file = append(file, frame(4)...)
+	file = append(file, "TAG"...)
+	file = append(file, make([]byte, 125)...)
+
+	r := NewReader(bytes.NewReader(file))
+	frames := readFrames(t, r)
+	if len(frames) != 4 {
+		t.Fatalf("%d frames read", len(frames))
+	}
+	for i, f := range frames {
+		if !bytes.Equal(f, frame(byte(i+1))) {
+			t.Errorf("frame %d = % x...", i, f[:8])
+		}
+	}
+	if r.VbrHeader() != nil || r.FirstHeader().SampleRate != 44_100 || r.Duration() != 4*(1152*time.Second/44_100) {
+		t.Errorf("vbr=%+v, duration=%s", r.VbrHeader(), r.Duration())
+	}
+}
+
+func TestReaderVbrHeader(t *testing.T) {
+	file := xingFrame(1000, 417_000)
+	file = append(file, frame(1)...)
+	file = append(file, frame(2)...)
+
+	r := NewReader(bytes.NewReader(file))
+	frames := readFrames(t, r)
+	// the Xing frame is not audio
+	if len(frames) != 2 || !bytes.Equal(frames[0], frame(1)) {
+		t.Errorf("%d frames read", len(frames))
+	}
+	if vbr := r.VbrHeader(); vbr == nil || vbr.Frames != 1000 || vbr.Bytes != 417_000 {
+		t.Errorf("vbr = %+v", vbr)
+	}
+	if r.Duration() != 1000*(1152*time.Second/44_100) {
+		t.Errorf("duration = %s", r.Duration())
+	}
+}
+
+func TestReaderTruncatedFrame(t *testing.T) {
+	file := append(frame(1), frame(2)[:100]...)
+	r := NewReader(bytes.NewReader(file))
+	if frames := readFrames(t, r); len(frames) != 1 {
+		t.Errorf("%d frames read", len(frames))
+	}
+}
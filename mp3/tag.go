@@ -0,0 +1,168 @@
+package mp3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	id3v2HeaderSize = 10
+	id3v1Size       = 128
+	apeFooterSize   = 32
+)
+
+// Id3v2Size returns the size of the ID3v2 tag starting at b including its header and footer,
+// or 0 if b does not start with a tag. At least 10 bytes are required.
+func Id3v2Size(b []byte) int {
+	if len(b) < id3v2HeaderSize || string(b[0:3]) != "ID3" || b[3] == 0xFF || b[4] == 0xFF ||
+		b[6]|b[7]|b[8]|b[9] >= 0x80 {
+		return 0
+	}
+
+	size := id3v2HeaderSize + (int(b[6])<<21 | int(b[7])<<14 | int(b[8])<<7 | int(b[9]))
+	if b[5]&0x10 != 0 {
+		size += id3v2HeaderSize // footer
+	}
+	return size
+}
+
+// apeTagSize returns the size of the APEv2 tag starting with its header at b or 0 if there is no tag
+func apeTagSize(b []byte) int {
+	if len(b) < apeFooterSize || string(b[0:8]) != "APETAGEX" {
+		return 0
+	}
+	// the size field counts the items and the footer but not the header
+	return apeFooterSize + int(binary.LittleEndian.Uint32(b[12:16]))
+}
+
+// ParseId3v2 returns the text frames of the ID3v2.3 or ID3v2.4 tag at b by frame ID, user defined TXXX frames are
+// returned by their description. Multiple values of a frame are joined with ";". Other versions, compressed and
+// encrypted frames are skipped.
+func ParseId3v2(b []byte) map[string]string {
+	size := Id3v2Size(b)
+	if size == 0 || size > len(b) {
+		return nil
+	}
+	version := b[3]
+	if version != 3 && version != 4 {
+		return nil
+	}
+
+	flags := b[5]
+	tag := b[id3v2HeaderSize : id3v2HeaderSize+syncSafe(b[6:10])]
+	if version == 3 && flags&0x80 != 0 {
+		tag = removeUnsynchronisation(tag)
+	}
+	if flags&0x40 != 0 && len(tag) >= 4 {
+		// the extended header size excludes itself in 2.3 and is a sync-safe integer including itself in 2.4
+		extended := int(binary.BigEndian.Uint32(tag[0:4])) + 4
+		if version == 4 {
+			extended = syncSafe(tag[0:4])
+		}
+		if extended > len(tag) {
+			return nil
+		}
+		tag = tag[extended:]
+	}
+
+	frames := make(map[string]string)
+	for len(tag) >= 10 && tag[0] != 0 {
+		id := string(tag[0:4])
+		frameSize := int(binary.BigEndian.Uint32(tag[4:8]))
+		if version == 4 {
+			frameSize = syncSafe(tag[4:8])
+		}
+		if 10+frameSize > len(tag) {
+			break
+		}
+		frame := tag[10 : 10+frameSize]
+		frameFlags := tag[9]
+		tag = tag[10+frameSize:]
+
+		if id[0] != 'T' || len(frame) == 0 || version == 3 && frameFlags&0xC0 != 0 || version == 4 && frameFlags&0x0C != 0 {
+			continue
+		}
+		if version == 4 && frameFlags&0x02 != 0 {
+			frame = removeUnsynchronisation(frame)
+		}
+		if version == 4 && frameFlags&0x01 != 0 {
+			// data length indicator
+			if len(frame) < 5 {
+				continue
+			}
+			frame = frame[4:]
+		}
+
+		values := splitId3Strings(frame[0], frame[1:])
+		if id == "TXXX" {
+			if len(values) < 2 {
+				continue
+			}
+			id, values = values[0], values[1:]
+		}
+		frames[id] = strings.Join(values, ";")
+	}
+	return frames
+}
+
+// splitId3Strings decodes null-separated strings of the text encoding: 0 - ISO-8859-1, 1 - UTF-16 with BOM,
+// 2 - UTF-16BE, 3 - UTF-8
+func splitId3Strings(encoding byte, b []byte) []string {
+	var values []string
+	if encoding == 1 || encoding == 2 {
+		var units []uint16
+		bigEndian := encoding == 2
+		for i := 0; i+1 < len(b); i += 2 {
+			unit := binary.LittleEndian.Uint16(b[i:])
+			if bigEndian {
+				unit = binary.BigEndian.Uint16(b[i:])
+			}
+			switch {
+			case unit == 0xFEFF && encoding == 1:
+				bigEndian = false
+			case unit == 0xFFFE && encoding == 1:
+				bigEndian = true
+			case unit == 0:
+				values = append(values, string(utf16.Decode(units)))
+				units = units[:0]
+			default:
+				units = append(units, unit)
+			}
+		}
+		if len(units) > 0 {
+			values = append(values, string(utf16.Decode(units)))
+		}
+		return values
+	}
+
+	for _, value := range bytes.Split(bytes.TrimRight(b, "\x00"), []byte{0}) {
+		if encoding == 0 {
+			runes := make([]rune, len(value))
+			for i, c := range value {
+				runes[i] = rune(c)
+			}
+			values = append(values, string(runes))
+		} else {
+			values = append(values, string(value))
+		}
+	}
+	return values
+}
+
+// removeUnsynchronisation drops zero bytes inserted after 0xFF
+func removeUnsynchronisation(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		out = append(out, b[i])
+		if b[i] == 0xFF && i+1 < len(b) && b[i+1] == 0 {
+			i++
+		}
+	}
+	return out
+}
+
+func syncSafe(b []byte) int {
+	return int(b[0])<<21 | int(b[1])<<14 | int(b[2])<<7 | int(b[3])
+}
@@ -0,0 +1,100 @@
+package mp3
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// id3Frame returns the frame of the tag version, frame sizes of ID3v2.4 are sync-safe
+func id3Frame(version byte, id string, flags byte, data []byte) []byte {
+	b := []byte(id)
+	if version == 4 {
+		size := len(data)
+		b = append(b, byte(size>>21&0x7f), byte(size>>14&0x7f), byte(size>>7&0x7f), byte(size&0x7f))
+	} else {
+		b = binary.BigEndian.AppendUint32(b, uint32(len(data)))
+	}
+	b = append(b, 0, flags)
+	return append(b, data...)
+}
+
+func id3Tag(version byte, flags byte, frames ...[]byte) []byte {
+	var body []byte
+	for _, f := range frames {
+		body = append(body, f...)
+	}
+	size := len(body)
+	b := []byte{'I', 'D', '3', version, 0, flags, byte(size >> 21 & 0x7f), byte(size >> 14 & 0x7f), byte(size >> 7 & 0x7f), byte(size & 0x7f)}
+	return append(b, body...)
+}
+
+func TestParseId3v2(t *testing.T) {
+	tag := id3Tag(3, 0,
+		id3Frame(3, "TIT2", 0, []byte("\x00Caf\xe9")),
+		id3Frame(3, "TPE1", 0, []byte("\x01\xff\xfeA\x00\x14\x04\x00\x00")),
+		id3Frame(3, "TALB", 0, []byte("\x02\x00B\x04\x14")),
+		id3Frame(3, "TXXX", 0, []byte("\x03CALL_ID\x0042")),
+		id3Frame(3, "COMM", 0, []byte("\x00engcomment")),
+		id3Frame(3, "TCON", 0x80, []byte("\x00compressed")),
+	)
+	frames := ParseId3v2(tag)
+	want := map[string]string{"TIT2": "Café", "TPE1": "AД", "TALB": "BД", "CALL_ID": "42"}
+	if len(frames) != len(want) {
+		t.Errorf("frames = %q, want %q", frames, want)
+	}
+	for id, value := range want {
+		if frames[id] != value {
+			t.Errorf("%s = %q, want %q", id, frames[id], value)
+		}
+	}
+}
+
+func TestParseId3v24(t *testing.T) {
+	// sync-safe frame size over 127, multiple values and a frame with the data length indicator
+	long := make([]byte, 200)
+	for i := range long {
+		long[i] = 'a'
+	}
+	tag := id3Tag(4, 0,
+		id3Frame(4, "TIT2", 0, append([]byte{3}, long...)),
+		id3Frame(4, "TPE1", 0, []byte("\x03A\x00B\x00")),
+		id3Frame(4, "TALB", 0x01, []byte("\x00\x00\x00\x04\x03xyz")),
+	)
+	frames := ParseId3v2(tag)
+	if frames["TIT2"] != string(long) || frames["TPE1"] != "A;B" || frames["TALB"] != "xyz" {
+		t.Errorf("frames = %q", frames)
+	}
+	if size := Id3v2Size(tag); size != len(tag) {
+		t.Errorf("tag size = %d, want %d", size, len(tag))
+	}
+}
+
+func TestParseId3v2Unsynchronisation(t *testing.T) {
+	// the frame size of ID3v2.3 counts the bytes after the inserted zero is removed
+	frame := id3Frame(3, "TIT2", 0, []byte("\x00a\xffb"))
+	tag := id3Tag(3, 0x80, append(frame[:len(frame)-1:len(frame)-1], 0x00, 'b'))
+	if frames := ParseId3v2(tag); frames["TIT2"] != "aÿb" {
+		t.Errorf("frames = %q", frames)
+	}
+}
+
+func TestId3v2Size(t *testing.T) {
+	tests := []struct {
+		b    []byte
+		want int
+	}{
+		{[]byte("ID3\x04\x00\x00\x00\x00\x02\x01"), 10 + 257},
+		{[]byte("ID3\x04\x00\x10\x00\x00\x00\x0a"), 30},
+		{[]byte("ID3\x04\x00\x00\x00\x00\x80\x00"), 0},
+		{[]byte("ID3\x04\x00\x00\x00\x00"), 0},
+		{[]byte("TAG\x04\x00\x00\x00\x00\x00\x00"), 0},
+	}
+	for _, test := range tests {
+		if size := Id3v2Size(test.b); size != test.want {
+			t.Errorf("Id3v2Size(% x) = %d, want %d", test.b, size, test.want)
+		}
+	}
+	if frames := ParseId3v2(id3Tag(2, 0, id3Frame(3, "TT2\x00", 0, []byte("\x00x")))); frames != nil {
+		t.Errorf("ID3v2.2 frames = %q", frames)
+	}
+}
@@ -0,0 +1,24 @@
+package ogg
+
+// crcTable is the lookup table of the non-reflected CRC-32 with polynomial 0x04C11DB7 used by Ogg
+var crcTable = func() (table [256]uint32) {
+	for i := range table {
+		r := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04C11DB7
+			} else {
+				r <<= 1
+			}
+		}
+		table[i] = r
+	}
+	return table
+}()
+
+func crcUpdate(crc uint32, b []byte) uint32 {
+	for _, v := range b {
+		crc = crc<<8 ^ crcTable[byte(crc>>24)^v]
+	}
+	return crc
+}
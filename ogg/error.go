@@ -0,0 +1,14 @@
+package ogg
+
+import "errors"
+
+var (
+	InvalidPage      = errors.New("invalid Ogg page: missing capture pattern")
+	InvalidChecksum  = errors.New("invalid Ogg page: checksum mismatch")
+	UnsupportedPage  = errors.New("unsupported Ogg page version")
+	InvalidOpusHead  = errors.New("invalid OpusHead packet")
+	InvalidOpusTags  = errors.New("invalid OpusTags packet")
+	InvalidVorbis    = errors.New("invalid Vorbis header packet")
+	InvalidPacket    = errors.New("invalid Opus packet")
+	StreamIsFinished = errors.New("Ogg stream is finished")
+)
@@ -0,0 +1,359 @@
+package ogg
+
+import (
+	"encoding/binary"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+const (
+	// OpusSampleRate is the rate of Ogg Opus granule positions regardless of the input sample rate
+	OpusSampleRate = 48_000
+	// DefaultPreSkip is the encoder delay of libopus at 48 kHz
+	DefaultPreSkip = 312
+
+	opusHeadSize = 19
+	// opusPageDuration is how much audio the writer puts on a page
+	opusPageDuration = OpusSampleRate
+)
+
+// OpusHead is the identification header of an Ogg Opus stream (RFC 7845 section 5.1).
+type OpusHead struct {
+	Version         uint8
+	Channels        uint8
+	PreSkip         uint16
+	InputSampleRate uint32
+	// OutputGain is in dB in Q7.8 format
+	OutputGain    int16
+	MappingFamily uint8
+	StreamCount   uint8
+	CoupledCount  uint8
+	ChannelMap    []byte
+}
+
+func NewOpusHead(inputSampleRate int, channels int) *OpusHead {
+	return &OpusHead{
+		Version:         1,
+		Channels:        uint8(channels),
+		PreSkip:         DefaultPreSkip,
+		InputSampleRate: uint32(inputSampleRate),
+	}
+}
+
+func ParseOpusHead(b []byte) (*OpusHead, error) {
+	if len(b) < opusHeadSize || string(b[0:8]) != "OpusHead" || b[8]>>4 != 0 {
+		return nil, InvalidOpusHead
+	}
+
+	h := &OpusHead{
+		Version:         b[8],
+		Channels:        b[9],
+		PreSkip:         binary.LittleEndian.Uint16(b[10:12]),
+		InputSampleRate: binary.LittleEndian.Uint32(b[12:16]),
+		OutputGain:      int16(binary.LittleEndian.Uint16(b[16:18])),
+		MappingFamily:   b[18],
+	}
+	if h.Channels == 0 {
+		return nil, InvalidOpusHead
+	}
+	if h.MappingFamily != 0 {
+		if len(b) < opusHeadSize+2+int(h.Channels) {
+			return nil, InvalidOpusHead
+		}
+		h.StreamCount = b[19]
+		h.CoupledCount = b[20]
+		h.ChannelMap = append([]byte(nil), b[21:21+int(h.Channels)]...)
+	}
+
+	return h, nil
+}
+
+func (h *OpusHead) Bytes() []byte {
+	b := make([]byte, opusHeadSize, opusHeadSize+2+len(h.ChannelMap))
+	copy(b[0:8], "OpusHead")
+	b[8] = h.Version
+	b[9] = h.Channels
+	binary.LittleEndian.PutUint16(b[10:12], h.PreSkip)
+	binary.LittleEndian.PutUint32(b[12:16], h.InputSampleRate)
+	binary.LittleEndian.PutUint16(b[16:18], uint16(h.OutputGain))
+	b[18] = h.MappingFamily
+	if h.MappingFamily != 0 {
+		b = append(b, h.StreamCount, h.CoupledCount)
+		b = append(b, h.ChannelMap...)
+	}
+	return b
+}
+
+// Codec returns the codec of the stream.
+func (h *OpusHead) Codec() *audiocodec.Codec {
+	return audiocodec.Opus48kHzCodec
+}
+
+// Comments is a list of "NAME=value" user comments shared by Opus and Vorbis headers.
+type Comments []string
+
+// Get returns the first value of the field, field names are case-insensitive.
+func (c Comments) Get(name string) string {
+	for _, comment := range c {
+		if key, value, found := strings.Cut(comment, "="); found && strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+func (c *Comments) Add(name string, value string) {
+	*c = append(*c, strings.ToUpper(name)+"="+value)
+}
+
+// Map returns all comments by upper-cased field names.
+func (c Comments) Map() map[string][]string {
+	m := make(map[string][]string, len(c))
+	for _, comment := range c {
+		if key, value, found := strings.Cut(comment, "="); found {
+			key = strings.ToUpper(key)
+			m[key] = append(m[key], value)
+		}
+	}
+	return m
+}
+
+// OpusTags is the comment header of an Ogg Opus stream (RFC 7845 section 5.2).
+type OpusTags struct {
+	Vendor   string
+	Comments Comments
+}
+
+func ParseOpusTags(b []byte) (*OpusTags, error) {
+	if len(b) < 8 || string(b[0:8]) != "OpusTags" {
+		return nil, InvalidOpusTags
+	}
+
+	vendor, comments, _, ok := ParseComments(b[8:])
+	if !ok {
+		return nil, InvalidOpusTags
+	}
+	return &OpusTags{
+		Vendor:   vendor,
+		Comments: comments,
+	}, nil
+}
+
+func (t *OpusTags) Bytes() []byte {
+	return AppendComments([]byte("OpusTags"), t.Vendor, t.Comments)
+}
+
+// OpusPacketSampleCount returns the number of 48 kHz samples in the Opus packet by its TOC byte (RFC 6716 section 3.1).
+func OpusPacketSampleCount(packet []byte) (int, error) {
+	if len(packet) == 0 {
+		return 0, InvalidPacket
+	}
+
+	config := int(packet[0] >> 3)
+	var frameSize int
+	switch {
+	case config < 12: // SILK: 10, 20, 40, 60 ms
+		frameSize = [4]int{480, 960, 1920, 2880}[config%4]
+	case config < 16: // Hybrid: 10, 20 ms
+		frameSize = [2]int{480, 960}[config%2]
+	default: // CELT: 2.5, 5, 10, 20 ms
+		frameSize = [4]int{120, 240, 480, 960}[config%4]
+	}
+
+	var frames int
+	switch packet[0] & 0x03 {
+	case 0:
+		frames = 1
+	case 1, 2:
+		frames = 2
+	default:
+		if len(packet) < 2 {
+			return 0, InvalidPacket
+		}
+		frames = int(packet[1] & 0x3F)
+	}
+
+	return frames * frameSize, nil
+}
+
+// OpusWriter produces an Ogg Opus file from encoded packets.
+type OpusWriter struct {
+	w           *Writer
+	head        *OpusHead
+	granule     int64
+	pageGranule int64 // granule position the current page started at
+}
+
+// NewOpusWriter writes the identification and the comment headers, each on its own page as required by RFC 7845.
+func NewOpusWriter(w io.Writer, serial uint32, head *OpusHead, tags *OpusTags) (*OpusWriter, error) {
+	ow := &OpusWriter{
+		w:    NewWriter(w, serial),
+		head: head,
+	}
+
+	if err := ow.w.WritePacket(head.Bytes(), 0); err != nil {
+		return nil, err
+	}
+	if err := ow.w.Flush(); err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = &OpusTags{Vendor: "audiocodec"}
+	}
+	if err := ow.w.WritePacket(tags.Bytes(), 0); err != nil {
+		return nil, err
+	}
+	if err := ow.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return ow, nil
+}
+
+// WritePacket adds an Opus packet, its granule position is derived from the packet duration.
+func (ow *OpusWriter) WritePacket(packet []byte) error {
+	samples, err := OpusPacketSampleCount(packet)
+	if err != nil {
+		return err
+	}
+
+	ow.granule += int64(samples)
+	if err = ow.w.WritePacket(packet, ow.granule); err != nil {
+		return err
+	}
+	if ow.granule-ow.pageGranule >= opusPageDuration {
+		ow.pageGranule = ow.granule
+		return ow.w.Flush()
+	}
+	return nil
+}
+
+// Close finishes the stream, the file is not valid until it is closed.
+func (ow *OpusWriter) Close() error {
+	return ow.w.Close()
+}
+
+// Duration returns the duration of the audio written so far excluding the pre-skip.
+func (ow *OpusWriter) Duration() time.Duration {
+	return granuleDuration(ow.granule - int64(ow.head.PreSkip))
+}
+
+// OpusReader reads Opus packets from an Ogg Opus file.
+type OpusReader struct {
+	r       *Reader
+	serial  uint32
+	Head    *OpusHead
+	Tags    *OpusTags
+	granule int64
+}
+
+// NewOpusReader reads the headers of the first Opus stream in the file.
+func NewOpusReader(r io.Reader) (*OpusReader, error) {
+	or := &OpusReader{
+		r: NewReader(r),
+	}
+
+	for or.Head == nil {
+		packet, err := or.r.ReadPacket()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if !packet.BeginOfStream {
+			continue
+		}
+		if head, err := ParseOpusHead(packet.Data); err == nil {
+			or.Head = head
+			or.serial = packet.Serial
+		}
+	}
+
+	for or.Tags == nil {
+		packet, err := or.r.ReadPacket()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if packet.Serial != or.serial {
+			continue
+		}
+		if or.Tags, err = ParseOpusTags(packet.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	return or, nil
+}
+
+// ReadPacket returns the next audio packet of the stream or io.EOF.
+func (or *OpusReader) ReadPacket() ([]byte, error) {
+	for {
+		packet, err := or.r.ReadPacket()
+		if err != nil {
+			return nil, err
+		}
+		if packet.Serial != or.serial {
+			continue
+		}
+		if packet.Granule != NoGranule {
+			or.granule = packet.Granule
+		}
+		return packet.Data, nil
+	}
+}
+
+// Position returns the duration of audio up to the last page read, excluding the pre-skip.
+func (or *OpusReader) Position() time.Duration {
+	return granuleDuration(or.granule - int64(or.Head.PreSkip))
+}
+
+func granuleDuration(granule int64) time.Duration {
+	if granule < 0 {
+		return 0
+	}
+	return time.Duration(granule) * time.Second / OpusSampleRate
+}
+
+// ParseComments parses the vendor string and the user comments of a Vorbis comment structure
+func ParseComments(b []byte) (vendor string, comments Comments, rest []byte, ok bool) {
+	readString := func() (string, bool) {
+		if len(b) < 4 {
+			return "", false
+		}
+		size := binary.LittleEndian.Uint32(b[0:4])
+		if uint64(size) > uint64(len(b)-4) {
+			return "", false
+		}
+		s := string(b[4 : 4+size])
+		b = b[4+size:]
+		return s, true
+	}
+
+	if vendor, ok = readString(); !ok || len(b) < 4 {
+		return "", nil, nil, false
+	}
+	count := binary.LittleEndian.Uint32(b[0:4])
+	b = b[4:]
+	for i := uint32(0); i < count; i++ {
+		comment, ok := readString()
+		if !ok {
+			return "", nil, nil, false
+		}
+		comments = append(comments, comment)
+	}
+
+	return vendor, comments, b, true
+}
+
+// AppendComments appends the vendor string and the user comments as a Vorbis comment structure
+func AppendComments(b []byte, vendor string, comments Comments) []byte {
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(vendor)))
+	b = append(b, vendor...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(comments)))
+	for _, comment := range comments {
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(comment)))
+		b = append(b, comment...)
+	}
+	return b
+}
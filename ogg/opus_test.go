@@ -0,0 +1,146 @@
+package ogg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestOpusHead(t *testing.T) {
+	heads := []*OpusHead{
+		NewOpusHead(16_000, 1),
+		{Version: 1, Channels: 3, PreSkip: 3840, InputSampleRate: 44_100, OutputGain: -256, MappingFamily: 1, StreamCount: 2, CoupledCount: 1, ChannelMap: []byte{0, 2, 1}},
+	}
+	for _, head := range heads {
+		b := head.Bytes()
+		if len(b) != opusHeadSize+len(head.ChannelMap)+2*int(head.MappingFamily) {
+			t.Errorf("head of %d bytes", len(b))
+		}
+		parsed, err := ParseOpusHead(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.Version != head.Version || parsed.Channels != head.Channels || parsed.PreSkip != head.PreSkip ||
+			parsed.InputSampleRate != head.InputSampleRate || parsed.OutputGain != head.OutputGain ||
+			parsed.MappingFamily != head.MappingFamily || parsed.StreamCount != head.StreamCount ||
+			parsed.CoupledCount != head.CoupledCount || !bytes.Equal(parsed.ChannelMap, head.ChannelMap) {
+			t.Errorf("parsed %+v, want %+v", parsed, head)
+		}
+	}
+
+	invalid := [][]byte{
+		[]byte("OpusHead"),
+		append([]byte("OpusHead\x10\x02"), make([]byte, 9)...),
+		append([]byte("OpusHead\x01\x00"), make([]byte, 9)...),
+		append([]byte("OpusHead\x01\x03"), 0, 0, 0, 0, 0, 0, 0, 0, 1, 2, 1),
+	}
+	for _, b := range invalid {
+		if _, err := ParseOpusHead(b); !errors.Is(err, InvalidOpusHead) {
+			t.Errorf("ParseOpusHead(% x): %v, want %v", b, err, InvalidOpusHead)
+		}
+	}
+}
+
+func TestOpusTags(t *testing.T) {
+	tags := &OpusTags{Vendor: "libopus", Comments: Comments{"TITLE=call"}}
+	tags.Comments.Add("artist", "a=b")
+	want := []byte("OpusTags\x07\x00\x00\x00libopus\x02\x00\x00\x00\x0a\x00\x00\x00TITLE=call\x0a\x00\x00\x00ARTIST=a=b")
+	if b := tags.Bytes(); !bytes.Equal(b, want) {
+		t.Errorf("tags = %q, want %q", b, want)
+	}
+
+	parsed, err := ParseOpusTags(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Vendor != "libopus" || parsed.Comments.Get("title") != "call" || parsed.Comments.Get("Artist") != "a=b" || parsed.Comments.Get("ALBUM") != "" {
+		t.Errorf("parsed %+v", parsed)
+	}
+	if _, err = ParseOpusTags(want[:len(want)-1]); !errors.Is(err, InvalidOpusTags) {
+		t.Errorf("truncated tags: %v, want %v", err, InvalidOpusTags)
+	}
+
+	comments := Comments{"ARTIST=a", "artist=b", "invalid", "TITLE="}
+	if m := comments.Map(); len(m) != 2 || len(m["ARTIST"]) != 2 || m["ARTIST"][1] != "b" || m["TITLE"][0] != "" {
+		t.Errorf("map = %v", m)
+	}
+}
+
+func TestOpusPacketSampleCount(t *testing.T) {
+	// TOC bytes of RFC 6716 section 3.1: configuration in the upper 5 bits and the frame count code in the lower 2
+	tests := []struct {
+		packet []byte
+		want   int
+	}{
+		{[]byte{0 << 3}, 480},             // SILK NB 10 ms
+		{[]byte{3 << 3}, 2880},            // SILK NB 60 ms
+		{[]byte{9 << 3}, 960},             // SILK WB 20 ms
+		{[]byte{12 << 3}, 480},            // Hybrid SWB 10 ms
+		{[]byte{15 << 3}, 960},            // Hybrid FB 20 ms
+		{[]byte{16 << 3}, 120},            // CELT NB 2.5 ms
+		{[]byte{31 << 3}, 960},            // CELT FB 20 ms
+		{[]byte{31<<3 | 1}, 1920},         // two equal frames
+		{[]byte{1<<3 | 2}, 1920},          // two frames of different size
+		{[]byte{18<<3 | 3, 0x85}, 2400},   // five frames, VBR flag
+		{[]byte{0<<3 | 3, 0x06}, 6 * 480}, // six frames
+	}
+	for _, test := range tests {
+		if n, err := OpusPacketSampleCount(test.packet); err != nil || n != test.want {
+			t.Errorf("OpusPacketSampleCount(% x) = %d, %v, want %d", test.packet, n, err, test.want)
+		}
+	}
+	for _, packet := range [][]byte{nil, {3}} {
+		if _, err := OpusPacketSampleCount(packet); !errors.Is(err, InvalidPacket) {
+			t.Errorf("OpusPacketSampleCount(% x): %v, want %v", packet, err, InvalidPacket)
+		}
+	}
+}
+
+func TestOpusWriterReader(t *testing.T) {
+	var file bytes.Buffer
+	w, err := NewOpusWriter(&file, 5, NewOpusHead(8000, 1), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 3 seconds of 20 ms packets, a page holds a second of audio
+	for i := range 150 {
+		if err = w.WritePacket([]byte{31 << 3, byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := w.Duration(); d != 3*time.Second-DefaultPreSkip*time.Second/OpusSampleRate {
+		t.Errorf("written duration = %s", d)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewOpusReader(bytes.NewReader(file.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Head.InputSampleRate != 8000 || r.Head.Channels != 1 || r.Tags.Vendor != "audiocodec" {
+		t.Errorf("head %+v, tags %+v", r.Head, r.Tags)
+	}
+	for i := range 150 {
+		packet, err := r.ReadPacket()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(packet, []byte{31 << 3, byte(i)}) {
+			t.Errorf("packet %d = % x", i, packet)
+		}
+		// the position is known at the end of a page
+		if i == 49 && r.Position() != time.Second-DefaultPreSkip*time.Second/OpusSampleRate {
+			t.Errorf("position after a second = %s", r.Position())
+		}
+	}
+	if _, err = r.ReadPacket(); err != io.EOF {
+		t.Errorf("ReadPacket at the end: %v, want %v", err, io.EOF)
+	}
+	if r.Position() != w.Duration() {
+		t.Errorf("position = %s, want %s", r.Position(), w.Duration())
+	}
+}
@@ -0,0 +1,120 @@
+package ogg
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+const (
+	Continued     byte = 0x01 // the page starts with the continuation of the packet from the previous page
+	BeginOfStream byte = 0x02
+	EndOfStream   byte = 0x04
+
+	headerSize     = 27
+	maxSegments    = 255
+	maxSegmentSize = 255
+
+	// NoGranule is the granule position of a page on which no packet ends
+	NoGranule int64 = -1
+)
+
+// Page is a single Ogg page as defined by RFC 3533:
+//
+//	Смещение	Размер	Описание
+//	0x00		4		Capture pattern "OggS"
+//	0x04		1		Stream structure version (0)
+//	0x05		1		Header type flags
+//	0x06		8		Granule position
+//	0x0e		4		Bitstream serial number
+//	0x12		4		Page sequence number
+//	0x16		4		CRC checksum
+//	0x1a		1		Number of segments
+//	0x1b		*		Segment table (lacing values), followed by the page body
+type Page struct {
+	Flags    byte
+	Granule  int64
+	Serial   uint32
+	Sequence uint32
+	Lacing   []byte
+	Body     []byte
+}
+
+func (p *Page) IsContinued() bool {
+	return p.Flags&Continued != 0
+}
+
+func (p *Page) IsBeginOfStream() bool {
+	return p.Flags&BeginOfStream != 0
+}
+
+func (p *Page) IsEndOfStream() bool {
+	return p.Flags&EndOfStream != 0
+}
+
+// Bytes encodes the page and calculates its checksum.
+func (p *Page) Bytes() []byte {
+	b := make([]byte, headerSize+len(p.Lacing)+len(p.Body))
+	copy(b[0:4], "OggS")
+	b[4] = 0
+	b[5] = p.Flags
+	binary.LittleEndian.PutUint64(b[6:14], uint64(p.Granule))
+	binary.LittleEndian.PutUint32(b[14:18], p.Serial)
+	binary.LittleEndian.PutUint32(b[18:22], p.Sequence)
+	b[26] = byte(len(p.Lacing))
+	copy(b[headerSize:], p.Lacing)
+	copy(b[headerSize+len(p.Lacing):], p.Body)
+	binary.LittleEndian.PutUint32(b[22:26], crcUpdate(0, b))
+	return b
+}
+
+// ReadPage reads and verifies a single page.
+func ReadPage(r io.Reader) (*Page, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if string(header[0:4]) != "OggS" {
+		return nil, InvalidPage
+	}
+	if header[4] != 0 {
+		return nil, UnsupportedPage
+	}
+
+	p := &Page{
+		Flags:    header[5],
+		Granule:  int64(binary.LittleEndian.Uint64(header[6:14])),
+		Serial:   binary.LittleEndian.Uint32(header[14:18]),
+		Sequence: binary.LittleEndian.Uint32(header[18:22]),
+		Lacing:   make([]byte, header[26]),
+	}
+	if _, err := io.ReadFull(r, p.Lacing); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	bodySize := 0
+	for _, v := range p.Lacing {
+		bodySize += int(v)
+	}
+	p.Body = make([]byte, bodySize)
+	if _, err := io.ReadFull(r, p.Body); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	checksum := binary.LittleEndian.Uint32(header[22:26])
+	clear(header[22:26])
+	crc := crcUpdate(0, header)
+	crc = crcUpdate(crc, p.Lacing)
+	crc = crcUpdate(crc, p.Body)
+	if crc != checksum {
+		return nil, InvalidChecksum
+	}
+
+	return p, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
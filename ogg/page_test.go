@@ -0,0 +1,62 @@
+package ogg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+// referencePage is the first page of an Opus stream with the checksum calculated independently
+var referencePage = []byte{
+	0x4f, 0x67, 0x67, 0x53, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x78, 0x56, 0x34, 0x12, 0x00,
+	0x00, 0x00, 0x00, 0x23, 0xec, 0xb0, 0x3e, 0x01, 0x13, 0x4f, 0x70, 0x75, 0x73, 0x48, 0x65, 0x61, 0x64, 0x01, 0x02,
+	0x38, 0x01, 0x80, 0xbb, 0x00, 0x00, 0x00, 0x00, 0x00,
+}
+
+func TestCrc(t *testing.T) {
+	// the check value of CRC-32/CKSUM before its final inversion
+	if crc := crcUpdate(0, []byte("123456789")); crc != 0x89a1897f {
+		t.Errorf("crc = %08x, want 89a1897f", crc)
+	}
+}
+
+func TestPage(t *testing.T) {
+	page := &Page{
+		Flags:  BeginOfStream,
+		Serial: 0x12345678,
+		Lacing: []byte{19},
+		Body:   NewOpusHead(48_000, 2).Bytes(),
+	}
+	if b := page.Bytes(); !bytes.Equal(b, referencePage) {
+		t.Errorf("page = % x, want % x", b, referencePage)
+	}
+
+	p, err := ReadPage(bytes.NewReader(referencePage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.IsBeginOfStream() || p.IsContinued() || p.IsEndOfStream() || p.Serial != 0x12345678 || p.Granule != 0 || !bytes.Equal(p.Body, referencePage[28:]) {
+		t.Errorf("page = %+v", p)
+	}
+}
+
+func TestReadPageErrors(t *testing.T) {
+	corrupted := bytes.Clone(referencePage)
+	corrupted[len(corrupted)-1] = 1
+	truncated := referencePage[:len(referencePage)-1]
+	tests := []struct {
+		b   []byte
+		err error
+	}{
+		{corrupted, InvalidChecksum},
+		{truncated, io.ErrUnexpectedEOF},
+		{append([]byte("OggT"), referencePage[4:]...), InvalidPage},
+		{nil, io.EOF},
+	}
+	for _, test := range tests {
+		if _, err := ReadPage(bytes.NewReader(test.b)); !errors.Is(err, test.err) {
+			t.Errorf("ReadPage: %v, want %v", err, test.err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package ogg
+
+import (
+	"io"
+)
+
+type Packet struct {
+	Data   []byte
+	Serial uint32
+	// Granule is the granule position of the page if the packet is the last one finished on it, otherwise NoGranule
+	Granule       int64
+	BeginOfStream bool
+	EndOfStream   bool
+}
+
+// Reader splits an Ogg stream into packets, packets of multiplexed logical streams are returned in the order
+// they are finished.
+type Reader struct {
+	r       io.Reader
+	page    *Page
+	segment int // next lacing value of the page
+	offset  int // body offset of the next segment
+	lastEnd int // lacing index of the last packet finished on the page
+	skip    bool
+	partial map[uint32][]byte
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		r:       r,
+		partial: make(map[uint32][]byte),
+	}
+}
+
+func (r *Reader) ReadPacket() (*Packet, error) {
+	for {
+		if r.page == nil || r.segment == len(r.page.Lacing) {
+			if err := r.nextPage(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		p := r.page
+		size := int(p.Lacing[r.segment])
+		data := append(r.partial[p.Serial], p.Body[r.offset:r.offset+size]...)
+		index := r.segment
+		r.segment++
+		r.offset += size
+
+		if size == maxSegmentSize {
+			r.partial[p.Serial] = data
+			continue
+		}
+		delete(r.partial, p.Serial)
+		if r.skip {
+			// The tail of a packet whose beginning was not read
+			r.skip = false
+			continue
+		}
+
+		packet := &Packet{
+			Data:          data,
+			Serial:        p.Serial,
+			Granule:       NoGranule,
+			BeginOfStream: p.IsBeginOfStream() && index == 0,
+		}
+		if index == r.lastEnd {
+			packet.Granule = p.Granule
+			packet.EndOfStream = p.IsEndOfStream()
+		}
+		return packet, nil
+	}
+}
+
+// Page returns the page the last packet was read from.
+func (r *Reader) Page() *Page {
+	return r.page
+}
+
+func (r *Reader) nextPage() error {
+	p, err := ReadPage(r.r)
+	if err != nil {
+		return err
+	}
+
+	_, hasPartial := r.partial[p.Serial]
+	r.skip = false
+	if p.IsContinued() && !hasPartial {
+		r.skip = true
+	} else if !p.IsContinued() && hasPartial {
+		delete(r.partial, p.Serial)
+	}
+
+	r.page = p
+	r.segment = 0
+	r.offset = 0
+	r.lastEnd = -1
+	for i, v := range p.Lacing {
+		if v < maxSegmentSize {
+			r.lastEnd = i
+		}
+	}
+
+	return nil
+}
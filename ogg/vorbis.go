@@ -0,0 +1,65 @@
+package ogg
+
+import "encoding/binary"
+
+const vorbisIdentificationSize = 30
+
+// VorbisHead is the identification header of a Vorbis stream.
+type VorbisHead struct {
+	Version        uint32
+	Channels       uint8
+	SampleRate     uint32
+	BitrateMaximum int32
+	BitrateNominal int32
+	BitrateMinimum int32
+	BlockSize0     int
+	BlockSize1     int
+}
+
+func ParseVorbisHead(b []byte) (*VorbisHead, error) {
+	if len(b) < vorbisIdentificationSize || b[0] != 1 || string(b[1:7]) != "vorbis" || b[29]&1 == 0 {
+		return nil, InvalidVorbis
+	}
+
+	h := &VorbisHead{
+		Version:        binary.LittleEndian.Uint32(b[7:11]),
+		Channels:       b[11],
+		SampleRate:     binary.LittleEndian.Uint32(b[12:16]),
+		BitrateMaximum: int32(binary.LittleEndian.Uint32(b[16:20])),
+		BitrateNominal: int32(binary.LittleEndian.Uint32(b[20:24])),
+		BitrateMinimum: int32(binary.LittleEndian.Uint32(b[24:28])),
+		BlockSize0:     1 << (b[28] & 0x0F),
+		BlockSize1:     1 << (b[28] >> 4),
+	}
+	if h.Version != 0 || h.Channels == 0 || h.SampleRate == 0 {
+		return nil, InvalidVorbis
+	}
+
+	return h, nil
+}
+
+// VorbisComment is the comment header of a Vorbis stream.
+type VorbisComment struct {
+	Vendor   string
+	Comments Comments
+}
+
+func ParseVorbisComment(b []byte) (*VorbisComment, error) {
+	if len(b) < 7 || b[0] != 3 || string(b[1:7]) != "vorbis" {
+		return nil, InvalidVorbis
+	}
+
+	vendor, comments, rest, ok := ParseComments(b[7:])
+	if !ok || len(rest) < 1 || rest[0]&1 == 0 {
+		return nil, InvalidVorbis
+	}
+	return &VorbisComment{
+		Vendor:   vendor,
+		Comments: comments,
+	}, nil
+}
+
+func (c *VorbisComment) Bytes() []byte {
+	b := AppendComments(append([]byte{3}, "vorbis"...), c.Vendor, c.Comments)
+	return append(b, 1)
+}
@@ -0,0 +1,51 @@
+package ogg
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestVorbisHead(t *testing.T) {
+	// identification header of a stereo stream at 44.1 kHz of 128 kbit/s with blocks of 256 and 2048 samples
+	b := []byte{
+		0x01, 'v', 'o', 'r', 'b', 'i', 's', 0x00, 0x00, 0x00, 0x00, 0x02, 0x44, 0xac, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0xf4, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0xb8, 0x01,
+	}
+	h, err := ParseVorbisHead(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Channels != 2 || h.SampleRate != 44_100 || h.BitrateNominal != 128_000 || h.BlockSize0 != 256 || h.BlockSize1 != 2048 {
+		t.Errorf("head %+v", h)
+	}
+
+	// the framing bit is required
+	b[29] = 0
+	if _, err = ParseVorbisHead(b); !errors.Is(err, InvalidVorbis) {
+		t.Errorf("head without framing bit: %v, want %v", err, InvalidVorbis)
+	}
+}
+
+func TestVorbisComment(t *testing.T) {
+	comment := &VorbisComment{Vendor: "Xiph.Org libVorbis I 20200704 (Reducing Environment)", Comments: Comments{"TITLE=call", "ARTIST=operator"}}
+	b := comment.Bytes()
+	if b[0] != 3 || string(b[1:7]) != "vorbis" || b[len(b)-1] != 1 {
+		t.Errorf("comment header = %q", b)
+	}
+
+	parsed, err := ParseVorbisComment(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Vendor != comment.Vendor || len(parsed.Comments) != 2 || parsed.Comments.Get("artist") != "operator" {
+		t.Errorf("parsed %+v", parsed)
+	}
+
+	if _, err = ParseVorbisComment(b[:len(b)-1]); !errors.Is(err, InvalidVorbis) {
+		t.Errorf("comment without framing bit: %v, want %v", err, InvalidVorbis)
+	}
+	if _, err = ParseVorbisComment(bytes.Replace(b, []byte("vorbis"), []byte("VORBIS"), 1)); !errors.Is(err, InvalidVorbis) {
+		t.Errorf("comment of another codec: %v, want %v", err, InvalidVorbis)
+	}
+}
@@ -0,0 +1,116 @@
+package ogg
+
+import (
+	"io"
+)
+
+// Writer packs packets of a single logical stream into Ogg pages.
+// Packets are collected into a page until it is full or Flush is called, packets larger than a page are continued
+// on the next ones.
+type Writer struct {
+	w        io.Writer
+	serial   uint32
+	sequence uint32
+	flags    byte
+	granule  int64
+	lacing   []byte
+	body     []byte
+	finished bool
+}
+
+func NewWriter(w io.Writer, serial uint32) *Writer {
+	return &Writer{
+		w:      w,
+		serial: serial,
+		flags:  BeginOfStream,
+	}
+}
+
+// WritePacket adds the packet to the current page, granule is the position at the end of the packet.
+func (w *Writer) WritePacket(packet []byte, granule int64) error {
+	if w.finished {
+		return StreamIsFinished
+	}
+
+	for {
+		size := len(packet)
+		if size > maxSegmentSize {
+			size = maxSegmentSize
+		}
+		w.lacing = append(w.lacing, byte(size))
+		w.body = append(w.body, packet[:size]...)
+		packet = packet[size:]
+		finished := size < maxSegmentSize
+
+		if finished {
+			w.granule = granule
+		}
+		if len(w.lacing) == maxSegments {
+			if err := w.writePage(finished, 0); err != nil {
+				return err
+			}
+			if !finished {
+				w.flags |= Continued
+			}
+		}
+		if finished {
+			return nil
+		}
+	}
+}
+
+// Flush writes the current page even if it is not full, e.g. to keep header packets on their own pages.
+func (w *Writer) Flush() error {
+	if len(w.lacing) == 0 {
+		return nil
+	}
+	return w.writePage(true, 0)
+}
+
+// Close writes the last page of the stream with the end of stream flag.
+func (w *Writer) Close() error {
+	if w.finished {
+		return nil
+	}
+	err := w.writePage(true, EndOfStream)
+	w.finished = true
+	return err
+}
+
+func (w *Writer) Serial() uint32 {
+	return w.serial
+}
+
+func (w *Writer) writePage(packetFinished bool, flags byte) error {
+	granule := w.granule
+	if !packetFinished && !w.hasFinishedPacket() {
+		granule = NoGranule
+	}
+
+	page := &Page{
+		Flags:    w.flags | flags,
+		Granule:  granule,
+		Serial:   w.serial,
+		Sequence: w.sequence,
+		Lacing:   w.lacing,
+		Body:     w.body,
+	}
+	if _, err := w.w.Write(page.Bytes()); err != nil {
+		return err
+	}
+
+	w.sequence++
+	w.flags = 0
+	w.lacing = w.lacing[:0]
+	w.body = w.body[:0]
+	return nil
+}
+
+func (w *Writer) hasFinishedPacket() bool {
+	for _, v := range w.lacing {
+		if v < maxSegmentSize {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package ogg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+func packet(size int, seed byte) []byte {
+	p := make([]byte, size)
+	for i := range p {
+		p[i] = seed + byte(i)
+	}
+	return p
+}
+
+func TestWriterReader(t *testing.T) {
+	// packets of segment boundary sizes and a packet spanning pages
+	sizes := []int{0, 1, 254, 255, 256, 510, 70_000, 3}
+	var file bytes.Buffer
+	w := NewWriter(&file, 7)
+	for i, size := range sizes {
+		if err := w.WritePacket(packet(size, byte(i)), int64(1000*(i+1))); err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			w.Flush()
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WritePacket([]byte{1}, 0); !errors.Is(err, StreamIsFinished) {
+		t.Errorf("WritePacket into the closed stream: %v, want %v", err, StreamIsFinished)
+	}
+
+	r := NewReader(bytes.NewReader(file.Bytes()))
+	for i, size := range sizes {
+		p, err := r.ReadPacket()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(p.Data, packet(size, byte(i))) || p.Serial != 7 || p.BeginOfStream != (i == 0) {
+			t.Errorf("packet %d of %d bytes, serial=%d, bos=%t", i, len(p.Data), p.Serial, p.BeginOfStream)
+		}
+		// the granule position belongs to the last packet finished on a page
+		if p.Granule != NoGranule && p.Granule != int64(1000*(i+1)) {
+			t.Errorf("packet %d granule = %d", i, p.Granule)
+		}
+		if i == 0 && p.Granule != 1000 || i == len(sizes)-1 && (p.Granule != 8000 || !p.EndOfStream) {
+			t.Errorf("packet %d granule=%d, eos=%t", i, p.Granule, p.EndOfStream)
+		}
+	}
+	if _, err := r.ReadPacket(); err != io.EOF {
+		t.Errorf("ReadPacket at the end: %v, want %v", err, io.EOF)
+	}
+
+	// the pages of the large packet are continued, a page without a finished packet has no granule position
+	var continued int
+	for b := bytes.NewReader(file.Bytes()); ; {
+		page, err := ReadPage(b)
+		if err != nil {
+			break
+		}
+		if page.IsContinued() {
+			continued++
+		}
+		if !slices.ContainsFunc(page.Lacing, func(v byte) bool { return v < maxSegmentSize }) && page.Granule != NoGranule {
+			t.Errorf("page %d without finished packets has granule %d", page.Sequence, page.Granule)
+		}
+	}
+	if continued == 0 {
+		t.Error("no continued pages")
+	}
+}
+
+func TestReaderSkipsPartialPacket(t *testing.T) {
+	var file bytes.Buffer
+	w := NewWriter(&file, 1)
+	w.WritePacket(packet(70_000, 0), 100)
+	w.WritePacket(packet(10, 1), 200)
+	w.Close()
+
+	// reading from the second page the tail of the large packet is skipped
+	first, err := ReadPage(bytes.NewReader(file.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReader(bytes.NewReader(file.Bytes()[len(first.Bytes()):]))
+	p, err := r.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(p.Data, packet(10, 1)) || p.Granule != 200 {
+		t.Errorf("packet of %d bytes, granule=%d", len(p.Data), p.Granule)
+	}
+}
@@ -0,0 +1,125 @@
+package opus
+
+/*
+#cgo pkg-config: opus
+
+#include <opus.h>
+
+static int decoder_reset(OpusDecoder *decoder) {
+	return opus_decoder_ctl(decoder, OPUS_RESET_STATE);
+}
+*/
+import "C"
+import (
+	"time"
+	"unsafe"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+// maxFrameDuration is the longest audio an Opus packet may contain
+const maxFrameDuration = 120 * time.Millisecond
+
+// Decoder decompresses Opus packets into mono 16-bit PCM.
+type Decoder struct {
+	decoder *C.OpusDecoder
+
+	pcmCodec *audiocodec.Codec
+	codec    *audiocodec.Codec
+	samples  []C.opus_int16
+}
+
+// NewDecoder creates a decoder producing PCM codec with the sample rate of 8, 12, 16, 24 or 48 kHz,
+// Opus resamples internally to any of them.
+func NewDecoder(pcmCodec *audiocodec.Codec) (*Decoder, error) {
+	if err := checkPcmCodec(pcmCodec); err != nil {
+		return nil, err
+	}
+
+	var errCode C.int
+	d := &Decoder{
+		decoder:  C.opus_decoder_create(C.opus_int32(pcmCodec.SampleRate), 1, &errCode),
+		pcmCodec: pcmCodec,
+		codec:    audiocodec.Opus48kHzCodec,
+		samples:  make([]C.opus_int16, pcmCodec.SampleCountByDuration(maxFrameDuration)),
+	}
+	if errCode != C.OPUS_OK {
+		return nil, opusError(errCode)
+	}
+
+	return d, nil
+}
+
+// Decode returns PCM data of the packet. An empty packet (e.g. skipped by DTX) produces no data,
+// use Conceal to fill the gap.
+func (d *Decoder) Decode(packet []byte) ([]byte, error) {
+	if len(packet) == 0 {
+		return nil, nil
+	}
+	return d.decode(packet, len(d.samples), false)
+}
+
+// DecodeFec recovers the packet preceding this one from its forward error correction data.
+// The duration of the lost packet has to be known, e.g. from RTP timestamps.
+func (d *Decoder) DecodeFec(packet []byte, lostDuration time.Duration) ([]byte, error) {
+	return d.decode(packet, d.frameSize(lostDuration), true)
+}
+
+// Conceal synthesizes the audio of a lost packet of the duration.
+func (d *Decoder) Conceal(lostDuration time.Duration) ([]byte, error) {
+	return d.decode(nil, d.frameSize(lostDuration), false)
+}
+
+func (d *Decoder) decode(packet []byte, frameSize int, fec bool) ([]byte, error) {
+	var data *C.uchar
+	if len(packet) > 0 {
+		data = (*C.uchar)(unsafe.Pointer(&packet[0]))
+	}
+
+	var decodeFec C.int
+	if fec {
+		decodeFec = 1
+	}
+
+	n := C.opus_decode(d.decoder, data, C.opus_int32(len(packet)), &d.samples[0], C.int(frameSize), decodeFec)
+	if n < 0 {
+		return nil, opusError(n)
+	}
+
+	pcm := make([]byte, d.pcmCodec.SizeBySampleCount(int(n)))
+	for i, sample := range d.samples[:n] {
+		pcm[2*i] = byte(sample)
+		pcm[2*i+1] = byte(uint16(sample) >> 8)
+	}
+	return pcm, nil
+}
+
+func (d *Decoder) frameSize(duration time.Duration) int {
+	frameSize := int(int64(duration) * int64(d.pcmCodec.SampleRate) / int64(time.Second))
+	if frameSize > len(d.samples) {
+		return len(d.samples)
+	}
+	return frameSize
+}
+
+func (d *Decoder) Reset() error {
+	if errCode := C.decoder_reset(d.decoder); errCode != C.OPUS_OK {
+		return opusError(errCode)
+	}
+	return nil
+}
+
+func (d *Decoder) Free() {
+	C.opus_decoder_destroy(d.decoder)
+	d.decoder = nil
+}
+
+// Codec returns the codec of the decoded stream.
+func (d *Decoder) Codec() *audiocodec.Codec {
+	return d.codec
+}
+
+// PcmCodec returns the codec of the produced audio, it can be passed to the resamplers.
+func (d *Decoder) PcmCodec() *audiocodec.Codec {
+	return d.pcmCodec
+}
@@ -0,0 +1,212 @@
+package opus
+
+/*
+#cgo pkg-config: opus
+
+#include <opus.h>
+
+static int encoder_set(OpusEncoder *encoder, int request, opus_int32 value) {
+	return opus_encoder_ctl(encoder, request, value);
+}
+
+static int encoder_reset(OpusEncoder *encoder) {
+	return opus_encoder_ctl(encoder, OPUS_RESET_STATE);
+}
+*/
+import "C"
+import (
+	"fmt"
+	"time"
+	"unsafe"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+type Application C.int
+
+// Voip - best for most VoIP/videoconference applications where listening quality and intelligibility matter most.
+// Audio - best for broadcast/high-fidelity application where the decoded audio should be as close as possible to
+// the input.
+// RestrictedLowDelay - only use when lowest-achievable latency is what matters most.
+const (
+	Voip               Application = C.OPUS_APPLICATION_VOIP
+	Audio              Application = C.OPUS_APPLICATION_AUDIO
+	RestrictedLowDelay Application = C.OPUS_APPLICATION_RESTRICTED_LOWDELAY
+
+	// maxPacketSize is the recommended size of the packet buffer
+	maxPacketSize = 4000
+)
+
+type EncoderOptions struct {
+	Application Application
+	// Bitrate in bits per second, zero lets the encoder choose it
+	Bitrate int
+	// Complexity in range 0-10
+	Complexity int
+	// Fec enables inband forward error correction tuned for PacketLoss percent of lost packets
+	Fec        bool
+	PacketLoss int
+	// Dtx enables discontinuous transmission: during silence a full packet is produced once per 400 ms,
+	// the other packets are at most 2 bytes long
+	Dtx bool
+	// FrameDuration is one of 2.5, 5, 10, 20, 40 or 60 ms
+	FrameDuration time.Duration
+}
+
+func DefaultEncoderOptions() EncoderOptions {
+	return EncoderOptions{
+		Application:   Voip,
+		Complexity:    10,
+		FrameDuration: 20 * time.Millisecond,
+	}
+}
+
+// Encoder compresses mono 16-bit PCM into Opus packets.
+type Encoder struct {
+	encoder *C.OpusEncoder
+
+	pcmCodec  *audiocodec.Codec
+	codec     *audiocodec.Codec
+	frameSize int // samples per frame
+	buffer    []byte
+	samples   []C.opus_int16
+	packet    []byte
+}
+
+// NewEncoder creates an encoder of PCM codec with the sample rate of 8, 12, 16, 24 or 48 kHz.
+func NewEncoder(pcmCodec *audiocodec.Codec, options EncoderOptions) (*Encoder, error) {
+	if err := checkPcmCodec(pcmCodec); err != nil {
+		return nil, err
+	}
+
+	frameSize := int(int64(options.FrameDuration) * int64(pcmCodec.SampleRate) / int64(time.Second))
+	if !isValidFrameSize(frameSize, pcmCodec.SampleRate) {
+		return nil, fmt.Errorf("%w: %s", InvalidFrameDuration, options.FrameDuration)
+	}
+
+	var errCode C.int
+	e := &Encoder{
+		encoder:   C.opus_encoder_create(C.opus_int32(pcmCodec.SampleRate), 1, C.int(options.Application), &errCode),
+		pcmCodec:  pcmCodec,
+		codec:     audiocodec.Opus48kHzCodec,
+		frameSize: frameSize,
+		samples:   make([]C.opus_int16, frameSize),
+		packet:    make([]byte, maxPacketSize),
+	}
+	if errCode != C.OPUS_OK {
+		return nil, opusError(errCode)
+	}
+
+	bitrate := C.opus_int32(C.OPUS_AUTO)
+	if options.Bitrate > 0 {
+		bitrate = C.opus_int32(options.Bitrate)
+	}
+	settings := []struct {
+		request C.int
+		value   C.opus_int32
+	}{
+		{C.OPUS_SET_BITRATE_REQUEST, bitrate},
+		{C.OPUS_SET_COMPLEXITY_REQUEST, C.opus_int32(options.Complexity)},
+		{C.OPUS_SET_INBAND_FEC_REQUEST, boolValue(options.Fec)},
+		{C.OPUS_SET_PACKET_LOSS_PERC_REQUEST, C.opus_int32(options.PacketLoss)},
+		{C.OPUS_SET_DTX_REQUEST, boolValue(options.Dtx)},
+	}
+	for _, setting := range settings {
+		if errCode = C.encoder_set(e.encoder, setting.request, setting.value); errCode != C.OPUS_OK {
+			e.Free()
+			return nil, opusError(errCode)
+		}
+	}
+
+	return e, nil
+}
+
+// Encode buffers PCM data and returns the packets of all completed frames.
+// With DTX enabled libopus encodes silent frames into packets of at most 2 bytes (the TOC byte only),
+// such packets should not be sent.
+func (e *Encoder) Encode(data []byte) ([][]byte, error) {
+	e.buffer = append(e.buffer, data...)
+
+	frameBytes := e.pcmCodec.SizeBySampleCount(e.frameSize)
+	var packets [][]byte
+	for len(e.buffer) >= frameBytes {
+		packet, err := e.encode(e.buffer[:frameBytes])
+		if err != nil {
+			return nil, err
+		}
+		packets = append(packets, packet)
+		e.buffer = e.buffer[frameBytes:]
+	}
+	e.buffer = append(e.buffer[:0:0], e.buffer...)
+
+	return packets, nil
+}
+
+// Flush encodes the buffered incomplete frame padded with silence.
+func (e *Encoder) Flush() ([]byte, error) {
+	if len(e.buffer) == 0 {
+		return nil, nil
+	}
+
+	frame := make([]byte, e.pcmCodec.SizeBySampleCount(e.frameSize))
+	copy(frame, e.buffer)
+	e.buffer = e.buffer[:0]
+
+	return e.encode(frame)
+}
+
+func (e *Encoder) encode(frame []byte) ([]byte, error) {
+	for i := range e.samples {
+		e.samples[i] = C.opus_int16(int16(frame[2*i]) | int16(frame[2*i+1])<<8)
+	}
+
+	n := C.opus_encode(
+		e.encoder,
+		&e.samples[0],
+		C.int(e.frameSize),
+		(*C.uchar)(unsafe.Pointer(&e.packet[0])),
+		C.opus_int32(len(e.packet)),
+	)
+	if n < 0 {
+		return nil, opusError(C.int(n))
+	}
+
+	packet := make([]byte, int(n))
+	copy(packet, e.packet)
+	return packet, nil
+}
+
+func (e *Encoder) Reset() error {
+	e.buffer = e.buffer[:0]
+	if errCode := C.encoder_reset(e.encoder); errCode != C.OPUS_OK {
+		return opusError(errCode)
+	}
+	return nil
+}
+
+func (e *Encoder) Free() {
+	C.opus_encoder_destroy(e.encoder)
+	e.encoder = nil
+}
+
+// FrameDuration returns the duration of audio in every packet.
+func (e *Encoder) FrameDuration() time.Duration {
+	return time.Duration(e.frameSize) * time.Second / time.Duration(e.pcmCodec.SampleRate)
+}
+
+// Codec returns the codec of the produced stream.
+func (e *Encoder) Codec() *audiocodec.Codec {
+	return e.codec
+}
+
+// PcmCodec returns the codec of the encoded audio.
+func (e *Encoder) PcmCodec() *audiocodec.Codec {
+	return e.pcmCodec
+}
+
+func boolValue(v bool) C.opus_int32 {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,61 @@
+package opus
+
+/*
+#cgo pkg-config: opus
+
+#include <opus.h>
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+var (
+	InvalidFrameDuration = errors.New("opus frame duration must be 2.5, 5, 10, 20, 40 or 60 ms")
+	NotSupportedRate     = errors.New("opus supports sample rates of 8, 12, 16, 24 and 48 kHz")
+)
+
+// PacketSampleCount returns the number of samples per channel in the packet at the sample rate.
+func PacketSampleCount(packet []byte, sampleRate int) (int, error) {
+	if len(packet) == 0 {
+		return 0, nil
+	}
+
+	n := C.opus_packet_get_nb_samples((*C.uchar)(&packet[0]), C.opus_int32(len(packet)), C.opus_int32(sampleRate))
+	if n < 0 {
+		return 0, opusError(n)
+	}
+	return int(n), nil
+}
+
+func checkPcmCodec(codec *audiocodec.Codec) error {
+	if !codec.IsPcm() {
+		return audiocodec.NotPcm
+	}
+	if codec.BitRate != 16 {
+		return fmt.Errorf("not supported bit rate: %d", codec.BitRate)
+	}
+
+	switch codec.SampleRate {
+	case 8_000, 12_000, 16_000, 24_000, 48_000:
+		return nil
+	}
+	return fmt.Errorf("%w: %d", NotSupportedRate, codec.SampleRate)
+}
+
+// isValidFrameSize checks that the frame lasts 2.5, 5, 10, 20, 40 or 60 ms
+func isValidFrameSize(frameSize int, sampleRate int) bool {
+	for _, tenthsOfMs := range []int{25, 50, 100, 200, 400, 600} {
+		if frameSize*10_000 == tenthsOfMs*sampleRate {
+			return true
+		}
+	}
+	return false
+}
+
+func opusError(errCode C.int) error {
+	return fmt.Errorf("opus error code: %d; %s", int(errCode), C.GoString(C.opus_strerror(errCode)))
+}
@@ -0,0 +1,9 @@
+package pcm
+
+import "errors"
+
+var (
+	NotSupportedCodec    = errors.New("codec is not supported for sample conversion")
+	InvalidFrameSize     = errors.New("frame size is not a multiple of sample size")
+	DifferentSampleRates = errors.New("codecs have different sample rates")
+)
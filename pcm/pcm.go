@@ -0,0 +1,173 @@
+package pcm
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/binary"
+	"github.com/URALINNOVATSIYA/audiocodec/g711"
+)
+
+// maxSample32bit is the largest value that survives conversion to 32-bit integers without overflow,
+// 1 itself is rounded up to 2^31 by float32
+var maxSample32bit = math.Nextafter32(1, 0)
+
+// Decoder returns a function converting a single sample of the codec into a float in range [-1, 1].
+// Linear PCM of 8, 16, 24 and 32 bits, floating point PCM of 32 and 64 bits and G.711 are supported.
+func Decoder(codec *audiocodec.Codec) (func(sample []byte) float32, error) {
+	switch codec.Name {
+	case audiocodec.Pcm:
+		switch codec.BitRate {
+		case 8:
+			return binary.Bytes8bitToFloat32, nil
+		case 16:
+			return binary.Bytes16bitToFloat32, nil
+		case 24:
+			return binary.Bytes24bitToFloat32, nil
+		case 32:
+			return binary.Bytes32bitToFloat32, nil
+		}
+	case audiocodec.PcmFloat:
+		switch codec.BitRate {
+		case 32:
+			return binary.BytesFloat32ToFloat32, nil
+		case 64:
+			return binary.BytesFloat64ToFloat32, nil
+		}
+	case audiocodec.PcmA:
+		return func(sample []byte) float32 {
+			return int16ToFloat32(g711.DecodeAlaw(sample[0]))
+		}, nil
+	case audiocodec.PcmU:
+		return func(sample []byte) float32 {
+			return int16ToFloat32(g711.DecodeUlaw(sample[0]))
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", NotSupportedCodec, codec.Name)
+	}
+
+	return nil, fmt.Errorf("not supported bit rate: %d", codec.BitRate)
+}
+
+// Encoder returns a function converting a float sample into the codec, values out of range [-1, 1] are clipped.
+func Encoder(codec *audiocodec.Codec) (func(sample float32, buf []byte), error) {
+	var encode func(sample float32, buf []byte)
+	switch codec.Name {
+	case audiocodec.Pcm:
+		switch codec.BitRate {
+		case 8:
+			encode = binary.Float32ToBytes8bit
+		case 16:
+			encode = binary.Float32ToBytes16bit
+		case 24:
+			encode = binary.Float32ToBytes24bit
+		case 32:
+			encode = func(sample float32, buf []byte) {
+				binary.Float32ToBytes32bit(min(sample, maxSample32bit), buf)
+			}
+		default:
+			return nil, fmt.Errorf("not supported bit rate: %d", codec.BitRate)
+		}
+	case audiocodec.PcmFloat:
+		switch codec.BitRate {
+		case 32:
+			encode = binary.Float32ToBytesFloat32
+		case 64:
+			encode = binary.Float32ToBytesFloat64
+		default:
+			return nil, fmt.Errorf("not supported bit rate: %d", codec.BitRate)
+		}
+	case audiocodec.PcmA:
+		encode = func(sample float32, buf []byte) {
+			buf[0] = g711.EncodeAlaw(float32ToInt16(sample))
+		}
+	case audiocodec.PcmU:
+		encode = func(sample float32, buf []byte) {
+			buf[0] = g711.EncodeUlaw(float32ToInt16(sample))
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", NotSupportedCodec, codec.Name)
+	}
+
+	return func(sample float32, buf []byte) {
+		encode(Clip(sample), buf)
+	}, nil
+}
+
+// Decode converts the frame into float samples appending them to dst.
+func Decode(codec *audiocodec.Codec, dst []float32, frame []byte) ([]float32, error) {
+	decode, err := Decoder(codec)
+	if err != nil {
+		return nil, err
+	}
+
+	sampleSize := codec.SampleSize()
+	if len(frame)%sampleSize != 0 {
+		return nil, fmt.Errorf("%w: size=%d, sample size=%d", InvalidFrameSize, len(frame), sampleSize)
+	}
+
+	for i := 0; i < len(frame); i += sampleSize {
+		dst = append(dst, decode(frame[i:i+sampleSize]))
+	}
+	return dst, nil
+}
+
+// Encode converts float samples into the codec appending them to dst.
+func Encode(codec *audiocodec.Codec, dst []byte, samples []float32) ([]byte, error) {
+	encode, err := Encoder(codec)
+	if err != nil {
+		return nil, err
+	}
+
+	sampleSize := codec.SampleSize()
+	pos := len(dst)
+	dst = append(dst, make([]byte, len(samples)*sampleSize)...)
+	for _, sample := range samples {
+		encode(sample, dst[pos:pos+sampleSize])
+		pos += sampleSize
+	}
+	return dst, nil
+}
+
+// Transcode converts the frame from one codec into another with the same sample rate.
+func Transcode(from *audiocodec.Codec, to *audiocodec.Codec, frame []byte) ([]byte, error) {
+	if from.SampleRate != to.SampleRate {
+		return nil, fmt.Errorf("%w: %d != %d", DifferentSampleRates, from.SampleRate, to.SampleRate)
+	}
+	if from.IsEqual(to) {
+		return frame, nil
+	}
+
+	samples, err := Decode(from, make([]float32, 0, from.SampleCountBySize(len(frame))), frame)
+	if err != nil {
+		return nil, err
+	}
+	return Encode(to, make([]byte, 0, to.SizeBySampleCount(len(samples))), samples)
+}
+
+// int16ToFloat32 and float32ToInt16 scale G.711 samples the same way as 16-bit PCM is scaled by the binary package,
+// so transcoding between them is lossless
+func int16ToFloat32(v int16) float32 {
+	if v < 0 {
+		return float32(v) / 32_768
+	}
+	return float32(v) / 32_767
+}
+
+func float32ToInt16(sample float32) int16 {
+	if sample < 0 {
+		return int16(32_768 * sample)
+	}
+	return int16(32_767 * sample)
+}
+
+func Clip(sample float32) float32 {
+	if sample > 1 {
+		return 1
+	}
+	if sample < -1 {
+		return -1
+	}
+	return sample
+}
@@ -0,0 +1,118 @@
+package pcm
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/g711"
+)
+
+// allSamples returns every value of the sample size in bytes, or every step-th value for the larger sizes
+func allSamples(sampleSize int, step int) []byte {
+	max := 1 << (8 * sampleSize)
+	var frame []byte
+	for v := 0; v < max; v += step {
+		for i := 0; i < sampleSize; i++ {
+			frame = append(frame, byte(v>>(8*i)))
+		}
+	}
+	return frame
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		bitRate int
+		step    int
+	}{
+		{8, 1},
+		{16, 1},
+		{24, 37},
+	}
+	for _, tt := range tests {
+		codec := &audiocodec.Codec{Name: audiocodec.Pcm, SampleRate: 8_000, BitRate: tt.bitRate}
+		frame := allSamples(codec.SampleSize(), tt.step)
+		samples, err := Decode(codec, nil, frame)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range samples {
+			if s < -1 || s > 1 {
+				t.Fatalf("%d bits: sample %v is out of range", tt.bitRate, s)
+			}
+		}
+		encoded, err := Encode(codec, nil, samples)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(encoded, frame) {
+			t.Errorf("%d bits: round trip is lossy", tt.bitRate)
+		}
+	}
+}
+
+func TestTranscodeG711(t *testing.T) {
+	pcm16 := allSamples(2, 1)
+	codes := allSamples(1, 1)
+	tests := []struct {
+		codec  *audiocodec.Codec
+		encode func(dst []byte, pcm []byte) []byte
+		decode func(dst []byte, frame []byte) []byte
+	}{
+		{audiocodec.PcmA8kHz8bCodec, g711.EncodeAlawFrame, g711.DecodeAlawFrame},
+		{audiocodec.PcmU8kHz8bCodec, g711.EncodeUlawFrame, g711.DecodeUlawFrame},
+	}
+	for _, tt := range tests {
+		// transcoding must be exactly the same as G.711 coding of 16-bit PCM
+		encoded, err := Transcode(audiocodec.Pcm8kHz16bCodec, tt.codec, pcm16)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(encoded, tt.encode(nil, pcm16)) {
+			t.Errorf("%s: encoding differs from G.711", tt.codec.Name)
+		}
+
+		decoded, err := Transcode(tt.codec, audiocodec.Pcm8kHz16bCodec, codes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded, tt.decode(nil, codes)) {
+			t.Errorf("%s: decoding differs from G.711", tt.codec.Name)
+		}
+	}
+}
+
+func TestEncodeClips(t *testing.T) {
+	frame, err := Encode(audiocodec.Pcm8kHz16bCodec, nil, []float32{1.5, -1.5, float32(math.Inf(1))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0xff, 0x7f, 0x00, 0x80, 0xff, 0x7f}; !bytes.Equal(frame, want) {
+		t.Errorf("frame = % x, want % x", frame, want)
+	}
+
+	// full scale must not overflow 32-bit integers
+	if frame, err = Encode(audiocodec.Pcm44kHz32bCodec, nil, []float32{1, -1}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0x80, 0xff, 0xff, 0x7f, 0x00, 0x00, 0x00, 0x80}; !bytes.Equal(frame, want) {
+		t.Errorf("frame = % x, want % x", frame, want)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := Decoder(audiocodec.GsmCodec); !errors.Is(err, NotSupportedCodec) {
+		t.Errorf("Decoder = %v, want %v", err, NotSupportedCodec)
+	}
+	if _, err := Encoder(audiocodec.GsmCodec); !errors.Is(err, NotSupportedCodec) {
+		t.Errorf("Encoder = %v, want %v", err, NotSupportedCodec)
+	}
+	if _, err := Decode(audiocodec.Pcm8kHz16bCodec, nil, make([]byte, 3)); !errors.Is(err, InvalidFrameSize) {
+		t.Errorf("Decode = %v, want %v", err, InvalidFrameSize)
+	}
+	if _, err := Transcode(audiocodec.Pcm16kHz16bCodec, audiocodec.PcmA8kHz8bCodec, nil); !errors.Is(err, DifferentSampleRates) {
+		t.Errorf("Transcode = %v, want %v", err, DifferentSampleRates)
+	}
+}
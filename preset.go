@@ -14,6 +14,12 @@ const (
 	Pcm44kHz32bPreset Preset = "PCM_44100_32"
 	PcmA8kHz8bPreset  Preset = "PCMA_8000_8"
 	PcmU8kHz8bPreset  Preset = "PCMU_8000_8"
+	Opus48kHzPreset   Preset = "OPUS_48000_16"
+	G722Preset        Preset = "G722_16000_16"
+	GsmPreset         Preset = "GSM_8000_16"
+	AmrPreset         Preset = "AMR_8000_16"
+	AmrWbPreset       Preset = "AMR_WB_16000_16"
+	G729Preset        Preset = "G729_8000_16"
 )
 
 func MustParsePreset(s string) Preset {
@@ -38,6 +44,18 @@ func ParsePreset(s string) (Preset, error) {
 		return PcmA8kHz8bPreset, nil
 	case "PCMU_8000_8":
 		return PcmU8kHz8bPreset, nil
+	case "OPUS_48000_16":
+		return Opus48kHzPreset, nil
+	case "G722_16000_16":
+		return G722Preset, nil
+	case "GSM_8000_16":
+		return GsmPreset, nil
+	case "AMR_8000_16":
+		return AmrPreset, nil
+	case "AMR_WB_16000_16":
+		return AmrWbPreset, nil
+	case "G729_8000_16":
+		return G729Preset, nil
 	default:
 		return "", fmt.Errorf("preset \"%s\" does not exist", s)
 	}
@@ -57,6 +75,18 @@ func (p Preset) ToCodec() *Codec {
 		return PcmA8kHz8bCodec
 	case PcmU8kHz8bPreset:
 		return PcmU8kHz8bCodec
+	case Opus48kHzPreset:
+		return Opus48kHzCodec
+	case G722Preset:
+		return G722Codec
+	case GsmPreset:
+		return GsmCodec
+	case AmrPreset:
+		return AmrCodec
+	case AmrWbPreset:
+		return AmrWbCodec
+	case G729Preset:
+		return G729Codec
 	}
 
 	panic(fmt.Errorf("constant \"%s\" does not exist", p))
@@ -0,0 +1,8 @@
+package probe
+
+import "errors"
+
+var (
+	UnknownFormat     = errors.New("unknown audio format")
+	UnsupportedStream = errors.New("unsupported Ogg stream")
+)
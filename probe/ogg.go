@@ -0,0 +1,196 @@
+package probe
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/flac"
+	"github.com/URALINNOVATSIYA/audiocodec/ogg"
+)
+
+const (
+	speexHeaderSize = 80
+	// oggFlacHeaderSize is the mapping header followed by the "fLaC" marker and the STREAMINFO block header
+	oggFlacHeaderSize = 17
+)
+
+// oggStream is the first logical stream of an Ogg file identified by its header packets
+type oggStream struct {
+	serial     uint32
+	sampleRate int
+	// preSkip is the number of samples of Opus to drop at the start
+	preSkip  int64
+	info     *Info
+	comments func(packet []byte) (ogg.Comments, error)
+}
+
+// probeOgg reads the headers of the first logical stream, the duration is taken from the last granule position
+// of the stream.
+func probeOgg(r io.ReadSeeker) (*Info, error) {
+	reader := ogg.NewReader(bufio.NewReader(r))
+	var stream *oggStream
+	for stream == nil {
+		packet, err := reader.ReadPacket()
+		if err != nil {
+			return nil, err
+		}
+		if packet.BeginOfStream {
+			if stream, err = parseOggHeader(packet.Data); err != nil {
+				return nil, err
+			}
+			stream.serial = packet.Serial
+		}
+	}
+
+	for {
+		packet, err := reader.ReadPacket()
+		if err != nil {
+			return nil, err
+		}
+		if packet.Serial != stream.serial {
+			continue
+		}
+		comments, err := stream.comments(packet.Data)
+		if err != nil {
+			return nil, err
+		}
+		stream.info.Metadata = commentMetadata(comments)
+		break
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	granule := ogg.NoGranule
+	br := bufio.NewReader(r)
+	for {
+		page, err := ogg.ReadPage(br)
+		if err != nil {
+			// a truncated last page is ignored
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			return nil, err
+		}
+		if page.Serial == stream.serial && page.Granule != ogg.NoGranule {
+			granule = page.Granule
+		}
+	}
+	if granule -= stream.preSkip; granule > 0 {
+		stream.info.Duration = time.Duration(granule) * time.Second / time.Duration(stream.sampleRate)
+	}
+
+	return stream.info, nil
+}
+
+// parseOggHeader identifies Opus, Vorbis, Speex and FLAC streams by the first packet
+func parseOggHeader(packet []byte) (*oggStream, error) {
+	switch {
+	case bytes.HasPrefix(packet, []byte("OpusHead")):
+		head, err := ogg.ParseOpusHead(packet)
+		if err != nil {
+			return nil, err
+		}
+		return &oggStream{
+			sampleRate: ogg.OpusSampleRate,
+			preSkip:    int64(head.PreSkip),
+			info:       &Info{Format: Ogg, Codec: head.Codec(), Channels: int(head.Channels)},
+			comments: func(packet []byte) (ogg.Comments, error) {
+				tags, err := ogg.ParseOpusTags(packet)
+				if err != nil {
+					return nil, err
+				}
+				return tags.Comments, nil
+			},
+		}, nil
+
+	case bytes.HasPrefix(packet, []byte("\x01vorbis")):
+		head, err := ogg.ParseVorbisHead(packet)
+		if err != nil {
+			return nil, err
+		}
+		return &oggStream{
+			sampleRate: int(head.SampleRate),
+			info: &Info{
+				Format:   Ogg,
+				Codec:    audiocodec.NewCodec(audiocodec.Vorbis, int(head.SampleRate), 16),
+				Channels: int(head.Channels),
+			},
+			comments: func(packet []byte) (ogg.Comments, error) {
+				comment, err := ogg.ParseVorbisComment(packet)
+				if err != nil {
+					return nil, err
+				}
+				return comment.Comments, nil
+			},
+		}, nil
+
+	// Смещение	Размер	Описание
+	// 0x00		8		"Speex   "
+	// 0x08		20		версия библиотеки
+	// 0x1c		4		номер версии
+	// 0x20		4		размер заголовка
+	// 0x24		4		частота дискретизации
+	// 0x28		4		режим (полоса)
+	// 0x2c		4		версия битового потока режима
+	// 0x30		4		число каналов
+	// 0x34		*		битрейт, размер кадра, VBR, число кадров в пакете и др.
+	case bytes.HasPrefix(packet, []byte("Speex   ")):
+		if len(packet) < speexHeaderSize {
+			return nil, fmt.Errorf("%w: invalid Speex header", UnsupportedStream)
+		}
+		sampleRate := int(binary.LittleEndian.Uint32(packet[0x24:]))
+		channels := int(binary.LittleEndian.Uint32(packet[0x30:]))
+		if sampleRate == 0 || channels == 0 {
+			return nil, fmt.Errorf("%w: invalid Speex header", UnsupportedStream)
+		}
+		return &oggStream{
+			sampleRate: sampleRate,
+			info: &Info{
+				Format:   Ogg,
+				Codec:    audiocodec.NewCodec(audiocodec.Speex, sampleRate, 16),
+				Channels: channels,
+			},
+			comments: func(packet []byte) (ogg.Comments, error) {
+				_, comments, _, ok := ogg.ParseComments(packet)
+				if !ok {
+					return nil, fmt.Errorf("%w: invalid Speex comments", UnsupportedStream)
+				}
+				return comments, nil
+			},
+		}, nil
+
+	// Заголовок FLAC в Ogg: 0x7F "FLAC", версия отображения (2 байта), число заголовочных пакетов (2 байта),
+	// "fLaC", заголовок блока STREAMINFO (4 байта) и сам блок
+	case bytes.HasPrefix(packet, []byte("\x7fFLAC")):
+		if len(packet) < oggFlacHeaderSize || string(packet[9:13]) != "fLaC" {
+			return nil, fmt.Errorf("%w: invalid FLAC header", UnsupportedStream)
+		}
+		info, err := flac.ParseStreamInfo(packet[oggFlacHeaderSize:])
+		if err != nil {
+			return nil, err
+		}
+		return &oggStream{
+			sampleRate: info.SampleRate,
+			info:       &Info{Format: Ogg, Codec: info.Codec(), Channels: info.Channels},
+			comments: func(packet []byte) (ogg.Comments, error) {
+				// the metadata block header precedes the comments
+				if len(packet) < 4 || packet[0]&0x7F != flac.BlockVorbisComment {
+					return nil, nil
+				}
+				_, comments, _, ok := ogg.ParseComments(packet[4:])
+				if !ok {
+					return nil, fmt.Errorf("%w: invalid FLAC comments", UnsupportedStream)
+				}
+				return comments, nil
+			},
+		}, nil
+	}
+
+	return nil, UnsupportedStream
+}
@@ -0,0 +1,296 @@
+package probe
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/amr"
+	"github.com/URALINNOVATSIYA/audiocodec/flac"
+	"github.com/URALINNOVATSIYA/audiocodec/mp3"
+	"github.com/URALINNOVATSIYA/audiocodec/ogg"
+)
+
+// sniffSize is enough for the magic bytes of every format and the MPEG audio frame header
+const sniffSize = 16
+
+type Format string
+
+const (
+	Wav  Format = "WAV"
+	Rf64 Format = "RF64"
+	Aiff Format = "AIFF"
+	Au   Format = "AU"
+	Ogg  Format = "OGG"
+	Flac Format = "FLAC"
+	Mp3  Format = "MP3"
+	Amr  Format = "AMR"
+)
+
+// Info describes an audio file. Codec is the codec of the stored audio, for FLAC it is the PCM of the decoded samples.
+// Metadata is keyed by upper-cased Vorbis comment field names (TITLE, ARTIST, ...), ID3v2 frames and WAV INFO items
+// without such a name keep their ID.
+type Info struct {
+	Format   Format
+	Codec    *audiocodec.Codec
+	Channels int
+	Duration time.Duration
+	Metadata map[string]string
+}
+
+// id3Fields maps ID3v2 text frames onto Vorbis comment field names
+var id3Fields = map[string]string{
+	"TIT2": "TITLE",
+	"TPE1": "ARTIST",
+	"TPE2": "ALBUMARTIST",
+	"TALB": "ALBUM",
+	"TCOM": "COMPOSER",
+	"TCON": "GENRE",
+	"TRCK": "TRACKNUMBER",
+	"TYER": "DATE",
+	"TDRC": "DATE",
+	"TCOP": "COPYRIGHT",
+	"TENC": "ENCODED-BY",
+	"TSSE": "ENCODER",
+}
+
+// infoFields maps LIST/INFO items of WAV onto Vorbis comment field names
+var infoFields = map[string]string{
+	"INAM": "TITLE",
+	"IART": "ARTIST",
+	"IPRD": "ALBUM",
+	"ICMT": "COMMENT",
+	"ICRD": "DATE",
+	"IGNR": "GENRE",
+	"ICOP": "COPYRIGHT",
+	"ISFT": "ENCODER",
+	"IPRT": "TRACKNUMBER",
+}
+
+// Probe detects the format of the file by its magic bytes and reads it with the reader of the format. Only the headers
+// of WAV, RF64, AIFF and AU files are read, other formats are scanned frame by frame when their headers do not
+// contain the duration. The reader is rewound to the start afterwards.
+func Probe(r io.ReadSeeker) (*Info, error) {
+	head, offset, err := sniff(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var info *Info
+	switch {
+	case len(head) >= 12 && string(head[0:4]) == "RIFF" && string(head[8:12]) == "WAVE":
+		info, err = probeWav(r, Wav)
+	case len(head) >= 12 && (string(head[0:4]) == "RF64" || string(head[0:4]) == "BW64") && string(head[8:12]) == "WAVE":
+		info, err = probeWav(r, Rf64)
+	case len(head) >= 12 && string(head[0:4]) == "FORM" && (string(head[8:12]) == "AIFF" || string(head[8:12]) == "AIFC"):
+		info, err = probeAiff(r)
+	case bytes.HasPrefix(head, []byte(".snd")):
+		info, err = probeAu(r)
+	case bytes.HasPrefix(head, []byte("OggS")):
+		info, err = probeOgg(r)
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		info, err = probeFlac(r)
+	case bytes.HasPrefix(head, []byte("#!AMR")):
+		info, err = probeAmr(r)
+	case offset > 0 || isMp3(head):
+		info, err = probeMp3(r, offset)
+	default:
+		err = UnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// sniff returns the first bytes of the file after the ID3v2 tag and the size of the tag
+func sniff(r io.ReadSeeker) ([]byte, int64, error) {
+	var offset int64
+	for {
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, 0, err
+		}
+		head := make([]byte, sniffSize)
+		n, err := io.ReadFull(r, head)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			if err == io.EOF {
+				return nil, 0, UnknownFormat
+			}
+			return nil, 0, err
+		}
+		head = head[:n]
+
+		if size := mp3.Id3v2Size(head); size > 0 {
+			offset += int64(size)
+			continue
+		}
+		_, err = r.Seek(0, io.SeekStart)
+		return head, offset, err
+	}
+}
+
+func isMp3(head []byte) bool {
+	_, err := mp3.ParseFrameHeader(head)
+	return err == nil
+}
+
+func probeWav(r io.ReadSeeker, format Format) (*Info, error) {
+	wav, sampleCount, err := audiocodec.ReadWavHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	info := &Info{
+		Format:   format,
+		Codec:    wav.Codec(),
+		Channels: wav.Channels(),
+		Duration: duration(sampleCount, wav.Codec().SampleRate),
+	}
+	for _, item := range wav.Info() {
+		if info.Metadata == nil {
+			info.Metadata = make(map[string]string)
+		}
+		id := item.Id
+		if field, ok := infoFields[id]; ok {
+			id = field
+		}
+		if _, ok := info.Metadata[id]; !ok {
+			info.Metadata[id] = item.Value
+		}
+	}
+	return info, nil
+}
+
+func probeAiff(r io.ReadSeeker) (*Info, error) {
+	aiff, sampleCount, err := audiocodec.ReadAiffHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Info{
+		Format:   Aiff,
+		Codec:    aiff.Codec(),
+		Channels: aiff.Channels(),
+		Duration: duration(sampleCount, aiff.Codec().SampleRate),
+	}, nil
+}
+
+func probeAu(r io.ReadSeeker) (*Info, error) {
+	au, sampleCount, err := audiocodec.ReadAuHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	info := &Info{
+		Format:   Au,
+		Codec:    au.Codec(),
+		Channels: au.Channels(),
+		Duration: duration(sampleCount, au.Codec().SampleRate),
+	}
+	if au.Annotation() != "" {
+		info.Metadata = map[string]string{"COMMENT": au.Annotation()}
+	}
+	return info, nil
+}
+
+func probeFlac(r io.Reader) (*Info, error) {
+	d, err := flac.NewDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Info{
+		Format:   Flac,
+		Codec:    d.Codec(),
+		Channels: d.Channels(),
+		Duration: d.StreamInfo().Duration(),
+		Metadata: commentMetadata(d.Comments()),
+	}, nil
+}
+
+func probeMp3(r io.ReadSeeker, tagSize int64) (*Info, error) {
+	info := &Info{Format: Mp3}
+	if tagSize > 0 {
+		tag := make([]byte, tagSize)
+		if _, err := io.ReadFull(r, tag); err != nil {
+			return nil, err
+		}
+		for id, value := range mp3.ParseId3v2(tag) {
+			if info.Metadata == nil {
+				info.Metadata = make(map[string]string)
+			}
+			if field, ok := id3Fields[id]; ok {
+				id = field
+			}
+			info.Metadata[id] = value
+		}
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+
+	reader := mp3.NewReader(r)
+	if _, err := reader.ReadFrame(); err != nil {
+		if err == io.EOF {
+			return nil, UnknownFormat
+		}
+		return nil, err
+	}
+	if vbr := reader.VbrHeader(); vbr == nil || vbr.Frames == 0 {
+		for {
+			if _, err := reader.ReadFrame(); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return nil, err
+			}
+		}
+	}
+
+	header := reader.FirstHeader()
+	info.Codec = header.Codec()
+	info.Channels = header.Channels()
+	info.Duration = reader.Duration()
+	return info, nil
+}
+
+func probeAmr(r io.Reader) (*Info, error) {
+	reader, err := amr.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, err = reader.ReadFrame(); err != nil {
+			// a truncated last frame is not counted
+			if err == io.EOF || errors.Is(err, amr.TruncatedFrame) {
+				break
+			}
+			return nil, err
+		}
+	}
+	return &Info{
+		Format:   Amr,
+		Codec:    reader.Codec(),
+		Channels: 1,
+		Duration: reader.Position(),
+	}, nil
+}
+
+// commentMetadata joins multiple values of a field with ";"
+func commentMetadata(comments ogg.Comments) map[string]string {
+	if len(comments) == 0 {
+		return nil
+	}
+	metadata := make(map[string]string)
+	for field, values := range comments.Map() {
+		metadata[field] = strings.Join(values, ";")
+	}
+	return metadata
+}
+
+func duration(sampleCount int, sampleRate int) time.Duration {
+	return time.Duration(sampleCount) * time.Second / time.Duration(sampleRate)
+}
@@ -0,0 +1,182 @@
+package probe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/amr"
+	"github.com/URALINNOVATSIYA/audiocodec/flac"
+	"github.com/URALINNOVATSIYA/audiocodec/ogg"
+)
+
+// countingReader counts the bytes read from the file
+type countingReader struct {
+	*bytes.Reader
+	read int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read += n
+	return n, err
+}
+
+func probe(t *testing.T, file []byte) *Info {
+	t.Helper()
+	r := &countingReader{Reader: bytes.NewReader(file)}
+	info, err := Probe(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pos, _ := r.Seek(0, io.SeekCurrent); pos != 0 {
+		t.Errorf("reader left at %d", pos)
+	}
+	return info
+}
+
+func checkInfo(t *testing.T, info *Info, format Format, codec *audiocodec.Codec, channels int, duration time.Duration) {
+	t.Helper()
+	if info.Format != format || !info.Codec.IsEqual(codec) || info.Channels != channels || info.Duration != duration {
+		t.Errorf("info format=%s, codec=%s, channels=%d, duration=%s, want %s, %s, %d, %s",
+			info.Format, info.Codec.Preset(), info.Channels, info.Duration, format, codec.Preset(), channels, duration)
+	}
+}
+
+func TestProbeWav(t *testing.T) {
+	wav := audiocodec.NewWavWithChannels(audiocodec.PcmA8kHz8bCodec, 2)
+	wav.SetInfo(audiocodec.WavInfo{{Id: "INAM", Value: "call"}, {Id: "XUID", Value: "42"}})
+	wav.Write(make([]byte, 2*8000))
+	var file bytes.Buffer
+	wav.WriteTo(&file)
+
+	r := &countingReader{Reader: bytes.NewReader(file.Bytes())}
+	info, err := Probe(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkInfo(t, info, Wav, audiocodec.PcmA8kHz8bCodec, 2, time.Second)
+	if len(info.Metadata) != 2 || info.Metadata["TITLE"] != "call" || info.Metadata["XUID"] != "42" {
+		t.Errorf("metadata = %v", info.Metadata)
+	}
+	// only the headers and the sniffed bytes are read
+	if r.read > file.Len()-2*8000+sniffSize {
+		t.Errorf("%d of %d bytes read", r.read, file.Len())
+	}
+}
+
+func TestProbeRf64(t *testing.T) {
+	file := []byte("BW64\xff\xff\xff\xffWAVEds64")
+	file = binary.LittleEndian.AppendUint32(file, 28)
+	file = binary.LittleEndian.AppendUint64(file, 4+36+24+8+1600)
+	file = binary.LittleEndian.AppendUint64(file, 1600)
+	file = binary.LittleEndian.AppendUint64(file, 800)
+	file = binary.LittleEndian.AppendUint32(file, 0)
+	file = append(file, "fmt \x10\x00\x00\x00\x01\x00\x01\x00\x40\x1f\x00\x00\x80\x3e\x00\x00\x02\x00\x10\x00"...)
+	file = append(file, "data\xff\xff\xff\xff"...)
+	file = append(file, make([]byte, 1600)...)
+
+	checkInfo(t, probe(t, file), Rf64, audiocodec.Pcm8kHz16bCodec, 1, 100*time.Millisecond)
+}
+
+func TestProbeAiff(t *testing.T) {
+	codec := audiocodec.NewPcmCodec(44_100, 16)
+	aiff := audiocodec.NewAiffWithChannels(codec, 2)
+	aiff.Write(make([]byte, 4*441))
+	var file bytes.Buffer
+	aiff.WriteTo(&file)
+
+	checkInfo(t, probe(t, file.Bytes()), Aiff, codec, 2, 10*time.Millisecond)
+}
+
+func TestProbeAu(t *testing.T) {
+	au := audiocodec.NewAu(audiocodec.PcmU8kHz8bCodec)
+	au.SetAnnotation("call")
+	au.Write(make([]byte, 4000))
+	var file bytes.Buffer
+	au.WriteTo(&file)
+
+	info := probe(t, file.Bytes())
+	checkInfo(t, info, Au, audiocodec.PcmU8kHz8bCodec, 1, 500*time.Millisecond)
+	if len(info.Metadata) != 1 || info.Metadata["COMMENT"] != "call" {
+		t.Errorf("metadata = %v", info.Metadata)
+	}
+}
+
+func TestProbeFlac(t *testing.T) {
+	wav := audiocodec.NewWavWithChannels(audiocodec.Pcm8kHz16bCodec, 2)
+	wav.Write(make([]byte, 4*800))
+	file, err := flac.Encode(wav)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkInfo(t, probe(t, file), Flac, audiocodec.Pcm8kHz16bCodec, 2, 100*time.Millisecond)
+}
+
+func TestProbeOpus(t *testing.T) {
+	var file bytes.Buffer
+	tags := &ogg.OpusTags{Vendor: "test", Comments: ogg.Comments{"TITLE=call", "ARTIST=a", "artist=b"}}
+	w, err := ogg.NewOpusWriter(&file, 1, ogg.NewOpusHead(48_000, 2), tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// CELT packets of 20 ms
+	for range 100 {
+		w.WritePacket([]byte{0xf8, 0x00})
+	}
+	w.Close()
+
+	info := probe(t, file.Bytes())
+	checkInfo(t, info, Ogg, audiocodec.Opus48kHzCodec, 2, time.Duration(96_000-ogg.DefaultPreSkip)*time.Second/48_000)
+	if len(info.Metadata) != 2 || info.Metadata["TITLE"] != "call" || info.Metadata["ARTIST"] != "a;b" {
+		t.Errorf("metadata = %v", info.Metadata)
+	}
+}
+
+func TestProbeMp3(t *testing.T) {
+	// ID3v2.3 tag with the title followed by MPEG-1 Layer III frames of 128 kbit/s at 44.1 kHz
+	tag := []byte("ID3\x03\x00\x00\x00\x00\x00\x0fTIT2\x00\x00\x00\x05\x00\x00\x00call")
+	file := tag
+	for range 10 {
+		frame := make([]byte, 417)
+		copy(frame, []byte{0xff, 0xfb, 0x90, 0x00})
+		file = append(file, frame...)
+	}
+
+	info := probe(t, file)
+	checkInfo(t, info, Mp3, audiocodec.NewCodec(audiocodec.Mp3, 44_100, 16), 2, 10*(1152*time.Second/44_100))
+	if len(info.Metadata) != 1 || info.Metadata["TITLE"] != "call" {
+		t.Errorf("metadata = %v", info.Metadata)
+	}
+}
+
+func TestProbeAmr(t *testing.T) {
+	var file bytes.Buffer
+	w, err := amr.NewWriter(&file, amr.Nb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 12.2 kbit/s frames
+	for range 50 {
+		if err = w.WriteFrame(&amr.Frame{Type: 7, Quality: true, Data: make([]byte, 31)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a truncated last frame is not counted
+	file.WriteByte(0x3c)
+
+	checkInfo(t, probe(t, file.Bytes()), Amr, audiocodec.AmrCodec, 1, time.Second)
+}
+
+func TestProbeUnknown(t *testing.T) {
+	for _, file := range [][]byte{nil, []byte("not an audio file")} {
+		if _, err := Probe(bytes.NewReader(file)); !errors.Is(err, UnknownFormat) {
+			t.Errorf("Probe(%q): %v, want %v", file, err, UnknownFormat)
+		}
+	}
+}
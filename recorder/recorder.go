@@ -0,0 +1,232 @@
+package recorder
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/pcm"
+	"github.com/URALINNOVATSIYA/audiocodec/resample"
+)
+
+// Leg is a side of a call, it is also the channel of the stereo recording.
+type Leg int
+
+const (
+	Inbound  Leg = 0 // left channel
+	Outbound Leg = 1 // right channel
+)
+
+// jitterTolerance is the deviation of a frame timestamp from the end of the recorded track at which the frame is
+// still appended right after the track: packet arrival times jitter, and gluing such frames avoids clicks
+const jitterTolerance = 60 * time.Millisecond
+
+type track struct {
+	codec *audiocodec.Codec
+	pcm   *audiocodec.Codec // PCM with the sample rate of the leg the track is stored in
+	data  []byte
+}
+
+// Recorder aligns frames of two call legs on their wall-clock timestamps and builds a stereo (or mixed mono) WAV.
+// Every leg may use its own codec, gaps are filled with silence, both legs are resampled to the codec of the
+// recording once it is built.
+type Recorder struct {
+	mu      *sync.Mutex
+	codec   *audiocodec.Codec
+	factory resample.Factory
+	tracks  [2]*track
+	origin  time.Time
+}
+
+// NewRecorder creates a recorder producing WAV in the codec, the factory is required if a leg has
+// a different sample rate.
+func NewRecorder(codec *audiocodec.Codec, inboundCodec *audiocodec.Codec, outboundCodec *audiocodec.Codec, factory resample.Factory) (*Recorder, error) {
+	r := &Recorder{
+		mu:      &sync.Mutex{},
+		codec:   codec,
+		factory: factory,
+	}
+
+	bitRate := 16
+	if codec.IsPcm() && codec.BitRate == 32 {
+		bitRate = 32
+	}
+	for leg, legCodec := range []*audiocodec.Codec{inboundCodec, outboundCodec} {
+		if legCodec.SampleRate != codec.SampleRate && factory == nil {
+			return nil, fmt.Errorf("%w: %d Hz to %d Hz", resample.ResamplerRequired, legCodec.SampleRate, codec.SampleRate)
+		}
+		r.tracks[leg] = &track{
+			codec: legCodec,
+			pcm:   audiocodec.NewPcmCodec(legCodec.SampleRate, bitRate),
+		}
+	}
+
+	return r, nil
+}
+
+// Write records the frame of the leg, timestamp is the wall-clock time of its first sample.
+func (r *Recorder) Write(leg Leg, timestamp time.Time, frame []byte) error {
+	if leg != Inbound && leg != Outbound {
+		return fmt.Errorf("unknown leg: %d", leg)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := r.tracks[leg]
+	data, err := pcm.Transcode(t.codec, t.pcm, frame)
+	if err != nil {
+		return err
+	}
+
+	if r.origin.IsZero() {
+		r.origin = timestamp
+	}
+	if timestamp.Before(r.origin) {
+		// The frame precedes everything recorded so far, shift both tracks
+		shift := r.origin.Sub(timestamp)
+		for _, other := range r.tracks {
+			if len(other.data) > 0 {
+				other.data = append(make([]byte, other.pcm.Size(shift)), other.data...)
+			}
+		}
+		r.origin = timestamp
+	}
+
+	position := t.pcm.Size(timestamp.Sub(r.origin))
+	tolerance := t.pcm.Size(jitterTolerance)
+	switch {
+	case len(t.data) == 0 || position > len(t.data)+tolerance:
+		// The first frame of the leg is never glued, otherwise the legs would be misaligned
+		t.data = append(t.data, make([]byte, position-len(t.data))...)
+		t.data = append(t.data, data...)
+	case position < len(t.data)-tolerance:
+		end := position + len(data)
+		if end > len(t.data) {
+			t.data = append(t.data, make([]byte, end-len(t.data))...)
+		}
+		copy(t.data[position:end], data)
+	default:
+		t.data = append(t.data, data...)
+	}
+
+	return nil
+}
+
+// Duration returns the length of the recording.
+func (r *Recorder) Duration() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var duration time.Duration
+	for _, t := range r.tracks {
+		if d := t.pcm.Duration(len(t.data)); d > duration {
+			duration = d
+		}
+	}
+	return duration
+}
+
+// Wav builds the stereo recording: the inbound leg is the left channel, the outbound leg is the right one.
+func (r *Recorder) Wav() (*audiocodec.Wav, error) {
+	channels, err := r.render()
+	if err != nil {
+		return nil, err
+	}
+
+	sampleSize := r.codec.SampleSize()
+	data := make([]byte, 0, len(channels[0])*2)
+	for i := 0; i < len(channels[0]); i += sampleSize {
+		data = append(data, channels[0][i:i+sampleSize]...)
+		data = append(data, channels[1][i:i+sampleSize]...)
+	}
+
+	wav := audiocodec.NewWavWithChannels(r.codec, 2)
+	if _, err = wav.Write(data); err != nil {
+		return nil, err
+	}
+	return wav, nil
+}
+
+// MonoWav builds the recording with both legs mixed into a single channel.
+func (r *Recorder) MonoWav() (*audiocodec.Wav, error) {
+	channels, err := r.render()
+	if err != nil {
+		return nil, err
+	}
+
+	left, err := pcm.Decode(r.codec, nil, channels[0])
+	if err != nil {
+		return nil, err
+	}
+	right, err := pcm.Decode(r.codec, nil, channels[1])
+	if err != nil {
+		return nil, err
+	}
+	for i := range left {
+		left[i] += right[i]
+	}
+
+	data, err := pcm.Encode(r.codec, nil, left)
+	if err != nil {
+		return nil, err
+	}
+
+	wav := audiocodec.NewWav(r.codec)
+	if _, err = wav.Write(data); err != nil {
+		return nil, err
+	}
+	return wav, nil
+}
+
+func (r *Recorder) Codec() *audiocodec.Codec {
+	return r.codec
+}
+
+// render converts both tracks to the codec of the recording and pads them to the same length
+func (r *Recorder) render() ([2][]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var channels [2][]byte
+	for leg, t := range r.tracks {
+		converter, err := resample.NewConverter(t.pcm, r.codec, r.factory)
+		if err != nil {
+			return channels, err
+		}
+
+		data, err := converter.Convert(t.data)
+		if err == nil {
+			var tail []byte
+			if tail, err = converter.Flush(); err == nil {
+				data = append(data[:len(data):len(data)], tail...)
+			}
+		}
+		if freeErr := converter.Free(); err == nil {
+			err = freeErr
+		}
+		if err != nil {
+			return channels, err
+		}
+
+		// The resampler may produce a few samples more or less than the exact duration
+		if size := r.codec.Size(t.pcm.Duration(len(t.data))); len(data) > size && size > 0 {
+			data = data[:size]
+		}
+		channels[leg] = data
+	}
+
+	silence, err := pcm.Encode(r.codec, nil, []float32{0})
+	if err != nil {
+		return channels, err
+	}
+	length := max(len(channels[0]), len(channels[1]))
+	for leg := range channels {
+		for len(channels[leg]) < length {
+			channels[leg] = append(channels[leg], silence...)
+		}
+	}
+
+	return channels, nil
+}
@@ -0,0 +1,148 @@
+package recorder
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/g711"
+	"github.com/URALINNOVATSIYA/audiocodec/resample"
+)
+
+const frameDuration = 20 * time.Millisecond
+
+var origin = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// frame returns 16-bit PCM where every sample has the value
+func frame(codec *audiocodec.Codec, value int16) []byte {
+	b := make([]byte, codec.Size(frameDuration))
+	for i := 0; i < len(b); i += 2 {
+		b[i], b[i+1] = byte(value), byte(value>>8)
+	}
+	return b
+}
+
+// samples returns the 16-bit samples of the channel
+func samples(data []byte, channel int, channels int) []int16 {
+	var s []int16
+	for i := channel * 2; i < len(data); i += 2 * channels {
+		s = append(s, int16(data[i])|int16(data[i+1])<<8)
+	}
+	return s
+}
+
+// checkTrack checks that the track consists of frames of the values
+func checkTrack(t *testing.T, name string, track []int16, values ...int16) {
+	t.Helper()
+	frameSize := audiocodec.Pcm8kHz16bCodec.SampleCountByDuration(frameDuration)
+	if len(track) != len(values)*frameSize {
+		t.Errorf("%s: %d samples, want %d", name, len(track), len(values)*frameSize)
+		return
+	}
+	for i, s := range track {
+		if want := values[i/frameSize]; s != want {
+			t.Errorf("%s: sample %d = %d, want %d", name, i, s, want)
+			return
+		}
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	codec := audiocodec.Pcm8kHz16bCodec
+	r, err := NewRecorder(codec, codec, codec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writes := []struct {
+		leg    Leg
+		offset time.Duration
+		value  int16
+	}{
+		{Inbound, 0, 100},
+		// the first frame of the other leg is placed at its time even within the jitter tolerance
+		{Outbound, 20 * time.Millisecond, 200},
+		// jitter below the tolerance, appended right after the previous frame
+		{Inbound, 30 * time.Millisecond, 101},
+		// a gap filled with silence
+		{Inbound, 140 * time.Millisecond, 102},
+		// a late frame overwrites the silence
+		{Inbound, 60 * time.Millisecond, 103},
+		// a frame before the first one shifts both tracks
+		{Outbound, -100 * time.Millisecond, 201},
+	}
+	for _, w := range writes {
+		if err = r.Write(w.leg, origin.Add(w.offset), frame(codec, w.value)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = r.Write(Leg(2), origin, frame(codec, 0)); err == nil {
+		t.Error("unknown leg is accepted")
+	}
+	if d := r.Duration(); d != 260*time.Millisecond {
+		t.Errorf("duration = %v, want 260ms", d)
+	}
+
+	wav, err := r.Wav()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wav.Channels() != 2 || !wav.Codec().IsEqual(codec) {
+		t.Fatalf("%d channels of %v", wav.Channels(), wav.Codec())
+	}
+	checkTrack(t, "inbound", samples(wav.Data(), 0, 2), 0, 0, 0, 0, 0, 100, 101, 0, 103, 0, 0, 0, 102)
+	checkTrack(t, "outbound", samples(wav.Data(), 1, 2), 201, 0, 0, 0, 0, 0, 200, 0, 0, 0, 0, 0, 0)
+
+	mono, err := r.MonoWav()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mono.Channels() != 1 {
+		t.Fatalf("%d channels", mono.Channels())
+	}
+	checkTrack(t, "mono", samples(mono.Data(), 0, 1), 201, 0, 0, 0, 0, 100, 301, 0, 103, 0, 0, 0, 102)
+
+	// the stereo recording survives writing and parsing
+	var b bytes.Buffer
+	if _, err = wav.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := audiocodec.NewWavFromBytes(b.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Channels() != 2 || !bytes.Equal(parsed.Data(), wav.Data()) {
+		t.Error("parsed recording differs")
+	}
+}
+
+func TestRecorderCodecs(t *testing.T) {
+	// G.711 legs are decoded exactly as G.711
+	pcm := frame(audiocodec.Pcm8kHz16bCodec, 1000)
+	alaw := g711.EncodeAlawFrame(nil, pcm)
+	ulaw := g711.EncodeUlawFrame(nil, pcm)
+	r, err := NewRecorder(audiocodec.Pcm8kHz16bCodec, audiocodec.PcmA8kHz8bCodec, audiocodec.PcmU8kHz8bCodec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Write(Inbound, origin, alaw); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Write(Outbound, origin, ulaw); err != nil {
+		t.Fatal(err)
+	}
+
+	wav, err := r.Wav()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inbound, outbound := samples(g711.DecodeAlawFrame(nil, alaw), 0, 1), samples(g711.DecodeUlawFrame(nil, ulaw), 0, 1)
+	checkTrack(t, "inbound", samples(wav.Data(), 0, 2), inbound[0])
+	checkTrack(t, "outbound", samples(wav.Data(), 1, 2), outbound[0])
+
+	if _, err = NewRecorder(audiocodec.Pcm8kHz16bCodec, audiocodec.Pcm16kHz16bCodec, audiocodec.Pcm8kHz16bCodec, nil); !errors.Is(err, resample.ResamplerRequired) {
+		t.Errorf("NewRecorder = %v, want %v", err, resample.ResamplerRequired)
+	}
+}
@@ -0,0 +1,90 @@
+package resample
+
+import (
+	"fmt"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/pcm"
+)
+
+// Converter converts frames between any codecs supported by the pcm package: the sample format is transcoded
+// directly and the sample rate is changed by a resampler created with the factory.
+type Converter struct {
+	incomingCodec *audiocodec.Codec
+	outgoingCodec *audiocodec.Codec
+	// incomingPcm and outgoingPcm are the codecs the resampler works with
+	incomingPcm *audiocodec.Codec
+	outgoingPcm *audiocodec.Codec
+	resampler   Resampler
+}
+
+// NewConverter creates a converter, the factory may be nil if the codecs have the same sample rate.
+func NewConverter(incomingCodec *audiocodec.Codec, outgoingCodec *audiocodec.Codec, factory Factory) (*Converter, error) {
+	c := &Converter{
+		incomingCodec: incomingCodec,
+		outgoingCodec: outgoingCodec,
+	}
+	if incomingCodec.SampleRate == outgoingCodec.SampleRate {
+		return c, nil
+	}
+	if factory == nil {
+		return nil, fmt.Errorf("%w: %d Hz to %d Hz", ResamplerRequired, incomingCodec.SampleRate, outgoingCodec.SampleRate)
+	}
+
+	bitRate := 16
+	if outgoingCodec.IsPcm() && outgoingCodec.BitRate == 32 {
+		bitRate = 32
+	}
+	c.incomingPcm = audiocodec.NewPcmCodec(incomingCodec.SampleRate, bitRate)
+	c.outgoingPcm = audiocodec.NewPcmCodec(outgoingCodec.SampleRate, bitRate)
+
+	var err error
+	if c.resampler, err = factory(c.incomingPcm, c.outgoingPcm); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *Converter) Convert(frame []byte) ([]byte, error) {
+	if c.resampler == nil {
+		return pcm.Transcode(c.incomingCodec, c.outgoingCodec, frame)
+	}
+
+	frame, err := pcm.Transcode(c.incomingCodec, c.incomingPcm, frame)
+	if err != nil {
+		return nil, err
+	}
+	if frame, err = c.resampler.Resample(frame); err != nil {
+		return nil, err
+	}
+	return pcm.Transcode(c.outgoingPcm, c.outgoingCodec, frame)
+}
+
+// Flush returns the data remaining in the resampler at the end of the stream.
+func (c *Converter) Flush() ([]byte, error) {
+	if c.resampler == nil {
+		return nil, nil
+	}
+
+	frame, err := c.resampler.Flush()
+	if err != nil {
+		return nil, err
+	}
+	return pcm.Transcode(c.outgoingPcm, c.outgoingCodec, frame)
+}
+
+func (c *Converter) Free() error {
+	if c.resampler == nil {
+		return nil
+	}
+	return Free(c.resampler)
+}
+
+func (c *Converter) IncomingCodec() *audiocodec.Codec {
+	return c.incomingCodec
+}
+
+func (c *Converter) OutgoingCodec() *audiocodec.Codec {
+	return c.outgoingCodec
+}
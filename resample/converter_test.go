@@ -0,0 +1,88 @@
+package resample
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/g711"
+)
+
+// nearest is a resampler picking the nearest preceding sample, it is exact for integer ratios
+type nearest struct {
+	incoming, outgoing *audiocodec.Codec
+	freed              bool
+}
+
+func (r *nearest) Resample(incomingData []byte) ([]byte, error) {
+	size := r.incoming.SampleSize()
+	count := len(incomingData) / size * r.outgoing.SampleRate / r.incoming.SampleRate
+	out := make([]byte, 0, count*size)
+	for i := 0; i < count; i++ {
+		j := i * r.incoming.SampleRate / r.outgoing.SampleRate * size
+		out = append(out, incomingData[j:j+size]...)
+	}
+	return out, nil
+}
+
+func (r *nearest) Flush() ([]byte, error) {
+	return nil, nil
+}
+
+func (r *nearest) Free() {
+	r.freed = true
+}
+
+func TestConverter(t *testing.T) {
+	pcm := []byte{0x00, 0x10, 0x00, 0xf0, 0x34, 0x12, 0xcc, 0xed}
+	alaw := g711.EncodeAlawFrame(nil, pcm)
+
+	// the same sample rate needs no resampler
+	c, err := NewConverter(audiocodec.Pcm8kHz16bCodec, audiocodec.PcmA8kHz8bCodec, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if converted, err := c.Convert(pcm); err != nil || !bytes.Equal(converted, alaw) {
+		t.Errorf("converted % x, %v, want % x", converted, err, alaw)
+	}
+	if flushed, err := c.Flush(); err != nil || flushed != nil {
+		t.Errorf("flushed % x, %v", flushed, err)
+	}
+
+	if _, err = NewConverter(audiocodec.PcmA8kHz8bCodec, audiocodec.Pcm16kHz16bCodec, nil); !errors.Is(err, ResamplerRequired) {
+		t.Errorf("NewConverter = %v, want %v", err, ResamplerRequired)
+	}
+}
+
+func TestConverterResampler(t *testing.T) {
+	var r *nearest
+	factory := func(incomingCodec *audiocodec.Codec, outgoingCodec *audiocodec.Codec) (Resampler, error) {
+		r = &nearest{incoming: incomingCodec, outgoing: outgoingCodec}
+		return r, nil
+	}
+
+	// G.711 is resampled as 16-bit PCM
+	c, err := NewConverter(audiocodec.PcmA8kHz8bCodec, audiocodec.Pcm16kHz16bCodec, factory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.incoming.IsEqual(audiocodec.Pcm8kHz16bCodec) || !r.outgoing.IsEqual(audiocodec.Pcm16kHz16bCodec) {
+		t.Errorf("resampler codecs %v, %v", r.incoming, r.outgoing)
+	}
+
+	alaw := []byte{0xd5, 0x2a, 0x55, 0xaa}
+	decoded := g711.DecodeAlawFrame(nil, alaw)
+	var want []byte
+	for i := 0; i < len(decoded); i += 2 {
+		want = append(want, decoded[i:i+2]...)
+		want = append(want, decoded[i:i+2]...)
+	}
+	if converted, err := c.Convert(alaw); err != nil || !bytes.Equal(converted, want) {
+		t.Errorf("converted % x, %v, want % x", converted, err, want)
+	}
+
+	if err = c.Free(); err != nil || !r.freed {
+		t.Errorf("Free = %v, freed %v", err, r.freed)
+	}
+}
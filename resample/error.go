@@ -0,0 +1,5 @@
+package resample
+
+import "errors"
+
+var ResamplerRequired = errors.New("resampler factory is required to convert the sample rate")
@@ -0,0 +1,27 @@
+package resample
+
+import "github.com/URALINNOVATSIYA/audiocodec"
+
+// Resampler is the streaming interface shared by the soxr and libswresample resamplers.
+type Resampler interface {
+	Resample(incomingData []byte) ([]byte, error)
+	Flush() ([]byte, error)
+}
+
+// Factory creates a resampler between two PCM codecs with different sample rates, e.g.
+//
+//	func(in, out *audiocodec.Codec) (resample.Resampler, error) {
+//		return soxr.NewResampler(in, out, soxr.HighQuality)
+//	}
+type Factory func(incomingCodec *audiocodec.Codec, outgoingCodec *audiocodec.Codec) (Resampler, error)
+
+// Free releases the native resources of the resampler if it holds any.
+func Free(r Resampler) error {
+	switch r := r.(type) {
+	case interface{ Free() error }:
+		return r.Free()
+	case interface{ Free() }:
+		r.Free()
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package speex
+
+/*
+#cgo pkg-config: speex
+
+#include <speex/speex.h>
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+var CorruptedPacket = errors.New("corrupted Speex packet")
+
+// minFrameBits is the size of the shortest frame header, fewer remaining bits are padding
+const minFrameBits = 5
+
+// Decoder decompresses Speex packets into mono 16-bit PCM of the band sample rate.
+type Decoder struct {
+	state unsafe.Pointer
+	bits  C.SpeexBits
+
+	band    Band
+	samples []C.spx_int16_t
+}
+
+// NewDecoder creates a decoder of the band with the perceptual enhancement enabled.
+func NewDecoder(band Band) (*Decoder, error) {
+	mode, err := band.mode()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Decoder{
+		state: C.speex_decoder_init(mode),
+		band:  band,
+	}
+	if d.state == nil {
+		return nil, errors.New("failed to create Speex decoder")
+	}
+	C.speex_bits_init(&d.bits)
+
+	var frameSize C.int
+	C.speex_decoder_ctl(d.state, C.SPEEX_GET_FRAME_SIZE, unsafe.Pointer(&frameSize))
+	d.samples = make([]C.spx_int16_t, int(frameSize))
+
+	enhancement := C.int(1)
+	C.speex_decoder_ctl(d.state, C.SPEEX_SET_ENH, unsafe.Pointer(&enhancement))
+
+	return d, nil
+}
+
+// Decode returns PCM data of all frames of the packet.
+func (d *Decoder) Decode(packet []byte) ([]byte, error) {
+	if len(packet) == 0 {
+		return nil, nil
+	}
+	C.speex_bits_read_from(&d.bits, (*C.char)(unsafe.Pointer(&packet[0])), C.int(len(packet)))
+
+	var pcm []byte
+	for C.speex_bits_remaining(&d.bits) >= minFrameBits {
+		switch C.speex_decode_int(d.state, &d.bits, &d.samples[0]) {
+		case 0:
+			pcm = d.appendSamples(pcm)
+		case -1:
+			// the terminator of the packet
+			return pcm, nil
+		default:
+			return nil, CorruptedPacket
+		}
+	}
+	return pcm, nil
+}
+
+// Conceal synthesizes the audio of a lost frame.
+func (d *Decoder) Conceal() []byte {
+	C.speex_decode_int(d.state, nil, &d.samples[0])
+	return d.appendSamples(nil)
+}
+
+func (d *Decoder) appendSamples(pcm []byte) []byte {
+	for _, sample := range d.samples {
+		pcm = append(pcm, byte(sample), byte(uint16(sample)>>8))
+	}
+	return pcm
+}
+
+func (d *Decoder) Free() {
+	if d.state != nil {
+		C.speex_decoder_destroy(d.state)
+		C.speex_bits_destroy(&d.bits)
+		d.state = nil
+	}
+}
+
+// Codec returns the codec of the decoded stream.
+func (d *Decoder) Codec() *audiocodec.Codec {
+	return d.band.Codec()
+}
+
+// PcmCodec returns the codec of the produced audio, it can be passed to the resamplers.
+func (d *Decoder) PcmCodec() *audiocodec.Codec {
+	return d.band.PcmCodec()
+}
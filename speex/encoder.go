@@ -0,0 +1,185 @@
+package speex
+
+/*
+#cgo pkg-config: speex
+
+#include <speex/speex.h>
+
+static int encoder_set_vbr_quality(void *state, float quality) {
+	return speex_encoder_ctl(state, SPEEX_SET_VBR_QUALITY, &quality);
+}
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+type EncoderOptions struct {
+	// Quality in range 0-10
+	Quality int
+	// Complexity in range 1-10
+	Complexity int
+	// Vbr enables variable bit rate, Quality is used as the VBR quality then
+	Vbr bool
+	// Vad detects silence, Dtx stops transmitting it: packets of silence are as small as a byte
+	Vad bool
+	Dtx bool
+	// FramesPerPacket is the number of 20 ms frames in a packet
+	FramesPerPacket int
+}
+
+func DefaultEncoderOptions() EncoderOptions {
+	return EncoderOptions{
+		Quality:         8,
+		Complexity:      3,
+		FramesPerPacket: 1,
+	}
+}
+
+// Encoder compresses mono 16-bit PCM of the band sample rate into Speex packets.
+type Encoder struct {
+	state unsafe.Pointer
+	bits  C.SpeexBits
+
+	band            Band
+	frameSize       int // samples per frame
+	framesPerPacket int
+	frames          int // frames in the current packet
+	buffer          []byte
+	samples         []C.spx_int16_t
+	packet          []byte
+}
+
+func NewEncoder(band Band, options EncoderOptions) (*Encoder, error) {
+	mode, err := band.mode()
+	if err != nil {
+		return nil, err
+	}
+	if options.FramesPerPacket < 1 {
+		return nil, errors.New("speex packet must contain at least one frame")
+	}
+
+	e := &Encoder{
+		state:           C.speex_encoder_init(mode),
+		band:            band,
+		framesPerPacket: options.FramesPerPacket,
+		packet:          make([]byte, maxPacketSize),
+	}
+	if e.state == nil {
+		return nil, errors.New("failed to create Speex encoder")
+	}
+	C.speex_bits_init(&e.bits)
+
+	var frameSize C.int
+	C.speex_encoder_ctl(e.state, C.SPEEX_GET_FRAME_SIZE, unsafe.Pointer(&frameSize))
+	e.frameSize = int(frameSize)
+	e.samples = make([]C.spx_int16_t, e.frameSize)
+
+	settings := []struct {
+		request C.int
+		value   int
+	}{
+		{C.SPEEX_SET_VBR, boolValue(options.Vbr)},
+		{C.SPEEX_SET_QUALITY, options.Quality},
+		{C.SPEEX_SET_COMPLEXITY, options.Complexity},
+		{C.SPEEX_SET_VAD, boolValue(options.Vad)},
+		{C.SPEEX_SET_DTX, boolValue(options.Dtx)},
+	}
+	for _, setting := range settings {
+		value := C.int(setting.value)
+		if errCode := C.speex_encoder_ctl(e.state, setting.request, unsafe.Pointer(&value)); errCode != 0 {
+			e.Free()
+			return nil, fmt.Errorf("speex encoder ctl error: request %d, code %d", int(setting.request), int(errCode))
+		}
+	}
+	if options.Vbr {
+		C.encoder_set_vbr_quality(e.state, C.float(options.Quality))
+	}
+
+	return e, nil
+}
+
+// Encode buffers PCM data and returns the packets of all completed frames.
+func (e *Encoder) Encode(data []byte) [][]byte {
+	e.buffer = append(e.buffer, data...)
+
+	frameBytes := e.PcmCodec().SizeBySampleCount(e.frameSize)
+	var packets [][]byte
+	for len(e.buffer) >= frameBytes {
+		if packet := e.encode(e.buffer[:frameBytes]); packet != nil {
+			packets = append(packets, packet)
+		}
+		e.buffer = e.buffer[frameBytes:]
+	}
+	e.buffer = append(e.buffer[:0:0], e.buffer...)
+
+	return packets
+}
+
+// Flush encodes the buffered incomplete frame padded with silence and returns the last incomplete packet.
+func (e *Encoder) Flush() []byte {
+	if len(e.buffer) > 0 {
+		frame := make([]byte, e.PcmCodec().SizeBySampleCount(e.frameSize))
+		copy(frame, e.buffer)
+		e.buffer = e.buffer[:0]
+		if packet := e.encode(frame); packet != nil {
+			return packet
+		}
+	}
+	if e.frames == 0 {
+		return nil
+	}
+	return e.writePacket()
+}
+
+// encode adds the frame to the current packet and returns the packet once it is complete
+func (e *Encoder) encode(frame []byte) []byte {
+	for i := range e.samples {
+		e.samples[i] = C.spx_int16_t(int16(frame[2*i]) | int16(frame[2*i+1])<<8)
+	}
+	C.speex_encode_int(e.state, &e.samples[0], &e.bits)
+
+	if e.frames++; e.frames < e.framesPerPacket {
+		return nil
+	}
+	return e.writePacket()
+}
+
+func (e *Encoder) writePacket() []byte {
+	C.speex_bits_insert_terminator(&e.bits)
+	n := C.speex_bits_write(&e.bits, (*C.char)(unsafe.Pointer(&e.packet[0])), C.int(len(e.packet)))
+	C.speex_bits_reset(&e.bits)
+	e.frames = 0
+
+	packet := make([]byte, int(n))
+	copy(packet, e.packet)
+	return packet
+}
+
+func (e *Encoder) Free() {
+	if e.state != nil {
+		C.speex_encoder_destroy(e.state)
+		C.speex_bits_destroy(&e.bits)
+		e.state = nil
+	}
+}
+
+// FrameDuration returns the duration of audio in every packet.
+func (e *Encoder) FrameDuration() time.Duration {
+	return time.Duration(e.framesPerPacket) * FrameDuration
+}
+
+// Codec returns the codec of the produced stream.
+func (e *Encoder) Codec() *audiocodec.Codec {
+	return e.band.Codec()
+}
+
+// PcmCodec returns the codec of the encoded audio.
+func (e *Encoder) PcmCodec() *audiocodec.Codec {
+	return e.band.PcmCodec()
+}
@@ -0,0 +1,63 @@
+package speex
+
+/*
+#cgo pkg-config: speex
+
+#include <speex/speex.h>
+*/
+import "C"
+import (
+	"fmt"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+// FrameDuration is the duration of a Speex frame in every band
+const FrameDuration = 20 * time.Millisecond
+
+// maxPacketSize is enough for several frames of the highest quality
+const maxPacketSize = 2000
+
+type Band C.int
+
+// Narrowband - 8 kHz, Wideband - 16 kHz, UltraWideband - 32 kHz.
+const (
+	Narrowband    Band = C.SPEEX_MODEID_NB
+	Wideband      Band = C.SPEEX_MODEID_WB
+	UltraWideband Band = C.SPEEX_MODEID_UWB
+)
+
+// Codec describes a Speex stream of the band, BitRate is the sample size of the decoded PCM.
+func (b Band) Codec() *audiocodec.Codec {
+	return audiocodec.NewCodec(audiocodec.Speex, b.sampleRate(), 16)
+}
+
+// PcmCodec returns the codec of the decoded samples.
+func (b Band) PcmCodec() *audiocodec.Codec {
+	return audiocodec.NewPcmCodec(b.sampleRate(), 16)
+}
+
+func (b Band) sampleRate() int {
+	switch b {
+	case Wideband:
+		return 16_000
+	case UltraWideband:
+		return 32_000
+	}
+	return 8_000
+}
+
+func (b Band) mode() (*C.SpeexMode, error) {
+	if b != Narrowband && b != Wideband && b != UltraWideband {
+		return nil, fmt.Errorf("not supported Speex band: %d", int(b))
+	}
+	return C.speex_lib_get_mode(C.int(b)), nil
+}
+
+func boolValue(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
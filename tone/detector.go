@@ -0,0 +1,381 @@
+package tone
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/pcm"
+)
+
+const (
+	blockDuration = 20 * time.Millisecond
+	// debounceBlocks is how many consecutive blocks must agree before the detector accepts a change of the tone
+	debounceBlocks = 2
+	maxRuns        = 16
+
+	// minBlockPower is the mean square of a block below which it is considered silent (-45 dBFS)
+	minBlockPower = 3.1622776601683795e-05
+	// minToneShare is the part of the block energy the frequencies of a tone have to take together
+	minToneShare = 0.7
+	// minComponentShare is the part of the block energy every frequency of a tone has to take (divided by the number
+	// of frequencies)
+	minComponentShare = 0.3
+)
+
+// Detection reports a recognized signal. Every detection is reported twice: as soon as the signal is recognized
+// and once it stops (Final is set).
+type Detection struct {
+	Signal   *Signal
+	Start    time.Duration // offset from the first processed sample
+	Duration time.Duration
+	Final    bool
+}
+
+// run is a period of time where the same tone (or no tone) is heard
+type run struct {
+	label    int
+	start    time.Duration
+	duration time.Duration
+}
+
+func (r run) end() time.Duration {
+	return r.start + r.duration
+}
+
+type tracker struct {
+	signal *Signal
+	labels []int // tone label of every segment
+	active bool
+	phase  int // segment of the current run
+	start  time.Duration
+}
+
+// Detector classifies call progress tones, special information tones and fax tones in PCM or G.711 frames.
+// Frames of any length may be passed, they are analyzed in 20 ms blocks with the Goertzel algorithm.
+type Detector struct {
+	codec      *audiocodec.Codec
+	plan       *Plan
+	decoder    func(sample []byte) float32
+	sampleSize int
+
+	block         []float64
+	blockSize     int
+	blockDuration time.Duration
+	position      time.Duration
+
+	coefficients []float64 // Goertzel coefficient per frequency
+	tones        [][]int   // frequencies (indexes of coefficients) of every distinct tone
+	trackers     []*tracker
+
+	runs              []run
+	current           run
+	candidate         int
+	candidateDuration time.Duration
+}
+
+func NewDetector(codec *audiocodec.Codec, plan *Plan) (*Detector, error) {
+	decoder, err := pcm.Decoder(codec)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Detector{
+		codec:      codec,
+		plan:       plan,
+		decoder:    decoder,
+		sampleSize: codec.SampleSize(),
+		blockSize:  codec.SampleCountByDuration(blockDuration),
+	}
+
+	d.block = make([]float64, 0, d.blockSize)
+	d.blockDuration = time.Duration(d.blockSize) * time.Second / time.Duration(codec.SampleRate)
+
+	frequencies := make(map[float64]int)
+	toneLabels := make(map[string]int)
+	for _, signal := range plan.Signals {
+		t := &tracker{
+			signal: signal,
+			labels: make([]int, len(signal.Segments)),
+		}
+		for i, seg := range signal.Segments {
+			if seg.IsSilence() {
+				t.labels[i] = -1
+				continue
+			}
+
+			key := fmt.Sprint(seg.Frequencies)
+			label, exists := toneLabels[key]
+			if !exists {
+				tone := make([]int, len(seg.Frequencies))
+				for j, f := range seg.Frequencies {
+					if f <= 0 || f >= float64(codec.SampleRate)/2 {
+						return nil, fmt.Errorf("frequency %g Hz of signal \"%s\" is out of range", f, signal.Name)
+					}
+					index, exists := frequencies[f]
+					if !exists {
+						index = len(d.coefficients)
+						frequencies[f] = index
+						d.coefficients = append(d.coefficients, 2*math.Cos(2*math.Pi*f/float64(codec.SampleRate)))
+					}
+					tone[j] = index
+				}
+				label = len(d.tones)
+				toneLabels[key] = label
+				d.tones = append(d.tones, tone)
+			}
+			t.labels[i] = label
+		}
+		d.trackers = append(d.trackers, t)
+	}
+
+	d.Reset()
+
+	return d, nil
+}
+
+// Process analyzes the frame and returns signals recognized or finished within it.
+func (d *Detector) Process(frame []byte) ([]Detection, error) {
+	if len(frame)%d.sampleSize != 0 {
+		return nil, fmt.Errorf("frame size %d is not a multiple of sample size %d", len(frame), d.sampleSize)
+	}
+
+	var detections []Detection
+	for i := 0; i < len(frame); i += d.sampleSize {
+		d.block = append(d.block, float64(d.decoder(frame[i:i+d.sampleSize])))
+		if len(d.block) == d.blockSize {
+			detections = d.processBlock(detections)
+			d.block = d.block[:0]
+		}
+	}
+
+	return detections, nil
+}
+
+// Flush finishes all signals that are still in progress.
+func (d *Detector) Flush() []Detection {
+	var detections []Detection
+	for _, t := range d.trackers {
+		if t.active {
+			detections = d.finish(detections, t, d.position)
+		}
+	}
+	return detections
+}
+
+func (d *Detector) Reset() {
+	d.block = d.block[:0]
+	d.position = 0
+	d.runs = d.runs[:0]
+	d.current = run{label: -1}
+	d.candidate = -1
+	d.candidateDuration = 0
+	for _, t := range d.trackers {
+		t.active = false
+	}
+}
+
+func (d *Detector) Codec() *audiocodec.Codec {
+	return d.codec
+}
+
+func (d *Detector) Plan() *Plan {
+	return d.plan
+}
+
+func (d *Detector) processBlock(detections []Detection) []Detection {
+	label := d.classify()
+	d.position += d.blockDuration
+
+	switch {
+	case label == d.current.label:
+		d.current.duration += d.candidateDuration + d.blockDuration
+		d.candidateDuration = 0
+	case d.candidateDuration > 0 && label == d.candidate:
+		d.candidateDuration += d.blockDuration
+		if d.candidateDuration >= debounceBlocks*d.blockDuration {
+			completed := d.current
+			d.current = run{label: label, start: completed.end(), duration: d.candidateDuration}
+			d.candidateDuration = 0
+			if len(d.runs) == maxRuns {
+				copy(d.runs, d.runs[1:])
+				d.runs = d.runs[:maxRuns-1]
+			}
+			d.runs = append(d.runs, completed)
+			for _, t := range d.trackers {
+				if t.active {
+					detections = d.complete(detections, t, completed)
+				}
+			}
+		}
+	default:
+		d.current.duration += d.candidateDuration
+		d.candidate = label
+		d.candidateDuration = d.blockDuration
+	}
+
+	for _, t := range d.trackers {
+		if t.active {
+			detections = d.check(detections, t)
+		} else {
+			detections = d.match(detections, t)
+		}
+	}
+
+	return detections
+}
+
+// classify returns the label of the tone heard in the block or -1 if there is silence or something else
+func (d *Detector) classify() int {
+	var energy float64
+	for _, x := range d.block {
+		energy += x * x
+	}
+	if energy/float64(len(d.block)) < minBlockPower {
+		return -1
+	}
+
+	shares := make([]float64, len(d.coefficients))
+	for i, coefficient := range d.coefficients {
+		var s1, s2 float64
+		for _, x := range d.block {
+			s1, s2 = x+coefficient*s1-s2, s1
+		}
+		power := s1*s1 + s2*s2 - coefficient*s1*s2
+		shares[i] = 2 * power / (float64(len(d.block)) * energy)
+	}
+
+	label := -1
+	var best float64
+	for l, tone := range d.tones {
+		var total float64
+		matched := true
+		for _, f := range tone {
+			if shares[f] < minComponentShare/float64(len(tone)) {
+				matched = false
+				break
+			}
+			total += shares[f]
+		}
+		if matched && total >= minToneShare && total > best {
+			label = l
+			best = total
+		}
+	}
+
+	return label
+}
+
+// match looks for the cadence of the inactive signal in the latest runs
+func (d *Detector) match(detections []Detection, t *tracker) []Detection {
+	if t.signal.IsContinuous() {
+		if d.current.label == t.labels[0] && d.current.duration >= t.signal.MinDuration {
+			return d.activate(detections, t, 0, d.current.start)
+		}
+		return detections
+	}
+
+	segmentCount := len(t.signal.Segments)
+	window := segmentCount
+	rotations := 1
+	if t.signal.Repeat {
+		window++
+		rotations = segmentCount
+	}
+	if len(d.runs) < window-1 {
+		return detections
+	}
+	runs := d.runs[len(d.runs)-window+1:]
+
+	for r := 0; r < rotations; r++ {
+		matched := true
+		for k, completed := range runs {
+			if !d.fits(t, (r+k)%segmentCount, completed) {
+				matched = false
+				break
+			}
+		}
+		if matched && d.fits(t, (r+window-1)%segmentCount, d.current) {
+			start := d.current.start
+			if len(runs) > 0 {
+				start = runs[0].start
+			}
+			return d.activate(detections, t, (r+window-1)%segmentCount, start)
+		}
+	}
+
+	return detections
+}
+
+// check finishes the active signal if the current run lasts longer than its segment
+func (d *Detector) check(detections []Detection, t *tracker) []Detection {
+	if t.signal.IsContinuous() {
+		return detections
+	}
+
+	if d.current.duration > d.maxDuration(t.signal.Segments[t.phase].Duration) {
+		return d.finish(detections, t, d.current.start)
+	}
+	return detections
+}
+
+// complete moves the active signal to the next segment of the cadence once a run is completed
+func (d *Detector) complete(detections []Detection, t *tracker, completed run) []Detection {
+	if t.signal.IsContinuous() || !d.fits(t, t.phase, completed) {
+		return d.finish(detections, t, completed.start)
+	}
+
+	t.phase++
+	if t.phase == len(t.signal.Segments) {
+		if !t.signal.Repeat {
+			return d.finish(detections, t, completed.end())
+		}
+		t.phase = 0
+	}
+	if d.current.label != t.labels[t.phase] {
+		return d.finish(detections, t, completed.end())
+	}
+
+	return detections
+}
+
+func (d *Detector) fits(t *tracker, segment int, r run) bool {
+	if r.label != t.labels[segment] {
+		return false
+	}
+
+	duration := t.signal.Segments[segment].Duration
+	return r.duration >= d.minDuration(duration) && r.duration <= d.maxDuration(duration)
+}
+
+func (d *Detector) minDuration(duration time.Duration) time.Duration {
+	return time.Duration(float64(duration)*(1-d.plan.Tolerance)) - debounceBlocks*d.blockDuration
+}
+
+func (d *Detector) maxDuration(duration time.Duration) time.Duration {
+	return time.Duration(float64(duration)*(1+d.plan.Tolerance)) + debounceBlocks*d.blockDuration
+}
+
+func (d *Detector) activate(detections []Detection, t *tracker, phase int, start time.Duration) []Detection {
+	t.active = true
+	t.phase = phase
+	t.start = start
+
+	return append(detections, Detection{
+		Signal:   t.signal,
+		Start:    start,
+		Duration: d.position - start,
+	})
+}
+
+func (d *Detector) finish(detections []Detection, t *tracker, end time.Duration) []Detection {
+	t.active = false
+
+	return append(detections, Detection{
+		Signal:   t.signal,
+		Start:    t.start,
+		Duration: end - t.start,
+		Final:    true,
+	})
+}
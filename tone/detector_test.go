@@ -0,0 +1,95 @@
+package tone
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+)
+
+const frameDuration = 20 * time.Millisecond
+
+// detect plays the signal at the level for the duration followed by a second of silence and returns all detections
+func detect(t *testing.T, codec *audiocodec.Codec, plan *Plan, signal *Signal, level float64, duration time.Duration) []Detection {
+	t.Helper()
+	g, err := NewGenerator(codec, signal, level)
+	if err != nil {
+		t.Fatal(err)
+	}
+	silence, err := NewGenerator(codec, sequence(Sit, "silence", pause(time.Second)), DefaultLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewDetector(codec, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var detections []Detection
+	for elapsed := time.Duration(0); ; elapsed += frameDuration {
+		frame, err := g.Frame(frameDuration)
+		if errors.Is(err, io.EOF) || elapsed >= duration {
+			if frame, err = silence.Frame(frameDuration); errors.Is(err, io.EOF) {
+				break
+			}
+		}
+		detected, err := d.Process(frame)
+		if err != nil {
+			t.Fatal(err)
+		}
+		detections = append(detections, detected...)
+	}
+	return append(detections, d.Flush()...)
+}
+
+func TestGeneratorDetector(t *testing.T) {
+	for _, codec := range []*audiocodec.Codec{audiocodec.Pcm8kHz16bCodec, audiocodec.PcmA8kHz8bCodec, audiocodec.Pcm16kHz16bCodec} {
+		for _, plan := range []*Plan{RuPlan, UsPlan, GbPlan, DePlan} {
+			for _, signal := range plan.Signals {
+				duration := 3 * signal.Period()
+				switch {
+				case signal.IsContinuous():
+					duration = signal.MinDuration + time.Second
+				case !signal.Repeat:
+					duration = signal.Period()
+				}
+
+				// other signals of the plan may share a part of the cadence, e.g. the call waiting tone
+				// of DE resembles congestion, only the generated one must be reported exactly once
+				var detections []Detection
+				for _, d := range detect(t, codec, plan, signal, DefaultLevel, duration) {
+					if d.Signal == signal {
+						detections = append(detections, d)
+					}
+				}
+				if len(detections) != 2 || detections[0].Final || !detections[1].Final {
+					t.Errorf("%s %s %s: detections %+v", codec.Name, plan.Country, signal.Name, detections)
+					continue
+				}
+				if detections[0].Start != 0 || detections[1].Start != 0 {
+					t.Errorf("%s %s %s: started at %v, %v", codec.Name, plan.Country, signal.Name, detections[0].Start, detections[1].Start)
+				}
+			}
+		}
+	}
+}
+
+func TestDetectorSilence(t *testing.T) {
+	codec := audiocodec.Pcm8kHz16bCodec
+	// the dial tone below -45 dBFS is silence
+	if detections := detect(t, codec, RuPlan, RuPlan.Signal(Dial), -50, 3*time.Second); len(detections) != 0 {
+		t.Errorf("detections %+v", detections)
+	}
+}
+
+func TestNewDetector(t *testing.T) {
+	plan := &Plan{Signals: []*Signal{continuous(Dial, "dial", time.Second, 5000)}}
+	if _, err := NewDetector(audiocodec.Pcm8kHz16bCodec, plan); err == nil {
+		t.Error("frequency above the Nyquist frequency is accepted")
+	}
+	if _, err := NewDetector(audiocodec.Pcm16kHz16bCodec, plan); err != nil {
+		t.Error(err)
+	}
+}
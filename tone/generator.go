@@ -0,0 +1,143 @@
+package tone
+
+import (
+	"io"
+	"math"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/pcm"
+)
+
+const (
+	// DefaultLevel is the level of every frequency of a generated tone in dBm0
+	DefaultLevel = -10.0
+
+	// fullScaleLevel is the level of a full scale sine wave in dBm0 (ITU-T G.711 digital milliwatt)
+	fullScaleLevel = 3.14
+	// rampDuration is the fade in and fade out of every tone segment preventing clicks
+	rampDuration = 2 * time.Millisecond
+)
+
+// Generator synthesizes the cadence of a signal frame by frame in any codec supported by the pcm package.
+// Repeating and continuous signals never end, a sequence ends with io.EOF.
+type Generator struct {
+	codec      *audiocodec.Codec
+	signal     *Signal
+	amplitude  float64
+	encoder    func(sample float32, buf []byte)
+	sampleSize int
+
+	lengths  []int // length of every segment in samples, zero for a continuous tone
+	rampSize int
+	segment  int
+	position int // sample within the current segment
+	finished bool
+}
+
+func NewGenerator(codec *audiocodec.Codec, signal *Signal, level float64) (*Generator, error) {
+	encoder, err := pcm.Encoder(codec)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Generator{
+		codec:      codec,
+		signal:     signal,
+		amplitude:  math.Pow(10, (level-fullScaleLevel)/20),
+		encoder:    encoder,
+		sampleSize: codec.SampleSize(),
+		lengths:    make([]int, len(signal.Segments)),
+		rampSize:   codec.SampleCountByDuration(rampDuration),
+	}
+	for i, seg := range signal.Segments {
+		g.lengths[i] = int(int64(seg.Duration) * int64(codec.SampleRate) / int64(time.Second))
+	}
+
+	return g, nil
+}
+
+// Read fills p with whole samples of the signal.
+func (g *Generator) Read(p []byte) (int, error) {
+	if g.finished {
+		return 0, io.EOF
+	}
+
+	n := 0
+	for ; n+g.sampleSize <= len(p) && !g.finished; n += g.sampleSize {
+		g.encoder(g.next(), p[n:n+g.sampleSize])
+	}
+
+	return n, nil
+}
+
+// Frame returns the next frame of the duration. The last frame of a sequence is padded with silence.
+func (g *Generator) Frame(duration time.Duration) ([]byte, error) {
+	if g.finished {
+		return nil, io.EOF
+	}
+
+	frame := make([]byte, g.codec.Size(duration))
+	n, _ := g.Read(frame)
+	for ; n < len(frame); n += g.sampleSize {
+		g.encoder(0, frame[n:n+g.sampleSize])
+	}
+
+	return frame, nil
+}
+
+// Reset rewinds the generator to the beginning of the cadence.
+func (g *Generator) Reset() {
+	g.segment = 0
+	g.position = 0
+	g.finished = false
+}
+
+func (g *Generator) Codec() *audiocodec.Codec {
+	return g.codec
+}
+
+func (g *Generator) Signal() *Signal {
+	return g.signal
+}
+
+func (g *Generator) next() float32 {
+	seg := g.signal.Segments[g.segment]
+	length := g.lengths[g.segment]
+
+	var v float64
+	if !seg.IsSilence() {
+		t := float64(g.position) / float64(g.codec.SampleRate)
+		for _, f := range seg.Frequencies {
+			v += math.Sin(2 * math.Pi * f * t)
+		}
+		v *= g.amplitude * g.ramp(length)
+	}
+
+	g.position++
+	if length > 0 && g.position >= length {
+		g.position = 0
+		g.segment++
+		if g.segment == len(g.lengths) {
+			g.segment = 0
+			g.finished = !g.signal.Repeat
+		}
+	}
+
+	return float32(v)
+}
+
+func (g *Generator) ramp(length int) float64 {
+	if g.rampSize == 0 {
+		return 1
+	}
+
+	distance := g.position
+	if length > 0 && length-1-g.position < distance {
+		distance = length - 1 - g.position
+	}
+	if distance >= g.rampSize {
+		return 1
+	}
+	return float64(distance) / float64(g.rampSize)
+}
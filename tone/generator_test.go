@@ -0,0 +1,108 @@
+package tone
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/URALINNOVATSIYA/audiocodec"
+	"github.com/URALINNOVATSIYA/audiocodec/pcm"
+)
+
+func TestGeneratorLevel(t *testing.T) {
+	codec := audiocodec.Pcm16kHz16bCodec
+	g, err := NewGenerator(codec, continuous(Dial, "milliwatt", 0, 1004), DefaultLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame, err := g.Frame(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples, err := pcm.Decode(codec, nil, frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var peak, sum float64
+	for _, s := range samples {
+		peak = max(peak, math.Abs(float64(s)))
+		sum += float64(s) * float64(s)
+	}
+	// a sine at 0 dBm0 is 3.14 dB below full scale, its RMS is another 3.01 dB lower
+	peakDb := 20 * math.Log10(peak)
+	rmsDb := 10 * math.Log10(sum/float64(len(samples)))
+	if want := DefaultLevel - fullScaleLevel; math.Abs(peakDb-want) > 0.01 {
+		t.Errorf("peak = %.3f dBFS, want %.3f", peakDb, want)
+	}
+	if want := DefaultLevel - fullScaleLevel - 10*math.Log10(2); math.Abs(rmsDb-want) > 0.01 {
+		t.Errorf("rms = %.3f dBFS, want %.3f", rmsDb, want)
+	}
+}
+
+func TestGeneratorSequence(t *testing.T) {
+	codec := audiocodec.Pcm8kHz16bCodec
+	g, err := NewGenerator(codec, ItuSitSignal, DefaultLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signal, err := io.ReadAll(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := codec.Size(ItuSitSignal.Period()); len(signal) != want {
+		t.Fatalf("signal size = %d, want %d", len(signal), want)
+	}
+
+	// every segment fades in from and out to silence
+	segmentSize := codec.Size(330 * time.Millisecond)
+	for i := 0; i < len(signal); i += segmentSize {
+		first, last := signal[i:i+2], signal[i+segmentSize-2:i+segmentSize]
+		if !bytes.Equal(first, []byte{0, 0}) || !bytes.Equal(last, []byte{0, 0}) {
+			t.Errorf("segment at %d starts with % x, ends with % x", i, first, last)
+		}
+	}
+
+	// frames are padded with silence at the end of the sequence
+	g.Reset()
+	var frames [][]byte
+	for {
+		frame, err := g.Frame(100 * time.Millisecond)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames = append(frames, frame)
+	}
+	if len(frames) != 10 {
+		t.Fatalf("%d frames, want 10", len(frames))
+	}
+	if joined := bytes.Join(frames, nil); !bytes.Equal(joined[:len(signal)], signal) || slices.ContainsFunc(joined[len(signal):], func(b byte) bool { return b != 0 }) {
+		t.Error("frames differ from the signal")
+	}
+}
+
+func TestGeneratorRepeat(t *testing.T) {
+	codec := audiocodec.PcmU8kHz8bCodec
+	busy := RuPlan.Signal(Busy)
+	g, err := NewGenerator(codec, busy, DefaultLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	period := codec.Size(busy.Period())
+	cycles := make([]byte, 3*period)
+	if n, err := io.ReadFull(g, cycles); err != nil || n != len(cycles) {
+		t.Fatalf("read %d, %v", n, err)
+	}
+	for i := period; i < len(cycles); i += period {
+		if !bytes.Equal(cycles[i:i+period], cycles[:period]) {
+			t.Errorf("cycle at %d differs from the first one", i)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package tone
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const DefaultTolerance = 0.15
+
+// Plan is a set of signals used by the telephone network of a country.
+type Plan struct {
+	Country string
+	Signals []*Signal
+	// Tolerance is the allowed relative deviation of cadence segment durations
+	Tolerance float64
+}
+
+func (p *Plan) Signal(kind Kind) *Signal {
+	for _, s := range p.Signals {
+		if s.Kind == kind {
+			return s
+		}
+	}
+	return nil
+}
+
+const (
+	sitShort = 276 * time.Millisecond
+	sitLong  = 380 * time.Millisecond
+)
+
+var (
+	FaxCngSignal = cadence(FaxCng, "fax_cng", tone(500*time.Millisecond, 1100), pause(3*time.Second))
+	FaxCedSignal = continuous(FaxCed, "fax_ced", 500*time.Millisecond, 2100)
+
+	// ItuSitSignal is the special information tone of ITU-T E.180 used outside North America
+	ItuSitSignal = sequence(
+		Sit, "sit",
+		tone(330*time.Millisecond, 950),
+		tone(330*time.Millisecond, 1400),
+		tone(330*time.Millisecond, 1800),
+	)
+
+	// North American special information tones, the variant is encoded by the frequency and the length
+	// of the first two segments
+	SitInterceptSignal = sequence(Sit, "sit_intercept", tone(sitShort, 913.8), tone(sitShort, 1370.6), tone(sitLong, 1776.7))
+	SitVacantSignal    = sequence(Sit, "sit_vacant_code", tone(sitLong, 985.2), tone(sitShort, 1370.6), tone(sitLong, 1776.7))
+	SitReorderSignal   = sequence(Sit, "sit_reorder", tone(sitLong, 913.8), tone(sitShort, 1428.5), tone(sitLong, 1776.7))
+	SitNoCircuitSignal = sequence(Sit, "sit_no_circuit", tone(sitLong, 913.8), tone(sitLong, 1370.6), tone(sitLong, 1776.7))
+)
+
+var RuPlan = &Plan{
+	Country: "RU",
+	Signals: []*Signal{
+		continuous(Dial, "dial", 1500*time.Millisecond, 425),
+		cadence(Ringback, "ringback", tone(time.Second, 425), pause(4*time.Second)),
+		cadence(Busy, "busy", tone(350*time.Millisecond, 425), pause(350*time.Millisecond)),
+		cadence(Congestion, "congestion", tone(175*time.Millisecond, 425), pause(175*time.Millisecond)),
+		cadence(CallWaiting, "call_waiting", tone(200*time.Millisecond, 425), pause(5*time.Second)),
+		ItuSitSignal,
+		FaxCngSignal,
+		FaxCedSignal,
+	},
+	Tolerance: DefaultTolerance,
+}
+
+var UsPlan = &Plan{
+	Country: "US",
+	Signals: []*Signal{
+		continuous(Dial, "dial", time.Second, 350, 440),
+		cadence(Ringback, "ringback", tone(2*time.Second, 440, 480), pause(4*time.Second)),
+		cadence(Busy, "busy", tone(500*time.Millisecond, 480, 620), pause(500*time.Millisecond)),
+		cadence(Congestion, "reorder", tone(250*time.Millisecond, 480, 620), pause(250*time.Millisecond)),
+		cadence(CallWaiting, "call_waiting", tone(300*time.Millisecond, 440), pause(9700*time.Millisecond)),
+		SitInterceptSignal,
+		SitVacantSignal,
+		SitReorderSignal,
+		SitNoCircuitSignal,
+		FaxCngSignal,
+		FaxCedSignal,
+	},
+	Tolerance: DefaultTolerance,
+}
+
+var GbPlan = &Plan{
+	Country: "GB",
+	Signals: []*Signal{
+		continuous(Dial, "dial", time.Second, 350, 450),
+		cadence(
+			Ringback, "ringback",
+			tone(400*time.Millisecond, 400, 450), pause(200*time.Millisecond),
+			tone(400*time.Millisecond, 400, 450), pause(2*time.Second),
+		),
+		cadence(Busy, "busy", tone(375*time.Millisecond, 400), pause(375*time.Millisecond)),
+		cadence(
+			Congestion, "congestion",
+			tone(400*time.Millisecond, 400), pause(350*time.Millisecond),
+			tone(225*time.Millisecond, 400), pause(525*time.Millisecond),
+		),
+		cadence(CallWaiting, "call_waiting", tone(100*time.Millisecond, 400), pause(2500*time.Millisecond)),
+		ItuSitSignal,
+		FaxCngSignal,
+		FaxCedSignal,
+	},
+	Tolerance: DefaultTolerance,
+}
+
+var DePlan = &Plan{
+	Country: "DE",
+	Signals: []*Signal{
+		continuous(Dial, "dial", 1500*time.Millisecond, 425),
+		cadence(Ringback, "ringback", tone(time.Second, 425), pause(4*time.Second)),
+		cadence(Busy, "busy", tone(480*time.Millisecond, 425), pause(480*time.Millisecond)),
+		cadence(Congestion, "congestion", tone(240*time.Millisecond, 425), pause(240*time.Millisecond)),
+		cadence(
+			CallWaiting, "call_waiting",
+			tone(200*time.Millisecond, 425), pause(200*time.Millisecond),
+			tone(200*time.Millisecond, 425), pause(5*time.Second),
+		),
+		ItuSitSignal,
+		FaxCngSignal,
+		FaxCedSignal,
+	},
+	Tolerance: DefaultTolerance,
+}
+
+var plans = map[string]*Plan{
+	"RU": RuPlan,
+	"US": UsPlan,
+	"GB": GbPlan,
+	"DE": DePlan,
+}
+
+// PlanByCountry returns the tone plan by ISO 3166-1 alpha-2 country code.
+func PlanByCountry(country string) (*Plan, error) {
+	if plan, exists := plans[strings.ToUpper(country)]; exists {
+		return plan, nil
+	}
+	return nil, fmt.Errorf("tone plan for country \"%s\" does not exist", country)
+}
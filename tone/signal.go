@@ -0,0 +1,96 @@
+package tone
+
+import "time"
+
+type Kind string
+
+const (
+	Dial        Kind = "dial"
+	Ringback    Kind = "ringback"
+	Busy        Kind = "busy"
+	Congestion  Kind = "congestion"
+	CallWaiting Kind = "call_waiting"
+	Sit         Kind = "sit"
+	FaxCng      Kind = "fax_cng"
+	FaxCed      Kind = "fax_ced"
+	Dtmf        Kind = "dtmf"
+)
+
+func (k Kind) String() string {
+	return string(k)
+}
+
+// Segment is a single step of a cadence.
+// A segment without frequencies is a pause, a segment with several frequencies is a dual (or multi) tone.
+type Segment struct {
+	Frequencies []float64
+	Duration    time.Duration
+}
+
+func (s Segment) IsSilence() bool {
+	return len(s.Frequencies) == 0
+}
+
+// Signal describes a telephony tone by its cadence.
+// A single segment of zero duration is a continuous tone.
+type Signal struct {
+	Kind     Kind
+	Name     string
+	Segments []Segment
+	// Repeat defines whether the cadence is played in a loop
+	Repeat bool
+	// MinDuration is how long a continuous tone must last before it is reported
+	MinDuration time.Duration
+}
+
+func (s *Signal) IsContinuous() bool {
+	return len(s.Segments) == 1 && s.Segments[0].Duration == 0
+}
+
+// Period returns the duration of one cadence cycle, zero for continuous tones.
+func (s *Signal) Period() time.Duration {
+	var period time.Duration
+	for _, seg := range s.Segments {
+		period += seg.Duration
+	}
+	return period
+}
+
+func tone(duration time.Duration, frequencies ...float64) Segment {
+	return Segment{
+		Frequencies: frequencies,
+		Duration:    duration,
+	}
+}
+
+func pause(duration time.Duration) Segment {
+	return Segment{
+		Duration: duration,
+	}
+}
+
+func continuous(kind Kind, name string, minDuration time.Duration, frequencies ...float64) *Signal {
+	return &Signal{
+		Kind:        kind,
+		Name:        name,
+		Segments:    []Segment{tone(0, frequencies...)},
+		MinDuration: minDuration,
+	}
+}
+
+func cadence(kind Kind, name string, segments ...Segment) *Signal {
+	return &Signal{
+		Kind:     kind,
+		Name:     name,
+		Segments: segments,
+		Repeat:   true,
+	}
+}
+
+func sequence(kind Kind, name string, segments ...Segment) *Signal {
+	return &Signal{
+		Kind:     kind,
+		Name:     name,
+		Segments: segments,
+	}
+}
@@ -4,31 +4,99 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 	"time"
 )
 
+// ds64ChunkSize is the size of the ds64 chunk without the table, a JUNK chunk of the same size reserves its place
+const ds64ChunkSize = 36
+
+// adpcmBits is the size of a code of IMA and Microsoft ADPCM
+const adpcmBits = 4
+
+// MsAdpcmCoefficients are the standard predictor coefficient pairs of Microsoft ADPCM
+var MsAdpcmCoefficients = [7][2]int16{{256, 0}, {512, -256}, {0, 0}, {192, 64}, {240, 0}, {460, -208}, {392, -232}}
+
 type Wav struct {
-	headers  []byte
-	data     []byte
-	codec    *Codec
-	read     int
-	editable bool
+	headers         []byte
+	data            []byte
+	trailer         []byte // chunks following the data of a parsed file
+	info            WavInfo
+	bext            *Bext
+	ixml            string
+	cuePoints       []CuePoint
+	codec           *Codec
+	channels        int
+	blockAlign      int
+	samplesPerBlock int
+	// declaredSampleCount is the number of samples per channel of the fact chunk, the last block of a block based
+	// codec may be padded beyond it
+	declaredSampleCount    int
+	hasDeclaredSampleCount bool
+	read                   int
+	editable               bool
 }
 
 func NewWav(codec *Codec) *Wav {
+	return NewWavWithChannels(codec, 1)
+}
+
+// NewWavWithChannels creates a WAV for interleaved multichannel data, every channel is described by the codec.
+func NewWavWithChannels(codec *Codec, channels int) *Wav {
 	return &Wav{
 		codec:    codec,
+		channels: channels,
 		editable: true,
 	}
 }
 
+// NewWavWithBlockAlign creates a WAV for block based codecs (IMA and Microsoft ADPCM, GSM), blockAlign is the size
+// of a block of all channels. A block of G.726 is 8 samples, so its blockAlign is bits per code times channels.
+func NewWavWithBlockAlign(codec *Codec, channels int, blockAlign int) *Wav {
+	w := NewWavWithChannels(codec, channels)
+	w.blockAlign = blockAlign
+	w.samplesPerBlock = SamplesPerBlock(codec, channels, blockAlign)
+	return w
+}
+
+// SamplesPerBlock returns the number of samples per channel in a block of IMA ADPCM, Microsoft ADPCM, G.726 or GSM,
+// or zero for other codecs. An ADPCM block shorter than its header holds no samples.
+func SamplesPerBlock(codec *Codec, channels int, blockAlign int) int {
+	switch codec.Name {
+	case ImaAdpcm:
+		// 4-byte header with the first sample per channel
+		if blockAlign < 4*channels {
+			return 0
+		}
+		return (blockAlign-4*channels)*8/(adpcmBits*channels) + 1
+	case MsAdpcm:
+		// 7-byte header with two first samples per channel
+		if blockAlign < 7*channels {
+			return 0
+		}
+		return (blockAlign-7*channels)*8/(adpcmBits*channels) + 2
+	case G726:
+		return 8
+	case Gsm:
+		// WAV49 packs two 160-sample frames into 65 bytes
+		return blockAlign / 65 * 320
+	}
+	return 0
+}
+
+// NewWavFromBytes parses a RIFF WAVE file or its 64-bit extensions RF64 (EBU Tech 3306) and BW64 (ITU-R BS.2088).
 func NewWavFromBytes(b []byte) (*Wav, error) {
-	if len(b) < 44 || !(b[0] == 'R' && b[1] == 'I' && b[2] == 'F' && b[3] == 'F') || !(b[8] == 'W' && b[9] == 'A' && b[10] == 'V' && b[11] == 'E') {
+	if len(b) < 44 || !(string(b[0:4]) == "RIFF" || string(b[0:4]) == "RF64" || string(b[0:4]) == "BW64") || !(b[8] == 'W' && b[9] == 'A' && b[10] == 'V' && b[11] == 'E') {
 		return nil, InvalidWav
 	}
+	rf64 := string(b[0:4]) != "RIFF"
 
 	var w Wav
 	w.codec = new(Codec)
+	// 64-bit chunk sizes of the ds64 chunk
+	var sizes map[string]int
+	// texts of cue points are linked after all chunks are read
+	var adtl [][]byte
 
 	i := 12
 	n := len(b)
@@ -37,47 +105,41 @@ func NewWavFromBytes(b []byte) (*Wav, error) {
 			break
 		}
 		chunkId0, chunkId1, chunkId2, chunkId3 := b[i], b[i+1], b[i+2], b[i+3]
-		chunkSize := binary.LittleEndian.Uint32(b[i+4 : i+8])
+		chunkSize32 := binary.LittleEndian.Uint32(b[i+4 : i+8])
+		chunkSize := int(chunkSize32)
+		if size, ok := sizes[string(b[i:i+4])]; ok && chunkSize32 == math.MaxUint32 {
+			chunkSize = size
+		}
 		payloadStart := i + 8
-		payloadEnd := payloadStart + int(chunkSize)
-		if payloadEnd > n {
+		payloadEnd := payloadStart + chunkSize
+		if payloadEnd > n || payloadEnd < payloadStart {
 			return nil, TruncatedWav
 		}
 
-		if chunkId0 == 'f' && chunkId1 == 'm' && chunkId2 == 't' && chunkId3 == ' ' {
-			if chunkSize < 16 {
-				return nil, fmt.Errorf("invalid fmt chunk: size=%d", chunkSize)
+		if rf64 && i == 12 {
+			if chunkId0 != 'd' || chunkId1 != 's' || chunkId2 != '6' || chunkId3 != '4' {
+				return nil, fmt.Errorf("%w: ds64 chunk not found", InvalidWav)
 			}
-			audioFormat := binary.LittleEndian.Uint16(b[payloadStart+0 : payloadStart+2])
-			numChannels := binary.LittleEndian.Uint16(b[payloadStart+2 : payloadStart+4])
-			sampleRate := binary.LittleEndian.Uint32(b[payloadStart+4 : payloadStart+8])
-			bitsPerSample := binary.LittleEndian.Uint16(b[payloadStart+14 : payloadStart+16])
-
-			switch audioFormat {
-			case 1:
-				w.codec.Name = Pcm
-			case 6:
-				w.codec.Name = PcmA
-			case 7:
-				w.codec.Name = PcmU
-			default:
-				return nil, fmt.Errorf("%w: format tag=%d", UnsupportedFormat, audioFormat)
+			if sizes = parseDs64(b[payloadStart:payloadEnd]); sizes == nil {
+				return nil, fmt.Errorf("invalid ds64 chunk: size=%d", chunkSize)
 			}
-			if numChannels != 1 {
-				return nil, OnlyMonoSupported
-			}
-			w.codec.SampleRate = int(sampleRate)
-			w.codec.BitRate = int(bitsPerSample)
 		} else if chunkId0 == 'd' && chunkId1 == 'a' && chunkId2 == 't' && chunkId3 == 'a' {
 			w.headers = b[:payloadStart:payloadStart]
-			w.data = b[payloadStart : payloadStart+int(chunkSize) : payloadStart+int(chunkSize)]
+			w.data = b[payloadStart:payloadEnd:payloadEnd]
+			w.trailer = b[payloadEnd:]
+		} else if err := w.parseChunk(b[i:i+4], b[payloadStart:payloadEnd], &adtl); err != nil {
+			return nil, err
 		}
 
 		// Advance to next chunk with word alignment
-		if (int(chunkSize) & 1) == 1 {
+		if (chunkSize & 1) == 1 {
 			chunkSize++ // pad byte if odd-sized chunk
 		}
-		i = payloadStart + int(chunkSize)
+		i = payloadStart + chunkSize
+	}
+
+	for _, list := range adtl {
+		parseAdtl(list, w.cuePoints)
 	}
 
 	if w.codec.Name == "" || w.codec.SampleRate == 0 || w.codec.BitRate == 0 {
@@ -89,6 +151,224 @@ func NewWavFromBytes(b []byte) (*Wav, error) {
 	return &w, nil
 }
 
+// ReadWavHeader reads the format and metadata chunks of a WAV, RF64 or BW64 file and seeks past the sample data.
+// It returns an editable WAV with the format and metadata of the file but without data, and the number of samples
+// per channel in the data chunk of the file.
+func ReadWavHeader(r io.ReadSeeker) (*Wav, int, error) {
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, 0, err
+	}
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err = r.Seek(start, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+
+	b := make([]byte, 12)
+	if _, err = io.ReadFull(r, b); err != nil || !(string(b[0:4]) == "RIFF" || string(b[0:4]) == "RF64" || string(b[0:4]) == "BW64") || string(b[8:12]) != "WAVE" {
+		return nil, 0, InvalidWav
+	}
+	rf64 := string(b[0:4]) != "RIFF"
+
+	w := &Wav{
+		codec:    new(Codec),
+		editable: true,
+	}
+	var sizes map[string]int
+	var adtl [][]byte
+	dataSize := -1
+
+	offset := start + 12
+	for {
+		header := make([]byte, 8)
+		if _, err = io.ReadFull(r, header); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			return nil, 0, err
+		}
+		chunkSize32 := binary.LittleEndian.Uint32(header[4:8])
+		chunkSize := int(chunkSize32)
+		if size, ok := sizes[string(header[0:4])]; ok && chunkSize32 == math.MaxUint32 {
+			chunkSize = size
+		}
+		payloadStart := offset + 8
+		if chunkSize < 0 || payloadStart+int64(chunkSize) > end {
+			return nil, 0, TruncatedWav
+		}
+
+		id := string(header[0:4])
+		switch {
+		case rf64 && offset == start+12:
+			if id != "ds64" {
+				return nil, 0, fmt.Errorf("%w: ds64 chunk not found", InvalidWav)
+			}
+			p := make([]byte, chunkSize)
+			if _, err = io.ReadFull(r, p); err != nil {
+				return nil, 0, err
+			}
+			if sizes = parseDs64(p); sizes == nil {
+				return nil, 0, fmt.Errorf("invalid ds64 chunk: size=%d", chunkSize)
+			}
+		case id == "fmt " || id == "bext" || id == "iXML" || id == "LIST" || id == "cue ":
+			p := make([]byte, chunkSize)
+			if _, err = io.ReadFull(r, p); err != nil {
+				return nil, 0, err
+			}
+			if err = w.parseChunk(header[0:4], p, &adtl); err != nil {
+				return nil, 0, err
+			}
+		case id == "data":
+			dataSize = chunkSize
+		}
+
+		// Advance to next chunk with word alignment
+		if (chunkSize & 1) == 1 {
+			chunkSize++ // pad byte if odd-sized chunk
+		}
+		offset = payloadStart + int64(chunkSize)
+		if _, err = r.Seek(offset, io.SeekStart); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	for _, list := range adtl {
+		parseAdtl(list, w.cuePoints)
+	}
+
+	if w.codec.Name == "" || w.codec.SampleRate == 0 || w.codec.BitRate == 0 {
+		return nil, 0, fmt.Errorf("fmt chunk not found: %w", UnsupportedFormat)
+	}
+	if dataSize < 0 {
+		return nil, 0, fmt.Errorf("data chunk not found: %w", UnsupportedFormat)
+	}
+	return w, w.sampleCount(dataSize), nil
+}
+
+// parseChunk parses a chunk of the format or metadata, lists of cue point texts are collected into adtl to be linked
+// to the points after all chunks are read.
+func (w *Wav) parseChunk(id []byte, p []byte, adtl *[][]byte) error {
+	chunkId0, chunkId1, chunkId2, chunkId3 := id[0], id[1], id[2], id[3]
+	if chunkId0 == 'f' && chunkId1 == 'm' && chunkId2 == 't' && chunkId3 == ' ' {
+		if len(p) < 16 {
+			return fmt.Errorf("invalid fmt chunk: size=%d", len(p))
+		}
+		audioFormat := binary.LittleEndian.Uint16(p[0:2])
+		numChannels := binary.LittleEndian.Uint16(p[2:4])
+		sampleRate := binary.LittleEndian.Uint32(p[4:8])
+		blockAlign := binary.LittleEndian.Uint16(p[12:14])
+		bitsPerSample := binary.LittleEndian.Uint16(p[14:16])
+
+		switch audioFormat {
+		case 1:
+			w.codec.Name = Pcm
+		case 2:
+			w.codec.Name = MsAdpcm
+		case 3:
+			w.codec.Name = PcmFloat
+		case 6:
+			w.codec.Name = PcmA
+		case 7:
+			w.codec.Name = PcmU
+		case 0x11:
+			w.codec.Name = ImaAdpcm
+		case 0x31:
+			w.codec.Name = Gsm
+		case 0x45, 0x64:
+			w.codec.Name = G726
+		default:
+			return fmt.Errorf("%w: format tag=%d", UnsupportedFormat, audioFormat)
+		}
+		if numChannels == 0 {
+			return fmt.Errorf("invalid fmt chunk: channels=%d", numChannels)
+		}
+		w.channels = int(numChannels)
+		w.codec.SampleRate = int(sampleRate)
+		w.codec.BitRate = int(bitsPerSample)
+
+		if w.codec.Name == ImaAdpcm || w.codec.Name == MsAdpcm {
+			w.codec.BitRate = 16
+			// the extra format bytes start with the number of samples per block
+			if len(p) < 20 || bitsPerSample != adpcmBits {
+				return fmt.Errorf("invalid fmt chunk of %s: size=%d, bits per sample=%d", w.codec.Name, len(p), bitsPerSample)
+			}
+			w.blockAlign = int(blockAlign)
+			w.samplesPerBlock = int(binary.LittleEndian.Uint16(p[18:20]))
+			if w.samplesPerBlock == 0 || w.samplesPerBlock > SamplesPerBlock(w.codec, w.channels, w.blockAlign) {
+				return fmt.Errorf("invalid fmt chunk of %s: block align=%d, samples per block=%d", w.codec.Name, blockAlign, w.samplesPerBlock)
+			}
+		}
+		if w.codec.Name == Gsm {
+			// bits per sample are zero, the block is a pair of frames
+			if len(p) < 20 || blockAlign != 65 || binary.LittleEndian.Uint16(p[18:20]) != 320 {
+				return fmt.Errorf("invalid fmt chunk of %s: size=%d, block align=%d", w.codec.Name, len(p), blockAlign)
+			}
+			w.codec.BitRate = GsmCodec.BitRate
+			w.blockAlign = 65
+			w.samplesPerBlock = 320
+		}
+		if w.codec.Name == G726 {
+			if bitsPerSample < 2 || bitsPerSample > 5 {
+				return fmt.Errorf("invalid fmt chunk of %s: bits per sample=%d", w.codec.Name, bitsPerSample)
+			}
+			// 8 codes of each channel take whole bytes
+			w.codec.BitRate = G726Codec.BitRate
+			w.blockAlign = int(bitsPerSample) * w.channels
+			w.samplesPerBlock = 8
+		}
+	} else if chunkId0 == 'f' && chunkId1 == 'a' && chunkId2 == 'c' && chunkId3 == 't' {
+		// the sample count of RF64 files does not fit into the chunk
+		if len(p) >= 4 && binary.LittleEndian.Uint32(p[0:4]) != math.MaxUint32 {
+			w.SetSampleCount(int(binary.LittleEndian.Uint32(p[0:4])))
+		}
+	} else if chunkId0 == 'b' && chunkId1 == 'e' && chunkId2 == 'x' && chunkId3 == 't' {
+		w.bext = parseBext(p)
+	} else if chunkId0 == 'i' && chunkId1 == 'X' && chunkId2 == 'M' && chunkId3 == 'L' {
+		w.ixml = fixedString(p)
+	} else if chunkId0 == 'L' && chunkId1 == 'I' && chunkId2 == 'S' && chunkId3 == 'T' {
+		if len(p) >= 4 && string(p[0:4]) == "INFO" {
+			w.info = append(w.info, parseInfo(p[4:])...)
+		}
+		if len(p) >= 4 && string(p[0:4]) == "adtl" {
+			*adtl = append(*adtl, p[4:])
+		}
+	} else if chunkId0 == 'c' && chunkId1 == 'u' && chunkId2 == 'e' && chunkId3 == ' ' {
+		w.cuePoints = parseCue(p)
+	}
+	return nil
+}
+
+// Смещение	Размер 	Описание 			Значение
+// 0x00 	4		Chunk ID			"ds64"
+// 0x04 	4		Chunk Data Size		28 + 12 * table length
+// 0x08 	8		RIFF size			64-битный размер RF64, в заголовке 0xFFFFFFFF
+// 0x10 	8		Data size			64-битный размер секции "data", в ней 0xFFFFFFFF
+// 0x18 	8		Sample count		64-битное число выборок секции "fact"
+// 0x20 	4		Table length		число записей таблицы
+// 0x24 	*		Table				записи из ID секции (4 байта) и ее 64-битного размера
+func parseDs64(b []byte) map[string]int {
+	if len(b) < ds64ChunkSize-8 {
+		return nil
+	}
+	sizes := map[string]int{
+		"data": int(binary.LittleEndian.Uint64(b[8:16])),
+	}
+	tableLength := int(binary.LittleEndian.Uint32(b[24:28]))
+	for i := 0; i < tableLength && 28+12*i+12 <= len(b); i++ {
+		entry := b[28+12*i:]
+		sizes[string(entry[0:4])] = int(binary.LittleEndian.Uint64(entry[4:12]))
+	}
+	return sizes
+}
+
+// isRf64 checks that the size of the file does not fit into the 32-bit RIFF size
+func isRf64(riffSize int) bool {
+	return int64(riffSize)-8 > math.MaxUint32
+}
+
 func (w *Wav) DataSize() int {
 	return len(w.data)
 }
@@ -112,23 +392,20 @@ func (w *Wav) Read(p []byte) (n int, err error) {
 		return 0, nil
 	}
 
-	if w.read >= len(w.headers)+len(w.data) {
+	if w.read >= len(w.headers)+len(w.data)+len(w.trailer) {
 		return 0, io.EOF
 	}
 
-	hSize := len(w.headers)
-	if w.read < hSize {
-		n = copy(p, w.headers[w.read:])
-		w.read += n
-
-		if n == len(p) {
-			return n, nil
+	pos := w.read
+	for _, part := range [...][]byte{w.headers, w.data, w.trailer} {
+		if pos >= len(part) {
+			pos -= len(part)
+			continue
 		}
+		n += copy(p[n:], part[pos:])
+		pos = 0
 	}
-
-	dr := copy(p[n:], w.data[w.read-hSize:])
-	w.read += dr
-	n += dr
+	w.read += n
 
 	return n, nil
 }
@@ -137,43 +414,135 @@ func (w *Wav) Codec() *Codec {
 	return w.codec
 }
 
-func (w *Wav) prepareHeaders() {
-	w.headers = make([]byte, w.headersSize())
+func (w *Wav) Channels() int {
+	return w.channels
+}
 
-	copy(w.headers[0:4], "RIFF")
-	binary.LittleEndian.PutUint32(w.headers[4:8], uint32(w.riffSize())-8)
-	copy(w.headers[8:12], "WAVE")
+// BlockAlign returns the size of the smallest unit of data of all channels.
+func (w *Wav) BlockAlign() int {
+	if w.blockAlign > 0 {
+		return w.blockAlign
+	}
+	return w.codec.SampleSize() * w.channels
+}
 
-	// Chunk ID "fmt "
-	copy(w.headers[12:16], "fmt ")
-	binary.LittleEndian.PutUint32(w.headers[16:20], uint32(w.fmtChunkSize()-8))
-	binary.LittleEndian.PutUint16(w.headers[20:22], uint16(w.compressionCode()))
-	binary.LittleEndian.PutUint16(w.headers[22:24], 1)
-	binary.LittleEndian.PutUint32(w.headers[24:28], uint32(w.codec.SampleRate))
-	binary.LittleEndian.PutUint32(w.headers[28:32], uint32(w.codec.Size(time.Second)))
-	binary.LittleEndian.PutUint16(w.headers[32:34], uint16(w.codec.SampleSize()))
-	binary.LittleEndian.PutUint16(w.headers[34:36], uint16(w.codec.BitRate))
+// SamplesPerBlock returns the number of samples per channel in a block of block based codecs.
+func (w *Wav) SamplesPerBlock() int {
+	return w.samplesPerBlock
+}
 
-	if w.codec.Name == Pcm {
-		// Chunk ID "data"
-		copy(w.headers[36:40], "data")
-		binary.LittleEndian.PutUint32(w.headers[40:44], uint32(len(w.data)))
-	} else {
-		binary.LittleEndian.PutUint16(w.headers[36:38], uint16(w.extraFormatSize()))
+// SampleCount returns the number of samples per channel.
+func (w *Wav) SampleCount() int {
+	return w.sampleCount(len(w.data))
+}
 
-		// Chunk ID "fact"
-		copy(w.headers[38:42], "fact")
-		binary.LittleEndian.PutUint32(w.headers[42:46], uint32(4)) // Chunk Data Size
-		binary.LittleEndian.PutUint32(w.headers[46:50], uint32(w.codec.SampleCountBySize(len(w.data))))
+// SetSampleCount declares the number of samples per channel of block based codecs whose last block is padded,
+// the count is written to the fact chunk.
+func (w *Wav) SetSampleCount(count int) {
+	w.declaredSampleCount = count
+	w.hasDeclaredSampleCount = true
+}
 
-		// Chunk ID "data"
-		copy(w.headers[50:54], "data")
-		binary.LittleEndian.PutUint32(w.headers[54:58], uint32(len(w.data)))
+func (w *Wav) sampleCount(dataSize int) int {
+	if w.samplesPerBlock == 0 {
+		return w.codec.SampleCountBySize(dataSize) / w.channels
 	}
+
+	count := dataSize / w.blockAlign * w.samplesPerBlock
+	if rest := dataSize % w.blockAlign; rest > 0 {
+		// the last block may be shorter
+		if w.codec.Name == G726 {
+			count += rest * 8 / w.blockAlign
+		} else {
+			count += min(SamplesPerBlock(w.codec, w.channels, rest), w.samplesPerBlock)
+		}
+	}
+	if w.hasDeclaredSampleCount {
+		count = min(count, w.declaredSampleCount)
+	}
+	return count
 }
 
-func (w *Wav) headersSize() int {
-	return w.riffSize() - len(w.data)
+func (w *Wav) byteRate() int {
+	if w.samplesPerBlock > 0 {
+		return w.blockAlign * w.codec.SampleRate / w.samplesPerBlock
+	}
+	return w.codec.Size(time.Second) * w.channels
+}
+
+func (w *Wav) prepareHeaders() {
+	w.headers = w.appendHeaders(nil, len(w.data), false)
+	if len(w.data)%2 == 1 {
+		w.trailer = []byte{0}
+	}
+}
+
+// appendHeaders appends the headers of data of the size. If reserve is set, a JUNK chunk is written in place of ds64
+// so the file may be upgraded to RF64 later. Files over 4 GB are always written as RF64.
+func (w *Wav) appendHeaders(b []byte, dataSize int, reserve bool) []byte {
+	riffSize := w.riffSize(dataSize, reserve)
+	rf64 := isRf64(riffSize)
+
+	if rf64 {
+		b = append(b, "RF64"...)
+		b = binary.LittleEndian.AppendUint32(b, math.MaxUint32)
+	} else {
+		b = append(b, "RIFF"...)
+		b = binary.LittleEndian.AppendUint32(b, uint32(riffSize-8))
+	}
+	b = append(b, "WAVE"...)
+
+	switch {
+	case rf64:
+		b = append(b, "ds64"...)
+		b = binary.LittleEndian.AppendUint32(b, ds64ChunkSize-8)
+		b = binary.LittleEndian.AppendUint64(b, uint64(riffSize-8))
+		b = binary.LittleEndian.AppendUint64(b, uint64(dataSize))
+		b = binary.LittleEndian.AppendUint64(b, uint64(w.sampleCount(dataSize)))
+		b = binary.LittleEndian.AppendUint32(b, 0) // table length
+	case reserve:
+		b = append(b, "JUNK"...)
+		b = binary.LittleEndian.AppendUint32(b, ds64ChunkSize-8)
+		b = append(b, make([]byte, ds64ChunkSize-8)...)
+	}
+
+	b = w.appendBextChunk(b)
+
+	// Chunk ID "fmt "
+	b = append(b, "fmt "...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(w.fmtChunkSize()-8))
+	b = binary.LittleEndian.AppendUint16(b, uint16(w.compressionCode()))
+	b = binary.LittleEndian.AppendUint16(b, uint16(w.channels))
+	b = binary.LittleEndian.AppendUint32(b, uint32(w.codec.SampleRate))
+	b = binary.LittleEndian.AppendUint32(b, uint32(w.byteRate()))
+	b = binary.LittleEndian.AppendUint16(b, uint16(w.BlockAlign()))
+	b = binary.LittleEndian.AppendUint16(b, uint16(w.bitsPerSample()))
+
+	if w.codec.Name != Pcm {
+		extraFormat := w.extraFormat()
+		b = binary.LittleEndian.AppendUint16(b, uint16(len(extraFormat)))
+		b = append(b, extraFormat...)
+
+		// Chunk ID "fact"
+		sampleCount := uint32(math.MaxUint32)
+		if !rf64 {
+			sampleCount = uint32(w.sampleCount(dataSize))
+		}
+		b = append(b, "fact"...)
+		b = binary.LittleEndian.AppendUint32(b, uint32(4)) // Chunk Data Size
+		b = binary.LittleEndian.AppendUint32(b, sampleCount)
+	}
+
+	b = w.appendInfoChunk(b)
+	b = w.appendIXmlChunk(b)
+	b = w.appendCueChunks(b)
+
+	// Chunk ID "data"
+	b = append(b, "data"...)
+	if rf64 {
+		return binary.LittleEndian.AppendUint32(b, math.MaxUint32)
+	}
+	return binary.LittleEndian.AppendUint32(b, uint32(dataSize))
 }
 
 func (w *Wav) compressionCode() int {
@@ -184,11 +553,33 @@ func (w *Wav) compressionCode() int {
 		return 6
 	case PcmU:
 		return 7
+	case MsAdpcm:
+		return 2
+	case PcmFloat:
+		return 3
+	case ImaAdpcm:
+		return 0x11
+	case G726:
+		return 0x45
+	case Gsm:
+		return 0x31
 	}
 
 	panic(fmt.Errorf("compression code not found for \"%s\" codec", w.codec.Name))
 }
 
+func (w *Wav) bitsPerSample() int {
+	switch w.codec.Name {
+	case Gsm:
+		return 0
+	case ImaAdpcm, MsAdpcm:
+		return adpcmBits
+	case G726:
+		return w.blockAlign / w.channels
+	}
+	return w.codec.BitRate
+}
+
 func (w *Wav) WriteTo(writer io.Writer) (size int64, err error) {
 	if w.editable {
 		w.editable = false
@@ -206,23 +597,99 @@ func (w *Wav) WriteTo(writer io.Writer) (size int64, err error) {
 	}
 	size += int64(n)
 
+	if n, err = writer.Write(w.trailer); err != nil {
+		return 0, err
+	}
+	size += int64(n)
+
 	return size, nil
 }
 
+// WavWriter streams a WAV file of unknown length into a seekable writer. The headers reserve the place of the ds64
+// chunk with a JUNK chunk, on Close they are rewritten with the final sizes and the file becomes RF64 if its data
+// exceeds 4 GB.
+type WavWriter struct {
+	w        io.WriteSeeker
+	wav      *Wav
+	dataSize int
+	closed   bool
+}
+
+// NewWavWriter writes the headers of the WAV described by wav followed by its data written so far.
+// The wav is not editable afterwards, the data written into the stream is not kept in it.
+func NewWavWriter(w io.WriteSeeker, wav *Wav) (*WavWriter, error) {
+	if !wav.editable {
+		return nil, WavFileIsNotEditable
+	}
+	wav.editable = false
+
+	ww := &WavWriter{
+		w:   w,
+		wav: wav,
+	}
+	if _, err := w.Write(wav.appendHeaders(nil, 0, true)); err != nil {
+		return nil, err
+	}
+	if _, err := ww.Write(wav.data); err != nil {
+		return nil, err
+	}
+	return ww, nil
+}
+
+func (ww *WavWriter) Write(data []byte) (int, error) {
+	if ww.closed {
+		return 0, WavWriterIsClosed
+	}
+	n, err := ww.w.Write(data)
+	ww.dataSize += n
+	return n, err
+}
+
+// Close pads odd data, rewrites the headers and leaves the writer at the end of the file. Closing a closed writer
+// does nothing.
+func (ww *WavWriter) Close() error {
+	if ww.closed {
+		return nil
+	}
+	ww.closed = true
+
+	if ww.dataSize%2 == 1 {
+		if _, err := ww.w.Write([]byte{0}); err != nil {
+			return err
+		}
+	}
+	if _, err := ww.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := ww.w.Write(ww.wav.appendHeaders(nil, ww.dataSize, true)); err != nil {
+		return err
+	}
+	_, err := ww.w.Seek(0, io.SeekEnd)
+	return err
+}
+
 // Смещение	Размер 	Описание 			Значение
 // 0x00 	4 		Chunk ID 			"RIFF" (0x52494646)
 // 0x04 	4 		Chunk Data Size		(file size) - 8
 // 0x08 	4 		RIFF Type			"WAVE" (0x57415645)
 // 0x10 	*		Wave chunks (секции WAV-файла)
-func (w *Wav) riffSize() int {
-	return 12 + w.waveChunksSize()
+func (w *Wav) riffSize(dataSize int, reserve bool) int {
+	return 12 + w.waveChunksSize(dataSize, reserve)
 }
 
 // Существует довольно много типов секций, заданных для файлов WAV, но нужны только две из них:
 // - секция формата ("fmt ")
 // - секция данных ("data")
-func (w *Wav) waveChunksSize() int {
-	return w.fmtChunkSize() + w.dataChunkSize()
+// Метаданные записываются в секции "LIST" типа "INFO", "iXML", "cue " и "LIST" типа "adtl" перед секцией данных,
+// секция Broadcast Wave "bext" - перед секцией формата.
+// Файлы больше 4 ГБ дополнительно содержат секцию "ds64" (RF64), ее место может быть заранее занято секцией "JUNK".
+func (w *Wav) waveChunksSize(dataSize int, reserve bool) int {
+	size := w.bextChunkSize() + w.fmtChunkSize() + w.factSize() + w.dataChunkSize(dataSize)
+	size += w.infoChunkSize() + w.ixmlChunkSize() + w.cueChunksSize()
+	if reserve || isRf64(12+size) {
+		size += ds64ChunkSize
+	}
+	return size
 }
 
 // Смещение	Размер 	Описание 					Значение
@@ -248,11 +715,25 @@ func (w *Wav) fmtChunkSize() int {
 		return 24
 	}
 
-	return 26 + w.extraFormatSize()
+	return 26 + len(w.extraFormat())
 }
 
-func (w *Wav) extraFormatSize() int {
-	return w.factSize()
+// Дополнительные данные формата IMA ADPCM и GSM - число выборок в блоке (2 байта).
+// Для Microsoft ADPCM за ним следуют число коэффициентов предсказания (2 байта) и пары коэффициентов (по 4 байта).
+func (w *Wav) extraFormat() []byte {
+	switch w.codec.Name {
+	case ImaAdpcm, Gsm:
+		return binary.LittleEndian.AppendUint16(nil, uint16(w.samplesPerBlock))
+	case MsAdpcm:
+		b := binary.LittleEndian.AppendUint16(nil, uint16(w.samplesPerBlock))
+		b = binary.LittleEndian.AppendUint16(b, uint16(len(MsAdpcmCoefficients)))
+		for _, c := range MsAdpcmCoefficients {
+			b = binary.LittleEndian.AppendUint16(b, uint16(c[0]))
+			b = binary.LittleEndian.AppendUint16(b, uint16(c[1]))
+		}
+		return b
+	}
+	return nil
 }
 
 // Смещение	Размер	Описание 			Величина
@@ -268,6 +749,9 @@ func (w *Wav) extraFormatSize() int {
 // По мере появления новых форматов WAVE секция fact будет расширена с добавлением полей после поля числа выборок.
 // Программы могут использовать размер секции fact для определения, какие поля представлены в секции.
 func (w *Wav) factSize() int {
+	if w.codec.Name == Pcm {
+		return 0
+	}
 	return 12
 }
 
@@ -275,8 +759,9 @@ func (w *Wav) factSize() int {
 // 0x00 	4 		Chunk ID
 // 0x04 	4 		Chunk Data Size
 // 0x08 	* 		Chunk Data Bytes
-func (w *Wav) dataChunkSize() int {
-	return 8 + len(w.data)
+func (w *Wav) dataChunkSize(dataSize int) int {
+	// odd data is followed by a pad byte
+	return 8 + dataSize + dataSize&1
 }
 
 func (w *Wav) Data() []byte {
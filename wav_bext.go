@@ -0,0 +1,194 @@
+package audiocodec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// bextSize is the size of the bext chunk data without the coding history
+const bextSize = 602
+
+// Bext is the Broadcast Wave extension chunk (EBU Tech 3285). Loudness values are in LUFS, LU and dBTP,
+// they are stored with the precision of 0.01 and take effect starting with version 2.
+type Bext struct {
+	Description         string
+	Originator          string
+	OriginatorReference string
+	// OriginationDate is "yyyy-mm-dd", OriginationTime is "hh-mm-ss"
+	OriginationDate string
+	OriginationTime string
+	// TimeReference is the number of samples since midnight of the first sample
+	TimeReference        uint64
+	Version              uint16
+	Umid                 [64]byte
+	LoudnessValue        float64
+	LoudnessRange        float64
+	MaxTruePeakLevel     float64
+	MaxMomentaryLoudness float64
+	MaxShortTermLoudness float64
+	CodingHistory        string
+}
+
+// TimeOffset returns the time since midnight of the first sample at the sample rate of the codec.
+func (b *Bext) TimeOffset(codec *Codec) time.Duration {
+	rate := uint64(codec.SampleRate)
+	return time.Duration(b.TimeReference/rate)*time.Second + time.Duration(b.TimeReference%rate)*time.Second/time.Duration(rate)
+}
+
+// SetTimeOffset sets the time reference to the time since midnight at the sample rate of the codec.
+func (b *Bext) SetTimeOffset(codec *Codec, offset time.Duration) {
+	rate := uint64(codec.SampleRate)
+	b.TimeReference = uint64(offset/time.Second)*rate + uint64(offset%time.Second)*rate/uint64(time.Second)
+}
+
+// SetOrigination sets the origination date, time and the time reference of the recording started at t.
+func (b *Bext) SetOrigination(codec *Codec, t time.Time) {
+	b.OriginationDate = t.Format("2006-01-02")
+	b.OriginationTime = t.Format("15-04-05")
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	b.SetTimeOffset(codec, t.Sub(midnight))
+}
+
+// Bext returns the bext chunk or nil.
+func (w *Wav) Bext() *Bext {
+	return w.bext
+}
+
+// SetBext sets the bext chunk written before the fmt chunk. Zero version is written as 2.
+func (w *Wav) SetBext(bext *Bext) error {
+	if !w.editable {
+		return WavFileIsNotEditable
+	}
+
+	w.bext = bext
+	return nil
+}
+
+// IXml returns the XML document of the iXML chunk.
+func (w *Wav) IXml() string {
+	return w.ixml
+}
+
+// SetIXml sets the XML document of the iXML chunk written before the data.
+func (w *Wav) SetIXml(xml string) error {
+	if !w.editable {
+		return WavFileIsNotEditable
+	}
+
+	w.ixml = xml
+	return nil
+}
+
+// Смещение	Размер 	Описание 					Значение
+// 0x000 	256		Description					ASCII строки, дополненные нулями
+// 0x100 	32		Originator
+// 0x120 	32		OriginatorReference
+// 0x140 	10		OriginationDate				"yyyy-mm-dd"
+// 0x14a 	8		OriginationTime				"hh-mm-ss"
+// 0x152 	8		TimeReference				число выборок с полуночи (младшие, затем старшие 4 байта)
+// 0x15a 	2		Version						версия секции
+// 0x15c 	64		UMID						SMPTE 330M
+// 0x19c 	2		LoudnessValue				целые со знаком, значения * 100
+// 0x19e 	2		LoudnessRange
+// 0x1a0 	2		MaxTruePeakLevel
+// 0x1a2 	2		MaxMomentaryLoudness
+// 0x1a4 	2		MaxShortTermLoudness
+// 0x1a6 	180		Reserved					нули
+// 0x25a 	*		CodingHistory				ASCII строка
+func parseBext(b []byte) *Bext {
+	if len(b) < bextSize {
+		return nil
+	}
+
+	bext := &Bext{
+		Description:          fixedString(b[0x000:0x100]),
+		Originator:           fixedString(b[0x100:0x120]),
+		OriginatorReference:  fixedString(b[0x120:0x140]),
+		OriginationDate:      fixedString(b[0x140:0x14a]),
+		OriginationTime:      fixedString(b[0x14a:0x152]),
+		TimeReference:        binary.LittleEndian.Uint64(b[0x152:0x15a]),
+		Version:              binary.LittleEndian.Uint16(b[0x15a:0x15c]),
+		LoudnessValue:        loudness(b[0x19c:]),
+		LoudnessRange:        loudness(b[0x19e:]),
+		MaxTruePeakLevel:     loudness(b[0x1a0:]),
+		MaxMomentaryLoudness: loudness(b[0x1a2:]),
+		MaxShortTermLoudness: loudness(b[0x1a4:]),
+		CodingHistory:        fixedString(b[bextSize:]),
+	}
+	copy(bext.Umid[:], b[0x15c:0x19c])
+	return bext
+}
+
+func (w *Wav) appendBextChunk(b []byte) []byte {
+	if w.bext == nil {
+		return b
+	}
+
+	version := w.bext.Version
+	if version == 0 {
+		version = 2
+	}
+	size := bextSize + len(w.bext.CodingHistory)
+	b = append(b, "bext"...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(size))
+	b = appendFixedString(b, w.bext.Description, 256)
+	b = appendFixedString(b, w.bext.Originator, 32)
+	b = appendFixedString(b, w.bext.OriginatorReference, 32)
+	b = appendFixedString(b, w.bext.OriginationDate, 10)
+	b = appendFixedString(b, w.bext.OriginationTime, 8)
+	b = binary.LittleEndian.AppendUint64(b, w.bext.TimeReference)
+	b = binary.LittleEndian.AppendUint16(b, version)
+	b = append(b, w.bext.Umid[:]...)
+	for _, v := range [...]float64{w.bext.LoudnessValue, w.bext.LoudnessRange, w.bext.MaxTruePeakLevel, w.bext.MaxMomentaryLoudness, w.bext.MaxShortTermLoudness} {
+		b = binary.LittleEndian.AppendUint16(b, uint16(int16(math.Round(v*100))))
+	}
+	b = append(b, make([]byte, 180)...)
+	b = append(b, w.bext.CodingHistory...)
+	return append(b, make([]byte, size&1)...)
+}
+
+func (w *Wav) bextChunkSize() int {
+	if w.bext == nil {
+		return 0
+	}
+	return 8 + (bextSize+len(w.bext.CodingHistory)+1)/2*2
+}
+
+func (w *Wav) appendIXmlChunk(b []byte) []byte {
+	if w.ixml == "" {
+		return b
+	}
+
+	b = append(b, "iXML"...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(w.ixml)))
+	b = append(b, w.ixml...)
+	return append(b, make([]byte, len(w.ixml)&1)...)
+}
+
+func (w *Wav) ixmlChunkSize() int {
+	if w.ixml == "" {
+		return 0
+	}
+	return 8 + (len(w.ixml)+1)/2*2
+}
+
+// fixedString returns the string of the null-padded field
+func fixedString(b []byte) string {
+	s, _, _ := bytes.Cut(b, []byte{0})
+	return string(s)
+}
+
+// appendFixedString appends the string truncated or padded with zeros to the size
+func appendFixedString(b []byte, s string, size int) []byte {
+	if len(s) > size {
+		s = s[:size]
+	}
+	b = append(b, s...)
+	return append(b, make([]byte, size-len(s))...)
+}
+
+func loudness(b []byte) float64 {
+	return float64(int16(binary.LittleEndian.Uint16(b))) / 100
+}
@@ -0,0 +1,97 @@
+package audiocodec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestBextTime(t *testing.T) {
+	codec := NewPcmCodec(48_000, 24)
+	var bext Bext
+	bext.SetOrigination(codec, time.Date(2024, 5, 1, 13, 45, 30, 500_000_000, time.UTC))
+	if bext.OriginationDate != "2024-05-01" || bext.OriginationTime != "13-45-30" {
+		t.Errorf("origination %s %s", bext.OriginationDate, bext.OriginationTime)
+	}
+	if want := uint64((13*3600+45*60+30)*48_000 + 24_000); bext.TimeReference != want {
+		t.Errorf("time reference = %d, want %d", bext.TimeReference, want)
+	}
+	if offset := bext.TimeOffset(codec); offset != 13*time.Hour+45*time.Minute+30*time.Second+500*time.Millisecond {
+		t.Errorf("time offset = %s", offset)
+	}
+
+	bext.SetTimeOffset(Pcm8kHz16bCodec, 90*time.Second+time.Millisecond)
+	if bext.TimeReference != 720_008 || bext.TimeOffset(Pcm8kHz16bCodec) != 90*time.Second+time.Millisecond {
+		t.Errorf("time reference = %d, offset=%s", bext.TimeReference, bext.TimeOffset(Pcm8kHz16bCodec))
+	}
+}
+
+func TestBextChunk(t *testing.T) {
+	w := NewWav(Pcm8kHz16bCodec)
+	w.SetBext(&Bext{Description: "leg A", TimeReference: 0x1_0000_0002, LoudnessValue: -23.5, CodingHistory: "A=PCM\r\n"})
+	chunk := w.appendBextChunk(nil)
+	// the odd size of the coding history is padded
+	if len(chunk) != w.bextChunkSize() || len(chunk) != 8+bextSize+8 || binary.LittleEndian.Uint32(chunk[4:8]) != bextSize+7 {
+		t.Fatalf("chunk of %d bytes, size=%d, chunk size=%d", len(chunk), binary.LittleEndian.Uint32(chunk[4:8]), w.bextChunkSize())
+	}
+	p := chunk[8:]
+	if string(p[:6]) != "leg A\x00" || binary.LittleEndian.Uint32(p[0x152:]) != 2 || binary.LittleEndian.Uint32(p[0x156:]) != 1 {
+		t.Errorf("description=%q, time reference=% x", p[:6], p[0x152:0x15a])
+	}
+	// zero version is written as 2, loudness is in hundredths
+	if binary.LittleEndian.Uint16(p[0x15a:]) != 2 || int16(binary.LittleEndian.Uint16(p[0x19c:])) != -2350 {
+		t.Errorf("version=% x, loudness=% x", p[0x15a:0x15c], p[0x19c:0x19e])
+	}
+	if string(p[bextSize:]) != "A=PCM\r\n\x00" {
+		t.Errorf("coding history %q", p[bextSize:])
+	}
+}
+
+func TestBextRoundTrip(t *testing.T) {
+	bext := &Bext{
+		Description:          "recording of the call",
+		Originator:           "audiocodec",
+		OriginatorReference:  "REF0001",
+		OriginationDate:      "2024-05-01",
+		OriginationTime:      "13-45-30",
+		TimeReference:        1_234_567_890,
+		Version:              1,
+		LoudnessValue:        -23.5,
+		LoudnessRange:        7.25,
+		MaxTruePeakLevel:     -1.1,
+		MaxMomentaryLoudness: -18,
+		MaxShortTermLoudness: -20.01,
+		CodingHistory:        "A=PCM,F=8000,W=16,M=mono\r\n",
+	}
+	copy(bext.Umid[:], "SMPTE UMID")
+	// the odd size of the iXML chunk is padded
+	ixml := `<?xml version="1.0"?><BWFXML><PROJECT>call</PROJECT></BWFXML>`
+
+	w := NewWav(Pcm8kHz16bCodec)
+	w.SetBext(bext)
+	w.SetIXml(ixml)
+	w.Write(testData(Pcm8kHz16bCodec, 1, 5))
+	var file bytes.Buffer
+	if _, err := w.WriteTo(&file); err != nil {
+		t.Fatal(err)
+	}
+	// bext precedes the format
+	if string(file.Bytes()[12:16]) != "bext" {
+		t.Errorf("first chunk %q", file.Bytes()[12:16])
+	}
+
+	parsed, err := NewWavFromBytes(file.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.Bext(); got == nil || *got != *bext {
+		t.Errorf("bext = %+v, want %+v", got, bext)
+	}
+	if parsed.IXml() != ixml {
+		t.Errorf("iXML = %q", parsed.IXml())
+	}
+	if !parsed.Codec().IsEqual(Pcm8kHz16bCodec) || !bytes.Equal(parsed.Data(), testData(Pcm8kHz16bCodec, 1, 5)) {
+		t.Errorf("parsed %s, data=% x", parsed.Codec().Preset(), parsed.Data())
+	}
+}
@@ -0,0 +1,246 @@
+package audiocodec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+const (
+	cuePointSize = 24
+	// ltxtSize is the size of the ltxt subchunk data without the text
+	ltxtSize = 20
+	// RegionPurpose is the purpose of ltxt subchunks of regions
+	RegionPurpose = "rgn "
+)
+
+// CuePoint is a marker of the cue chunk with its texts of the LIST/adtl chunk. Offset and Length are in samples per
+// channel, a point of non-zero Length is a region.
+type CuePoint struct {
+	Id     uint32
+	Offset int
+	Length int
+	// Label and Note are the texts of the labl and note subchunks
+	Label string
+	Note  string
+	// Purpose and Text belong to the ltxt subchunk, the purpose of regions is RegionPurpose by default
+	Purpose string
+	Text    string
+}
+
+// Position returns the offset of the point as a duration at the sample rate of the codec.
+func (p *CuePoint) Position(codec *Codec) time.Duration {
+	return samplesDuration(p.Offset, codec.SampleRate)
+}
+
+// Duration returns the length of the region at the sample rate of the codec.
+func (p *CuePoint) Duration(codec *Codec) time.Duration {
+	return samplesDuration(p.Length, codec.SampleRate)
+}
+
+// CuePoints returns the cue points in the order of the cue chunk.
+func (w *Wav) CuePoints() []CuePoint {
+	return w.cuePoints
+}
+
+// SetCuePoints sets the points of the cue chunk, zero IDs are replaced with unique ones.
+func (w *Wav) SetCuePoints(points []CuePoint) error {
+	if !w.editable {
+		return WavFileIsNotEditable
+	}
+
+	var maxId uint32
+	ids := make(map[uint32]bool, len(points))
+	for _, p := range points {
+		if p.Purpose != "" && len(p.Purpose) != 4 {
+			return fmt.Errorf("%w: purpose=%q", InvalidCuePoint, p.Purpose)
+		}
+		if p.Offset < 0 || p.Length < 0 {
+			return fmt.Errorf("%w: offset=%d, length=%d", InvalidCuePoint, p.Offset, p.Length)
+		}
+		if p.Id != 0 && ids[p.Id] {
+			return fmt.Errorf("%w: duplicate id=%d", InvalidCuePoint, p.Id)
+		}
+		ids[p.Id] = true
+		maxId = max(maxId, p.Id)
+	}
+
+	w.cuePoints = make([]CuePoint, len(points))
+	for i, p := range points {
+		if p.Id == 0 {
+			maxId++
+			p.Id = maxId
+		}
+		w.cuePoints[i] = p
+	}
+	return nil
+}
+
+// Смещение	Размер 	Описание 			Значение
+// 0x00 	4		Number of points	число точек
+// 0x04 	*		Points				точки по 24 байта:
+//
+// 0x00 	4		ID					уникальный номер точки
+// 0x04 	4		Position			номер выборки в списке воспроизведения, без него совпадает со Sample offset
+// 0x08 	4		Data chunk ID		"data"
+// 0x0c 	4		Chunk start			0 для файлов с одной секцией данных
+// 0x10 	4		Block start			0 для несжатых данных
+// 0x14 	4		Sample offset		номер выборки точки
+func parseCue(b []byte) []CuePoint {
+	if len(b) < 4 {
+		return nil
+	}
+	count := int(binary.LittleEndian.Uint32(b[0:4]))
+	points := make([]CuePoint, 0, min(count, (len(b)-4)/cuePointSize))
+	for i := 4; len(points) < count && i+cuePointSize <= len(b); i += cuePointSize {
+		points = append(points, CuePoint{
+			Id:     binary.LittleEndian.Uint32(b[i : i+4]),
+			Offset: int(binary.LittleEndian.Uint32(b[i+20 : i+24])),
+		})
+	}
+	return points
+}
+
+// Подсекции списка "LIST" типа "adtl":
+// - "labl" и "note": ID точки (4 байта), строка с завершающим нулем
+// - "ltxt": ID точки (4 байта), длина области в выборках (4 байта), назначение (4 байта), страна, язык, диалект и
+// кодовая страница (по 2 байта), необязательная строка с завершающим нулем
+func parseAdtl(b []byte, points []CuePoint) {
+	for i := 0; i+12 <= len(b); {
+		size := int(binary.LittleEndian.Uint32(b[i+4 : i+8]))
+		if size < 4 || i+8+size > len(b) {
+			break
+		}
+		sub := b[i+8 : i+8+size]
+		id := binary.LittleEndian.Uint32(sub[0:4])
+		for j := range points {
+			if points[j].Id != id {
+				continue
+			}
+			switch string(b[i : i+4]) {
+			case "labl":
+				points[j].Label = fixedString(sub[4:])
+			case "note":
+				points[j].Note = fixedString(sub[4:])
+			case "ltxt":
+				if len(sub) >= ltxtSize {
+					points[j].Length = int(binary.LittleEndian.Uint32(sub[4:8]))
+					points[j].Purpose = string(sub[8:12])
+					points[j].Text = fixedString(sub[ltxtSize:])
+				}
+			}
+			break
+		}
+		i += 8 + size + size&1
+	}
+}
+
+func (w *Wav) appendCueChunks(b []byte) []byte {
+	if len(w.cuePoints) == 0 {
+		return b
+	}
+
+	b = append(b, "cue "...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(4+cuePointSize*len(w.cuePoints)))
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(w.cuePoints)))
+	for _, p := range w.cuePoints {
+		b = binary.LittleEndian.AppendUint32(b, p.Id)
+		b = binary.LittleEndian.AppendUint32(b, uint32(p.Offset))
+		b = append(b, "data"...)
+		b = binary.LittleEndian.AppendUint32(b, 0)
+		b = binary.LittleEndian.AppendUint32(b, 0)
+		b = binary.LittleEndian.AppendUint32(b, uint32(p.Offset))
+	}
+
+	adtlSize := w.adtlChunkSize()
+	if adtlSize == 0 {
+		return b
+	}
+	b = append(b, "LIST"...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(adtlSize-8))
+	b = append(b, "adtl"...)
+	for _, p := range w.cuePoints {
+		if p.Label != "" {
+			b = appendAdtlText(b, "labl", p.Id, p.Label)
+		}
+		if p.Note != "" {
+			b = appendAdtlText(b, "note", p.Id, p.Note)
+		}
+		if p.hasLtxt() {
+			size := ltxtSize + textSize(p.Text)
+			b = append(b, "ltxt"...)
+			b = binary.LittleEndian.AppendUint32(b, uint32(size))
+			b = binary.LittleEndian.AppendUint32(b, p.Id)
+			b = binary.LittleEndian.AppendUint32(b, uint32(p.Length))
+			b = append(b, p.purpose()...)
+			b = append(b, make([]byte, 8)...) // country, language, dialect and code page
+			if p.Text != "" {
+				b = append(append(b, p.Text...), 0)
+			}
+			b = append(b, make([]byte, size&1)...)
+		}
+	}
+	return b
+}
+
+func (w *Wav) cueChunksSize() int {
+	if len(w.cuePoints) == 0 {
+		return 0
+	}
+	return 12 + cuePointSize*len(w.cuePoints) + w.adtlChunkSize()
+}
+
+func (w *Wav) adtlChunkSize() int {
+	size := 0
+	for _, p := range w.cuePoints {
+		if p.Label != "" {
+			size += 8 + padded(4+textSize(p.Label))
+		}
+		if p.Note != "" {
+			size += 8 + padded(4+textSize(p.Note))
+		}
+		if p.hasLtxt() {
+			size += 8 + padded(ltxtSize+textSize(p.Text))
+		}
+	}
+	if size == 0 {
+		return 0
+	}
+	return 12 + size
+}
+
+func (p *CuePoint) hasLtxt() bool {
+	return p.Length > 0 || p.Text != "" || p.Purpose != ""
+}
+
+func (p *CuePoint) purpose() string {
+	if p.Purpose == "" {
+		return RegionPurpose
+	}
+	return p.Purpose
+}
+
+func appendAdtlText(b []byte, chunkId string, id uint32, text string) []byte {
+	size := 4 + textSize(text)
+	b = append(b, chunkId...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(size))
+	b = binary.LittleEndian.AppendUint32(b, id)
+	b = append(append(b, text...), 0)
+	return append(b, make([]byte, size&1)...)
+}
+
+// textSize returns the size of the null-terminated string, an empty string is omitted
+func textSize(s string) int {
+	if s == "" {
+		return 0
+	}
+	return len(s) + 1
+}
+
+func padded(size int) int {
+	return (size + 1) / 2 * 2
+}
+
+func samplesDuration(sampleCount int, sampleRate int) time.Duration {
+	return time.Duration(sampleCount/sampleRate)*time.Second + time.Duration(sampleCount%sampleRate)*time.Second/time.Duration(sampleRate)
+}
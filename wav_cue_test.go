@@ -0,0 +1,105 @@
+package audiocodec
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSetCuePoints(t *testing.T) {
+	w := NewWav(Pcm8kHz16bCodec)
+	invalid := [][]CuePoint{
+		{{Purpose: "rgn"}},
+		{{Offset: -1}},
+		{{Id: 3}, {Id: 3}},
+	}
+	for _, points := range invalid {
+		if err := w.SetCuePoints(points); !errors.Is(err, InvalidCuePoint) {
+			t.Errorf("SetCuePoints(%+v): %v, want %v", points, err, InvalidCuePoint)
+		}
+	}
+
+	// zero IDs follow the greatest one
+	if err := w.SetCuePoints([]CuePoint{{Offset: 10}, {Id: 5, Offset: 20}, {Offset: 30}}); err != nil {
+		t.Fatal(err)
+	}
+	if points := w.CuePoints(); points[0].Id != 6 || points[1].Id != 5 || points[2].Id != 7 {
+		t.Errorf("points = %+v", points)
+	}
+
+	p := CuePoint{Offset: 12_000, Length: 4}
+	if p.Position(Pcm8kHz16bCodec) != 1500*time.Millisecond || p.Duration(Pcm8kHz16bCodec) != 500*time.Microsecond {
+		t.Errorf("position=%s, duration=%s", p.Position(Pcm8kHz16bCodec), p.Duration(Pcm8kHz16bCodec))
+	}
+}
+
+func TestParseCue(t *testing.T) {
+	// cue chunk of two points and the adtl list with an odd label, a note and a region, texts of unknown points are
+	// ignored
+	cue := []byte{
+		0x02, 0x00, 0x00, 0x00,
+		0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'd', 'a', 't', 'a', 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x64, 0x00, 0x00, 0x00,
+		0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'd', 'a', 't', 'a', 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00,
+	}
+	adtl := []byte{
+		'l', 'a', 'b', 'l', 0x07, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 'a', 'b', 0x00, 0x00,
+		'n', 'o', 't', 'e', 0x08, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 'a', 'b', 'c', 0x00,
+		'l', 'a', 'b', 'l', 0x06, 0x00, 0x00, 0x00, 0x09, 0x00, 0x00, 0x00, 'x', 0x00,
+		'l', 't', 'x', 't', 0x14, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x20, 0x00, 0x00, 0x00, 'r', 'g', 'n', ' ',
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+	points := parseCue(cue)
+	parseAdtl(adtl, points)
+	want := []CuePoint{
+		{Id: 1, Offset: 100, Label: "ab"},
+		{Id: 2, Offset: 256, Length: 32, Note: "abc", Purpose: RegionPurpose},
+	}
+	if len(points) != len(want) || points[0] != want[0] || points[1] != want[1] {
+		t.Errorf("points = %+v, want %+v", points, want)
+	}
+}
+
+func TestCueRoundTrip(t *testing.T) {
+	points := []CuePoint{
+		{Id: 1, Offset: 0, Label: "start"},
+		{Id: 2, Offset: 800, Label: "answer", Note: "operator picked up"},
+		{Id: 3, Offset: 1600, Length: 400, Label: "hold"},
+		{Id: 4, Offset: 2400, Length: 100, Purpose: "tran", Text: "hello"},
+		{Id: 5, Offset: 3000},
+	}
+	w := NewWavWithChannels(Pcm8kHz16bCodec, 2)
+	if err := w.SetCuePoints(points); err != nil {
+		t.Fatal(err)
+	}
+	if chunks := w.appendCueChunks(nil); len(chunks) != w.cueChunksSize() {
+		t.Errorf("cue chunks of %d bytes, size=%d", len(chunks), w.cueChunksSize())
+	}
+	w.Write(testData(Pcm8kHz16bCodec, 2, 4000))
+	var file bytes.Buffer
+	if _, err := w.WriteTo(&file); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetCuePoints(nil); !errors.Is(err, WavFileIsNotEditable) {
+		t.Errorf("SetCuePoints of the written wav: %v, want %v", err, WavFileIsNotEditable)
+	}
+
+	parsed, err := NewWavFromBytes(file.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// regions get the default purpose
+	points[2].Purpose = RegionPurpose
+	got := parsed.CuePoints()
+	if len(got) != len(points) {
+		t.Fatalf("points = %+v, want %+v", got, points)
+	}
+	for i := range points {
+		if got[i] != points[i] {
+			t.Errorf("point %d = %+v, want %+v", i, got[i], points[i])
+		}
+	}
+	if parsed.SampleCount() != 4000 {
+		t.Errorf("samples = %d", parsed.SampleCount())
+	}
+}
@@ -0,0 +1,120 @@
+package audiocodec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// InfoItem is a subchunk of the LIST/INFO chunk, e.g. INAM (title), IART (artist), ICMT (comment),
+// ICRD (creation date), ISFT (software) or any custom four-character ID.
+type InfoItem struct {
+	Id    string
+	Value string
+}
+
+// WavInfo is the metadata of the LIST/INFO chunk of a WAV file in the order of its subchunks.
+type WavInfo []InfoItem
+
+// Get returns the value of the first item with the ID.
+func (info WavInfo) Get(id string) string {
+	for _, item := range info {
+		if item.Id == id {
+			return item.Value
+		}
+	}
+	return ""
+}
+
+// Set replaces the value of the first item with the ID or appends a new item.
+func (info *WavInfo) Set(id string, value string) {
+	for i, item := range *info {
+		if item.Id == id {
+			(*info)[i].Value = value
+			return
+		}
+	}
+	*info = append(*info, InfoItem{Id: id, Value: value})
+}
+
+// Map returns the values by IDs, the first item wins if an ID repeats.
+func (info WavInfo) Map() map[string]string {
+	m := make(map[string]string, len(info))
+	for _, item := range info {
+		if _, ok := m[item.Id]; !ok {
+			m[item.Id] = item.Value
+		}
+	}
+	return m
+}
+
+// Info returns the metadata of the LIST/INFO chunks.
+func (w *Wav) Info() WavInfo {
+	return w.info
+}
+
+// SetInfo sets the metadata written into the LIST/INFO chunk before the data.
+func (w *Wav) SetInfo(info WavInfo) error {
+	if !w.editable {
+		return WavFileIsNotEditable
+	}
+	for _, item := range info {
+		if len(item.Id) != 4 {
+			return fmt.Errorf("%w: %q", InvalidInfoId, item.Id)
+		}
+	}
+
+	w.info = info
+	return nil
+}
+
+// Смещение	Размер 	Описание 			Значение
+// 0x00 	4		Chunk ID			"LIST"
+// 0x04 	4		Chunk Data Size		4 + размер подсекций
+// 0x08 	4		List type			"INFO"
+// 0x0c 	*		Подсекции			ID (4 байта), размер (4 байта), строка с завершающим нулем
+//
+// Размер подсекции включает завершающий ноль, но не байт выравнивания, который добавляется к подсекции нечетного размера.
+func parseInfo(b []byte) WavInfo {
+	var info WavInfo
+	for i := 0; i+8 <= len(b); {
+		size := int(binary.LittleEndian.Uint32(b[i+4 : i+8]))
+		if i+8+size > len(b) {
+			break
+		}
+		value, _, _ := bytes.Cut(b[i+8:i+8+size], []byte{0})
+		info = append(info, InfoItem{Id: string(b[i : i+4]), Value: string(value)})
+		i += 8 + size + size&1
+	}
+	return info
+}
+
+func (w *Wav) appendInfoChunk(b []byte) []byte {
+	if len(w.info) == 0 {
+		return b
+	}
+
+	b = append(b, "LIST"...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(w.infoChunkSize()-8))
+	b = append(b, "INFO"...)
+	for _, item := range w.info {
+		size := len(item.Value) + 1
+		b = append(b, item.Id...)
+		b = binary.LittleEndian.AppendUint32(b, uint32(size))
+		b = append(b, item.Value...)
+		b = append(b, make([]byte, 1+size&1)...)
+	}
+	return b
+}
+
+func (w *Wav) infoChunkSize() int {
+	if len(w.info) == 0 {
+		return 0
+	}
+
+	size := 12
+	for _, item := range w.info {
+		size += 8 + (len(item.Value)+2)/2*2
+	}
+	return size
+}
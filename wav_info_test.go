@@ -0,0 +1,81 @@
+package audiocodec
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestWavInfo(t *testing.T) {
+	var info WavInfo
+	info.Set("INAM", "call")
+	info.Set("IART", "operator")
+	info.Set("INAM", "incoming call")
+	info = append(info, InfoItem{Id: "IART", Value: "client"})
+	if info.Get("INAM") != "incoming call" || info.Get("IART") != "operator" || info.Get("ICMT") != "" || len(info) != 3 {
+		t.Errorf("info = %v", info)
+	}
+	if m := info.Map(); len(m) != 2 || m["IART"] != "operator" {
+		t.Errorf("map = %v", m)
+	}
+}
+
+func TestWavInfoChunk(t *testing.T) {
+	w := NewWav(Pcm8kHz16bCodec)
+	if err := w.SetInfo(WavInfo{{Id: "NAME", Value: "x"}, {Id: "TOOLONG", Value: "x"}}); !errors.Is(err, InvalidInfoId) {
+		t.Errorf("SetInfo with a long ID: %v, want %v", err, InvalidInfoId)
+	}
+	// the odd size of "call" with the null is padded, the even size of "abc" is not
+	w.SetInfo(WavInfo{{Id: "INAM", Value: "call"}, {Id: "ICMT", Value: "abc"}})
+	want := []byte{
+		'L', 'I', 'S', 'T', 0x1e, 0x00, 0x00, 0x00, 'I', 'N', 'F', 'O',
+		'I', 'N', 'A', 'M', 0x05, 0x00, 0x00, 0x00, 'c', 'a', 'l', 'l', 0x00, 0x00,
+		'I', 'C', 'M', 'T', 0x04, 0x00, 0x00, 0x00, 'a', 'b', 'c', 0x00,
+	}
+	if chunk := w.appendInfoChunk(nil); !bytes.Equal(chunk, want) || w.infoChunkSize() != len(want) {
+		t.Errorf("chunk = % x, size=%d, want % x", chunk, w.infoChunkSize(), want)
+	}
+	if info := parseInfo(want[12:]); len(info) != 2 || info.Get("INAM") != "call" || info.Get("ICMT") != "abc" {
+		t.Errorf("parsed info = %v", info)
+	}
+}
+
+func TestWavInfoRoundTrip(t *testing.T) {
+	info := WavInfo{
+		{Id: "INAM", Value: "call"},
+		{Id: "ICRD", Value: "2024-05-01"},
+		{Id: "ICMT", Value: ""},
+		{Id: "ISFT", Value: "audiocodec"},
+		{Id: "ICMT", Value: "second comment"},
+		{Id: "XUID", Value: "custom"},
+	}
+	w := NewWavWithChannels(Pcm8kHz16bCodec, 2)
+	if err := w.SetInfo(info); err != nil {
+		t.Fatal(err)
+	}
+	w.Write(testData(Pcm8kHz16bCodec, 2, 3))
+	var file bytes.Buffer
+	if _, err := w.WriteTo(&file); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetInfo(nil); !errors.Is(err, WavFileIsNotEditable) {
+		t.Errorf("SetInfo of the written wav: %v, want %v", err, WavFileIsNotEditable)
+	}
+
+	parsed, err := NewWavFromBytes(file.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.Info(); len(got) != len(info) {
+		t.Errorf("info = %v, want %v", got, info)
+	} else {
+		for i := range info {
+			if got[i] != info[i] {
+				t.Errorf("item %d = %v, want %v", i, got[i], info[i])
+			}
+		}
+	}
+	if !bytes.Equal(parsed.Data(), testData(Pcm8kHz16bCodec, 2, 3)) {
+		t.Errorf("data = % x", parsed.Data())
+	}
+}
@@ -0,0 +1,318 @@
+package audiocodec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"testing"
+)
+
+// memFile is an in-memory file counting the bytes read from it
+type memFile struct {
+	b    []byte
+	pos  int
+	read int
+}
+
+func (f *memFile) Read(p []byte) (int, error) {
+	if f.pos >= len(f.b) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.b[f.pos:])
+	f.pos += n
+	f.read += n
+	return n, nil
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	if end := f.pos + len(p); end > len(f.b) {
+		f.b = append(f.b, make([]byte, end-len(f.b))...)
+	}
+	n := copy(f.b[f.pos:], p)
+	f.pos += n
+	return n, nil
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		f.pos = int(offset)
+	case io.SeekCurrent:
+		f.pos += int(offset)
+	case io.SeekEnd:
+		f.pos = len(f.b) + int(offset)
+	}
+	return int64(f.pos), nil
+}
+
+func TestWavWriter(t *testing.T) {
+	var f memFile
+	wav := NewWavWithChannels(Pcm8kHz16bCodec, 2)
+	wav.Write(testData(Pcm8kHz16bCodec, 2, 1))
+	w, err := NewWavWriter(&f, wav)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = wav.Write([]byte{1}); !errors.Is(err, WavFileIsNotEditable) {
+		t.Errorf("Write into the streamed wav = %v, want %v", err, WavFileIsNotEditable)
+	}
+	w.Write(testData(Pcm8kHz16bCodec, 2, 2)[4:])
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := NewWavFromBytes(f.b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Channels() != 2 || parsed.SampleCount() != 2 || !bytes.Equal(parsed.Data(), testData(Pcm8kHz16bCodec, 2, 2)) {
+		t.Errorf("parsed channels=%d, samples=%d, data=% x", parsed.Channels(), parsed.SampleCount(), parsed.Data())
+	}
+	// the place of ds64 is reserved
+	if string(f.b[0:4]) != "RIFF" || string(f.b[12:16]) != "JUNK" {
+		t.Errorf("headers start with %q", f.b[:16])
+	}
+}
+
+func TestWavWriterOddData(t *testing.T) {
+	var f memFile
+	codec := NewPcmCodec(8_000, 8)
+	w, err := NewWavWriter(&f, NewWav(codec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte{1, 2, 3})
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Errorf("second Close = %v", err)
+	}
+	if _, err = w.Write([]byte{4}); !errors.Is(err, WavWriterIsClosed) {
+		t.Errorf("Write after Close = %v, want %v", err, WavWriterIsClosed)
+	}
+
+	// the data is padded and the pad is counted by the RIFF size
+	if len(f.b)%2 != 0 || f.b[len(f.b)-1] != 0 {
+		t.Errorf("file of %d bytes ends with %x", len(f.b), f.b[len(f.b)-1])
+	}
+	if size := binary.LittleEndian.Uint32(f.b[4:8]); int(size) != len(f.b)-8 {
+		t.Errorf("RIFF size = %d, want %d", size, len(f.b)-8)
+	}
+	parsed, err := NewWavFromBytes(f.b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(parsed.Data(), []byte{1, 2, 3}) {
+		t.Errorf("parsed data = % x", parsed.Data())
+	}
+}
+
+func TestWavWriteToOddData(t *testing.T) {
+	wav := NewWav(NewPcmCodec(8_000, 8))
+	wav.Write([]byte{1, 2, 3})
+	var file bytes.Buffer
+	if _, err := wav.WriteTo(&file); err != nil {
+		t.Fatal(err)
+	}
+	b := file.Bytes()
+	if size := binary.LittleEndian.Uint32(b[4:8]); len(b)%2 != 0 || int(size) != len(b)-8 {
+		t.Errorf("RIFF size = %d of a %d bytes file", size, len(b))
+	}
+
+	read, err := io.ReadAll(wav)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read, b) {
+		t.Errorf("Read = % x, want % x", read, b)
+	}
+}
+
+func TestReadWavHeader(t *testing.T) {
+	codec := NewPcmCodec(16_000, 16)
+	wav := NewWavWithChannels(codec, 2)
+	wav.Write(testData(codec, 2, 1000))
+	wav.SetInfo(WavInfo{{Id: "INAM", Value: "call"}})
+	wav.SetBext(&Bext{Description: "leg A", TimeReference: 16_000})
+	wav.SetCuePoints([]CuePoint{{Id: 1, Offset: 100, Label: "answer"}})
+	var file bytes.Buffer
+	if _, err := wav.WriteTo(&file); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &memFile{b: file.Bytes()}
+	header, sampleCount, err := ReadWavHeader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !header.Codec().IsEqual(codec) || header.Channels() != 2 || sampleCount != 1000 || header.DataSize() != 0 {
+		t.Errorf("header codec=%s, channels=%d, samples=%d, data size=%d", header.Codec().Preset(), header.Channels(), sampleCount, header.DataSize())
+	}
+	if header.Info().Get("INAM") != "call" || header.Bext() == nil || header.Bext().Description != "leg A" {
+		t.Errorf("header info=%v, bext=%+v", header.Info(), header.Bext())
+	}
+	if points := header.CuePoints(); len(points) != 1 || points[0].Offset != 100 || points[0].Label != "answer" {
+		t.Errorf("header cue points=%+v", points)
+	}
+	// the sample data is skipped
+	if f.read > len(f.b)-4000 {
+		t.Errorf("%d of %d bytes read", f.read, len(f.b))
+	}
+
+	f.b = f.b[:len(f.b)-1]
+	f.pos = 0
+	if _, _, err = ReadWavHeader(f); !errors.Is(err, TruncatedWav) {
+		t.Errorf("truncated file: %v, want %v", err, TruncatedWav)
+	}
+}
+
+// rf64File builds an RF64 or BW64 file of 16-bit mono PCM at 8 kHz, its sizes are set in the ds64 chunk only
+func rf64File(id string, data []byte) []byte {
+	b := append([]byte(id), 0xff, 0xff, 0xff, 0xff)
+	b = append(b, "WAVE"...)
+	b = append(b, "ds64"...)
+	b = binary.LittleEndian.AppendUint32(b, 28)
+	b = binary.LittleEndian.AppendUint64(b, uint64(4+36+24+8+len(data)))
+	b = binary.LittleEndian.AppendUint64(b, uint64(len(data)))
+	b = binary.LittleEndian.AppendUint64(b, uint64(len(data)/2))
+	b = binary.LittleEndian.AppendUint32(b, 0)
+	b = append(b, "fmt "...)
+	b = binary.LittleEndian.AppendUint32(b, 16)
+	b = append(b, 0x01, 0x00, 0x01, 0x00, 0x40, 0x1f, 0x00, 0x00, 0x80, 0x3e, 0x00, 0x00, 0x02, 0x00, 0x10, 0x00)
+	b = append(b, "data"...)
+	b = append(b, 0xff, 0xff, 0xff, 0xff)
+	return append(b, data...)
+}
+
+func TestRf64(t *testing.T) {
+	data := []byte{0x00, 0x01, 0xff, 0xfe, 0x01, 0x2c}
+	for _, id := range []string{"RF64", "BW64"} {
+		file := rf64File(id, data)
+		w, err := NewWavFromBytes(file)
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		if !w.Codec().IsEqual(Pcm8kHz16bCodec) || w.Channels() != 1 || w.SampleCount() != 3 || !bytes.Equal(w.Data(), data) {
+			t.Errorf("%s: parsed %s of %d channels, samples=%d, data=% x", id, w.Codec().Preset(), w.Channels(), w.SampleCount(), w.Data())
+		}
+
+		header, sampleCount, err := ReadWavHeader(&memFile{b: file})
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		if !header.Codec().IsEqual(Pcm8kHz16bCodec) || sampleCount != 3 {
+			t.Errorf("%s: header codec=%s, samples=%d", id, header.Codec().Preset(), sampleCount)
+		}
+
+		// small files are written back as RIFF
+		header.Write(data)
+		var written bytes.Buffer
+		if _, err = header.WriteTo(&written); err != nil {
+			t.Fatal(err)
+		}
+		if string(written.Bytes()[0:4]) != "RIFF" {
+			t.Errorf("%s: written as %q", id, written.Bytes()[0:4])
+		}
+		parsed, err := NewWavFromBytes(written.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !parsed.Codec().IsEqual(Pcm8kHz16bCodec) || !bytes.Equal(parsed.Data(), data) {
+			t.Errorf("%s: written %s, data=% x", id, parsed.Codec().Preset(), parsed.Data())
+		}
+	}
+
+	file := rf64File("RF64", data)
+	copy(file[12:16], "JUNK")
+	if _, err := NewWavFromBytes(file); !errors.Is(err, InvalidWav) {
+		t.Errorf("RF64 without ds64: %v, want %v", err, InvalidWav)
+	}
+}
+
+// sparseFile is a file of the size starting with the headers, the rest of it is not stored
+type sparseFile struct {
+	memFile
+	size int64
+}
+
+func (f *sparseFile) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekEnd {
+		return f.memFile.Seek(f.size+offset, io.SeekStart)
+	}
+	return f.memFile.Seek(offset, whence)
+}
+
+func TestRf64Headers(t *testing.T) {
+	if math.MaxInt == math.MaxInt32 {
+		t.Skip("sizes over 4 GB do not fit into int")
+	}
+	size := int64(math.MaxUint32) + 1000
+	dataSize := int(size)
+	w := NewWavWithChannels(PcmA8kHz8bCodec, 2)
+	w.SetInfo(WavInfo{{Id: "INAM", Value: "long call"}})
+	headers := w.appendHeaders(nil, dataSize, true)
+	if string(headers[0:4]) != "RF64" || string(headers[12:16]) != "ds64" {
+		t.Fatalf("headers start with %q", headers[:16])
+	}
+	if binary.LittleEndian.Uint32(headers[len(headers)-4:]) != math.MaxUint32 {
+		t.Errorf("data chunk size = %x", headers[len(headers)-4:])
+	}
+
+	header, sampleCount, err := ReadWavHeader(&sparseFile{memFile: memFile{b: headers}, size: int64(len(headers) + dataSize)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !header.Codec().IsEqual(PcmA8kHz8bCodec) || header.Channels() != 2 || sampleCount != dataSize/2 || header.Info().Get("INAM") != "long call" {
+		t.Errorf("header codec=%s, channels=%d, samples=%d, info=%v", header.Codec().Preset(), header.Channels(), sampleCount, header.Info())
+	}
+}
+
+func TestWavReference(t *testing.T) {
+	// the file written by the wave module of Python: 16-bit stereo PCM at 8 kHz
+	file := []byte{
+		0x52, 0x49, 0x46, 0x46, 0x2c, 0x00, 0x00, 0x00, 0x57, 0x41, 0x56, 0x45, 0x66, 0x6d, 0x74, 0x20,
+		0x10, 0x00, 0x00, 0x00, 0x01, 0x00, 0x02, 0x00, 0x40, 0x1f, 0x00, 0x00, 0x00, 0x7d, 0x00, 0x00,
+		0x04, 0x00, 0x10, 0x00, 0x64, 0x61, 0x74, 0x61, 0x08, 0x00, 0x00, 0x00, 0x00, 0x01, 0xff, 0xfe,
+		0x01, 0x2c, 0xfe, 0x70,
+	}
+	w, err := NewWavFromBytes(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !w.Codec().IsEqual(Pcm8kHz16bCodec) || w.Channels() != 2 || w.SampleCount() != 2 || !bytes.Equal(w.Data(), file[44:]) {
+		t.Errorf("parsed %s of %d channels, samples=%d, data=% x", w.Codec().Preset(), w.Channels(), w.SampleCount(), w.Data())
+	}
+
+	written := NewWavWithChannels(Pcm8kHz16bCodec, 2)
+	written.Write(file[44:])
+	var b bytes.Buffer
+	if _, err = written.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.Bytes(), file) {
+		t.Errorf("written file = % x, want % x", b.Bytes(), file)
+	}
+}
+
+func TestWavShortBlock(t *testing.T) {
+	// a trailing block shorter than the block header holds no samples
+	for _, codec := range []*Codec{NewCodec(ImaAdpcm, 8_000, 16), NewCodec(MsAdpcm, 8_000, 16)} {
+		w := NewWavWithBlockAlign(codec, 1, 256)
+		w.Write([]byte{0})
+		if w.SampleCount() != 0 {
+			t.Errorf("%s: %d samples, want 0", codec.Name, w.SampleCount())
+		}
+
+		f := &memFile{}
+		if _, err := w.WriteTo(f); err != nil {
+			t.Fatal(err)
+		}
+		f.pos = 0
+		if _, sampleCount, err := ReadWavHeader(f); err != nil || sampleCount != 0 {
+			t.Errorf("%s: ReadWavHeader = %d, %v, want 0 samples", codec.Name, sampleCount, err)
+		}
+	}
+}